@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+)
+
+func runAudit(args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		return fmt.Errorf("expected subcommand: verify")
+	}
+
+	fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	file := fs.String("file", config.AuditLog(), "audit log file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *file == "" || *file == audit.SyslogTarget {
+		return fmt.Errorf("audit log is not written to a file, use -file")
+	}
+
+	count, err := audit.VerifyFile(*file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: hash chain intact, %d records verified\n", *file, count)
+
+	return nil
+}
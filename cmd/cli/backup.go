@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/duykhoa/gopass/internal/backup"
+)
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", backup.FormatBundle, "bundle (encrypted), csv or json (plaintext)")
+	subtree := fs.String("subtree", "", "only export entries under this folder")
+	output := fs.String("o", "", "output file")
+	yes := fs.Bool("yes", false, "confirm a plaintext export without asking")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return fmt.Errorf("usage: export [-format bundle|csv|json] [-subtree folder] [-yes] -o <file>")
+	}
+
+	req := backup.ExportRequest{Format: *format, Subtree: *subtree}
+	if *format == backup.FormatCSV || *format == backup.FormatJSON {
+		req.ConfirmPlaintext = *yes || confirm(fmt.Sprintf("%s will contain every secret unencrypted.", *output))
+		if !req.ConfirmPlaintext {
+			return backup.ErrPlaintextNotConfirmed
+		}
+	}
+
+	var err error
+	if req.Passphrase, err = readPassphrase(); err != nil {
+		return err
+	}
+	if *format == backup.FormatBundle {
+		if req.BundlePassphrase, err = promptPassword("Bundle passphrase: "); err != nil {
+			return err
+		}
+		again, err := promptPassword("Repeat bundle passphrase: ")
+		if err != nil {
+			return err
+		}
+		if again != req.BundlePassphrase {
+			return fmt.Errorf("bundle passphrases do not match")
+		}
+	}
+
+	f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	count, err := backup.Export(f, req)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+	fmt.Printf("Exported %d entries to %s\n", count, *output)
+
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	overwrite := fs.Bool("overwrite", false, "replace existing entries")
+	verify := fs.Bool("verify", false, "only check the bundle integrity")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: restore [-overwrite] [-verify] <bundle>")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	passphrase, err := promptPassword("Bundle passphrase: ")
+	if err != nil {
+		return err
+	}
+
+	result := backup.Restore(backup.RestoreRequest{
+		Data:             data,
+		BundlePassphrase: passphrase,
+		Overwrite:        *overwrite,
+		VerifyOnly:       *verify,
+	})
+	if result.Err != nil {
+		return result.Err
+	}
+
+	if *verify {
+		fmt.Printf("Bundle of %s is intact, %d entries\n", result.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"), len(result.Manifest.Entries))
+		return nil
+	}
+	for _, entry := range result.Skipped {
+		fmt.Printf("skipped %s (exists)\n", entry)
+	}
+	fmt.Printf("Restored %d entries, skipped %d\n", result.Restored, len(result.Skipped))
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+func runClone(args []string) error {
+	fs := flag.NewFlagSet("clone", flag.ContinueOnError)
+	dir := fs.String("dir", config.PasswordStoreDir(), "directory to clone the store into")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: clone [-dir path] <remote>")
+	}
+
+	result := service.CloneStore(*dir, fs.Arg(0))
+	if result.Err != nil {
+		return result.Err
+	}
+	fmt.Printf("Cloned the store into %s\n", *dir)
+	if err := config.SetStorePath(*dir); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+
+	if result.CanDecrypt() {
+		fmt.Printf("Your key %s can decrypt the store\n", result.DecryptKey)
+		return nil
+	}
+	fmt.Println(result.AccessHelp())
+	if result.PublicKey != "" {
+		fmt.Println()
+		fmt.Print(result.PublicKey)
+	}
+	return nil
+}
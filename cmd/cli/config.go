@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/duykhoa/gopass/internal/config"
+)
+
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path := config.ConfigFile()
+	switch fs.Arg(0) {
+	case "path":
+		fmt.Println(path)
+	case "", "show":
+		if err := config.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid configuration, showing the defaults: %v\n\n", err)
+		}
+		cfg := config.Current()
+		return cfg.Encode(os.Stdout)
+	case "check":
+		if _, err := config.LoadFile(path); err != nil {
+			return err
+		}
+		fmt.Printf("%s is valid\n", path)
+	case "init":
+		if err := config.Default().Write(path); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", path)
+	default:
+		return fmt.Errorf("unknown config command %q, use path, show, check or init", fs.Arg(0))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+func runFsck(args []string) error {
+	fs := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, "re-encrypt entries for the store key and fix permissions")
+	decrypt := fs.Bool("decrypt", false, "also decrypt every entry to find unreadable ones")
+	removePlaintext := fs.Bool("remove-plaintext", false, "encrypt plaintext entries and delete the plaintext files")
+	yes := fs.Bool("yes", false, "do not ask before deleting plaintext files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	req := service.FsckRequest{Fix: *fix}
+	if *fix || *decrypt {
+		passphrase, err := readPassphrase()
+		if err != nil {
+			return err
+		}
+		req.Passphrase = passphrase
+	}
+	if *removePlaintext {
+		req.RemovePlaintext = *yes || confirm("Plaintext entries will be encrypted and the plaintext files deleted.")
+	}
+
+	result := service.Fsck(req)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, p := range result.Problems {
+		line := fmt.Sprintf("%-7s %s: %s", p.Severity, p.Path, p.Message)
+		switch {
+		case p.Fixed:
+			line += " (fixed)"
+		case p.FixErr != nil:
+			line += fmt.Sprintf(" (fix failed: %v)", p.FixErr)
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("Checked %d entries: %d errors, %d warnings\n",
+		result.Checked, result.Count(service.SeverityError), result.Count(service.SeverityWarning))
+
+	return nil
+}
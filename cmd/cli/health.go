@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+func runHealth(args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	defaults := service.DefaultAuditRequest("")
+	breachFile := fs.String("breaches", defaults.BreachFile, "offline HIBP SHA-1 list, a sorted file or a directory of range files")
+	maxAge := fs.Int("max-age", int(defaults.MaxAge/(24*time.Hour)), "report passwords unchanged for more days, 0 to disable")
+	workers := fs.Int("j", 0, "number of entries decrypted in parallel (default: number of CPUs)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := service.Audit(ctx, service.AuditRequest{
+		Passphrase: passphrase,
+		Entries:    fs.Args(),
+		Workers:    *workers,
+		MaxAge:     time.Duration(*maxAge) * 24 * time.Hour,
+		BreachFile: *breachFile,
+	})
+	if err != nil {
+		return err
+	}
+	report.Format(os.Stdout)
+
+	return nil
+}
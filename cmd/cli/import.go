@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/importer"
+)
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "export format: "+formatNames())
+	prefix := fs.String("prefix", "", "folder the entries are imported into")
+	strategy := fs.String("strategy", string(importer.StrategySkip), "when an entry exists: skip, overwrite or suffix")
+	dryRun := fs.Bool("dry-run", false, "only print the plan")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *format == "" {
+		return fmt.Errorf("usage: import -format <format> [-prefix folder] [-strategy skip|overwrite|suffix] [-dry-run] <file>")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	result := importer.Import(importer.ImportRequest{
+		Format:   *format,
+		Data:     data,
+		Prefix:   *prefix,
+		Strategy: importer.Strategy(*strategy),
+		DryRun:   *dryRun,
+	})
+	if result.Plan.Items == nil && result.Err != nil {
+		return result.Err
+	}
+
+	for _, item := range result.Plan.Items {
+		line := fmt.Sprintf("%-9s %s", item.Action, item.Entry)
+		if item.Conflict {
+			line += " (conflict)"
+		}
+		if item.Err != nil {
+			line += fmt.Sprintf(": %v", item.Err)
+		}
+		fmt.Println(line)
+	}
+	plan := result.Plan
+	fmt.Printf("\n%d records: %d new, %d overwritten, %d renamed, %d skipped\n",
+		len(plan.Items), plan.Count(importer.ActionCreate), plan.Count(importer.ActionOverwrite),
+		plan.Count(importer.ActionRename), plan.Count(importer.ActionSkip))
+	if !*dryRun {
+		fmt.Printf("Imported %d entries\n", result.Imported)
+	}
+
+	return result.Err
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(importer.Formats))
+	for _, f := range importer.Formats {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, ", ")
+}
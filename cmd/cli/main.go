@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"audit", "audit verify [-file path]   verify the audit log hash chain", runAudit},
+	{"config", "config [show|check|init|path] show, validate or create the configuration file", runConfig},
+	{"clone", "clone [-dir path] <remote>  clone an existing store and check that your key can decrypt it", runClone},
+	{"stores", "stores                      list the root store and the stores mounted in it", runStores},
+	{"sign", "sign [-yes] [folder]        sign the .gpg-id of a folder, the root store by default, once its keys are checked", runSign},
+	{"search", "search <query>              fuzzy search entry names, url: user: template: field: tag: use the index", runSearch},
+	{"index", "index rebuild               build the encrypted search index", runIndex},
+	{"grep", "grep [-i] [-j n] <pattern>  search decrypted entries, prints entry:field: line", runGrep},
+	{"fsck", "fsck [-fix] [-decrypt]       check the store for unreadable, misencrypted and plaintext files", runFsck},
+	{"health", "health [-breaches f] [entry]  report weak, reused, old and breached passwords", runHealth},
+	{"import", "import -format <f> <file>   import a Bitwarden, 1Password, KeePass, LastPass or browser export", runImport},
+	{"export", "export [-format f] -o <file> export the store as an encrypted bundle, or as plaintext csv/json", runExport},
+	{"restore", "restore [-verify] <bundle>  verify and restore an encrypted bundle", runRestore},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: gopass-cli <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", c.usage)
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(flag.Args()[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "gopass-cli %s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/service"
+	"golang.org/x/term"
+)
+
+// readPassphrase returns the cached passphrase, or prompts for it on the
+// terminal without echo.
+func readPassphrase() (string, error) {
+	if pass, valid := service.GetCachedPassphrase(); valid && pass != "" {
+		return pass, nil
+	}
+
+	return promptPassword("GPG passphrase: ")
+}
+
+// promptPassword prompts on the terminal without echo.
+func promptPassword(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return string(pass), nil
+}
+
+// confirm asks a yes/no question, anything but "yes" is a no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s Type yes to continue: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	entries, err := service.ListEntries()
+	if err != nil {
+		return err
+	}
+	req := service.SearchRequest{Query: strings.Join(fs.Args(), " "), Entries: entries}
+	results, err := service.Search(req)
+	if errors.Is(err, service.ErrIndexUnavailable) && service.IndexEnabled() {
+		if req.Passphrase, err = readPassphrase(); err != nil {
+			return err
+		}
+		results, err = service.Search(req)
+	}
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		fmt.Println(result.Entry)
+	}
+
+	return nil
+}
+
+func runIndex(args []string) error {
+	if len(args) == 0 || args[0] != "rebuild" {
+		return fmt.Errorf("expected subcommand: rebuild")
+	}
+
+	passphrase, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+	idx, err := service.RebuildIndex(passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Indexed %d entries\n", len(idx.Entries))
+
+	return nil
+}
+
+func runGrep(args []string) error {
+	fs := flag.NewFlagSet("grep", flag.ContinueOnError)
+	ignoreCase := fs.Bool("i", false, "case insensitive match")
+	workers := fs.Int("j", 0, "number of entries decrypted in parallel (default: number of CPUs)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one pattern")
+	}
+
+	passphrase, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	matches, err := service.Grep(ctx, service.GrepRequest{
+		Pattern:    fs.Arg(0),
+		IgnoreCase: *ignoreCase,
+		Passphrase: passphrase,
+		Workers:    *workers,
+	})
+	if err != nil {
+		return err
+	}
+
+	for m := range matches {
+		if m.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", m.Entry, m.Err)
+			continue
+		}
+		fmt.Printf("%s:%s: %s\n", m.Entry, m.Field, m.Line)
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+// runSign signs the .gpg-id of a folder once the user checked its keys, e.g.
+// after turning signing on for an existing store.
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "do not ask before signing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	folder := strings.Trim(fs.Arg(0), "/")
+
+	folders, err := service.ListRecipients()
+	if err != nil {
+		return err
+	}
+	var found *service.FolderRecipients
+	for i := range folders {
+		if folders[i].Folder == folder {
+			found = &folders[i]
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("%q has no .gpg-id of its own", folder)
+	}
+
+	fmt.Printf("Recipients of %q:\n", folder)
+	for _, r := range found.Recipients {
+		if r.Missing {
+			fmt.Printf("  %s (no public key)\n", r.ID)
+			continue
+		}
+		fmt.Printf("  %s %s\n", r.Key.Fingerprint, strings.Join(r.Key.UIDs, ", "))
+	}
+	if !*yes && !confirm("Everyone listed will be able to read the entries encrypted from now on.") {
+		return fmt.Errorf("not signed")
+	}
+
+	passphrase, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+	if err := service.SignRecipients(folder, passphrase); err != nil {
+		return err
+	}
+	fmt.Printf("Signed the recipients of %q\n", folder)
+	return nil
+}
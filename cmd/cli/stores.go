@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+func runStores(args []string) error {
+	fs := flag.NewFlagSet("stores", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := config.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "configuration not loaded, using the defaults: %v\n", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPREFIX\tPATH\tKEY\tGIT")
+	for _, m := range config.Mounts() {
+		prefix := m.Prefix
+		if prefix == "" {
+			prefix = "/"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\n", m.Name, prefix, m.Path, m.GPGId, service.IsGitStore(m))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/audit"
+)
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streaming responses such as /events working through the middleware.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// auditMiddleware writes one audit record per HTTP request. The actor is the
+// remote address, the X-Gopass-Actor header any client can set is only
+// recorded as the claimed actor, and a bearer token is only recorded as a
+// short fingerprint.
+func auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		var entry string
+		if strings.HasPrefix(r.URL.Path, "/secrets/") {
+			entry = strings.TrimPrefix(r.URL.Path, "/secrets/")
+		}
+
+		err := audit.Write(audit.Record{
+			Actor:        r.RemoteAddr,
+			ClaimedActor: r.Header.Get("X-Gopass-Actor"),
+			Token:        tokenFingerprint(r.Header.Get("Authorization")),
+			Client:       "server " + r.UserAgent(),
+			Entry:        entry,
+			Operation:    audit.OpHTTP,
+			Result:       fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, rec.status),
+		})
+		if err != nil {
+			log.Printf("Failed to write audit record: %v", err)
+		}
+	})
+}
+
+func tokenFingerprint(authorization string) string {
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/duykhoa/gopass/internal/backup"
+)
+
+// maxBundleSize limits the size of an uploaded backup bundle.
+const maxBundleSize = 64 << 20
+
+type exportRequest struct {
+	Format           string `json:"format"`
+	Subtree          string `json:"subtree"`
+	BundlePassphrase string `json:"bundle_passphrase"`
+	ConfirmPlaintext bool   `json:"confirm_plaintext"`
+}
+
+// adminExportHandler streams an export of the store. The entries are
+// decrypted with the X-Gopass-Passphrase header.
+func adminExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body exportRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Export to memory first, a failure must not send a partial file
+	var buf bytes.Buffer
+	_, err := backup.Export(&buf, backup.ExportRequest{
+		Format:           body.Format,
+		Subtree:          body.Subtree,
+		Passphrase:       r.Header.Get("X-Gopass-Passphrase"),
+		BundlePassphrase: body.BundlePassphrase,
+		ConfirmPlaintext: body.ConfirmPlaintext,
+	})
+	if errors.Is(err, backup.ErrPlaintextNotConfirmed) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to export: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	contentType, ext := "application/octet-stream", "gpg"
+	switch body.Format {
+	case backup.FormatCSV:
+		contentType, ext = "text/csv", "csv"
+	case backup.FormatJSON:
+		contentType, ext = "application/json", "json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=gopass-export.%s", ext))
+	w.Write(buf.Bytes())
+}
+
+// adminRestoreHandler restores the bundle sent as the request body, it is
+// decrypted with the X-Gopass-Bundle-Passphrase header.
+func adminRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBundleSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result := backup.Restore(backup.RestoreRequest{
+		Data:             data,
+		BundlePassphrase: r.Header.Get("X-Gopass-Bundle-Passphrase"),
+		Overwrite:        r.URL.Query().Get("overwrite") == "true",
+		VerifyOnly:       r.URL.Query().Get("verify") == "true",
+	})
+	if result.Err != nil {
+		http.Error(w, fmt.Sprintf("Failed to restore: %v", result.Err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"created_at": result.Manifest.CreatedAt,
+		"entries":    len(result.Manifest.Entries),
+		"restored":   result.Restored,
+		"skipped":    append([]string{}, result.Skipped...),
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+const sseHeartbeat = 30 * time.Second
+
+type sseEvent struct {
+	Type    service.EventType `json:"type"`
+	Message string            `json:"message,omitempty"`
+	Data    service.Payload   `json:"data,omitempty"`
+}
+
+// eventsHandler streams store changes as server-sent events.
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := make(chan service.Event, 16)
+	sub := service.Events.Subscribe(func(ev service.Event) {
+		select {
+		case events <- ev:
+		case <-r.Context().Done():
+		}
+	})
+	defer sub.Unsubscribe()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case ev := <-events:
+			data, err := json.Marshal(sseEvent{Type: ev.Type, Message: ev.Message, Data: ev.Data})
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+			flusher.Flush()
+		}
+	}
+}
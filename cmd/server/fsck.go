@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+type fsckProblem struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Fixed    bool   `json:"fixed"`
+	FixError string `json:"fix_error,omitempty"`
+}
+
+// adminFsckHandler checks the store. GET only reports problems, POST fixes
+// them as requested by the body. The X-Gopass-Passphrase header, when set,
+// decrypts every entry and is needed to re-encrypt entries.
+func adminFsckHandler(w http.ResponseWriter, r *http.Request) {
+	req := service.FsckRequest{Passphrase: r.Header.Get("X-Gopass-Passphrase")}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var body struct {
+			Fix             bool `json:"fix"`
+			RemovePlaintext bool `json:"remove_plaintext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Fix = body.Fix
+		req.RemovePlaintext = body.RemovePlaintext
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result := service.Fsck(req)
+	if result.Err != nil {
+		http.Error(w, "Failed to check password store: "+result.Err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	problems := make([]fsckProblem, 0, len(result.Problems))
+	for _, p := range result.Problems {
+		fp := fsckProblem{
+			Path:     p.Path,
+			Kind:     string(p.Kind),
+			Severity: string(p.Severity),
+			Message:  p.Message,
+			Fixed:    p.Fixed,
+		}
+		if p.FixErr != nil {
+			fp.FixError = p.FixErr.Error()
+		}
+		problems = append(problems, fp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"checked":  result.Checked,
+		"problems": problems,
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/duykhoa/gopass/internal/importer"
+)
+
+// maxImportSize limits the size of an uploaded export.
+const maxImportSize = 32 << 20
+
+type importItem struct {
+	Entry    string `json:"entry"`
+	Action   string `json:"action"`
+	Conflict bool   `json:"conflict"`
+	Error    string `json:"error,omitempty"`
+}
+
+type importResponse struct {
+	DryRun   bool         `json:"dry_run"`
+	Imported int          `json:"imported"`
+	Items    []importItem `json:"items"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// importHandler imports the export sent as the request body. The format,
+// prefix, strategy and dry_run query parameters select how it is imported.
+func importHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("format") == "" {
+		http.Error(w, "Missing 'format' query parameter", http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := query.Get("dry_run") == "true"
+	result := importer.Import(importer.ImportRequest{
+		Format:   query.Get("format"),
+		Data:     data,
+		Prefix:   query.Get("prefix"),
+		Strategy: importer.Strategy(query.Get("strategy")),
+		DryRun:   dryRun,
+	})
+	if result.Plan.Items == nil && result.Err != nil {
+		http.Error(w, fmt.Sprintf("Failed to import: %v", result.Err), http.StatusBadRequest)
+		return
+	}
+
+	resp := importResponse{DryRun: dryRun, Imported: result.Imported, Items: []importItem{}}
+	for _, item := range result.Plan.Items {
+		ii := importItem{Entry: item.Entry, Action: string(item.Action), Conflict: item.Conflict}
+		if item.Err != nil {
+			ii.Error = item.Err.Error()
+		}
+		resp.Items = append(resp.Items, ii)
+	}
+	if result.Err != nil {
+		resp.Error = result.Err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
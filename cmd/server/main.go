@@ -1,28 +1,81 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/duykhoa/gopass/internal/audit"
 	"github.com/duykhoa/gopass/internal/config"
-	"github.com/duykhoa/gopass/internal/gpg"
 	"github.com/duykhoa/gopass/internal/service"
-	"github.com/duykhoa/gopass/internal/store"
+	"github.com/duykhoa/gopass/internal/watcher"
 )
 
 func main() {
+	if err := config.Err(); err != nil {
+		log.Printf("Invalid configuration, using the defaults: %v", err)
+	}
+	if err := audit.Setup("server"); err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+
 	http.HandleFunc("/", helloHandler)
 	http.HandleFunc("/secrets", listSecretsHandler)
 	http.HandleFunc("/secrets/", secretHandler)
 	http.HandleFunc("/init", initHandler)
+	http.HandleFunc("/stores", listStoresHandler)
+	http.HandleFunc("/events", eventsHandler)
+	http.HandleFunc("/import", importHandler)
+	http.HandleFunc("/admin/export", loopbackOnly(adminExportHandler))
+	http.HandleFunc("/admin/restore", loopbackOnly(adminRestoreHandler))
+	http.HandleFunc("/admin/fsck", loopbackOnly(adminFsckHandler))
+	http.HandleFunc("/admin/reload", loopbackOnly(adminReloadHandler))
+	http.HandleFunc("/admin/remotes", loopbackOnly(adminRemotesHandler))
+	http.HandleFunc("/admin/remotes/sync", loopbackOnly(adminRemoteSyncHandler))
+	http.HandleFunc("/admin/remotes/push", loopbackOnly(adminRemotePushHandler))
+	http.HandleFunc("/admin/recipients", loopbackOnly(adminRecipientsHandler))
+	http.HandleFunc("/admin/recipients/sign", loopbackOnly(adminRecipientsSignHandler))
+	http.HandleFunc("/admin/keys", loopbackOnly(adminKeysHandler))
+
+	if w, err := watcher.NewMounts(config.Mounts(), service.Events); err != nil {
+		log.Printf("Store watcher is disabled: %v", err)
+	} else {
+		defer w.Close()
+		go w.Run(context.Background())
+	}
+
+	go reloadOnSignal()
 
-	fmt.Println("Server is listening on port 8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	// Listeners are read once, changing them needs a restart
+	handler := auditMiddleware(http.DefaultServeMux)
+	errs := make(chan error)
+	for _, addr := range config.ServerListen() {
+		go func() {
+			errs <- http.ListenAndServe(addr, handler)
+		}()
+		fmt.Printf("Server is listening on %s\n", addr)
+	}
+	log.Fatal(<-errs)
+}
+
+// loopbackOnly serves the handler to clients on the loopback interface only.
+// The admin routes export the store, change its keys, remotes and
+// configuration; some need no passphrase, and anyone reaching the server
+// could e.g. push the store to their own repository otherwise.
+func loopbackOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			http.Error(w, "The admin routes can only be used from this machine", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
 }
 
 func helloHandler(w http.ResponseWriter, r *http.Request) {
@@ -30,17 +83,51 @@ func helloHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func listSecretsHandler(w http.ResponseWriter, r *http.Request) {
-	storeDir := config.PasswordStoreDir()
-	files, err := os.ReadDir(storeDir)
+	secrets, err := service.ListEntries()
 	if err != nil {
 		http.Error(w, "Failed to read password store", http.StatusInternalServerError)
 		return
 	}
+	if store := r.URL.Query().Get("store"); store != "" {
+		if _, ok := config.MountByName(store); !ok {
+			http.Error(w, "Unknown store: "+store, http.StatusNotFound)
+			return
+		}
+		inStore := make([]string, 0, len(secrets))
+		for _, secret := range secrets {
+			if service.StoreOf(secret) == store {
+				inStore = append(inStore, secret)
+			}
+		}
+		secrets = inStore
+	}
 
-	var secrets []string
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".gpg") {
-			secrets = append(secrets, strings.TrimSuffix(file.Name(), ".gpg"))
+	q := r.URL.Query().Get("q")
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		q = strings.TrimSpace(q + " tag:" + tag)
+	}
+	if r.URL.Query().Get("favorite") == "true" {
+		q = strings.TrimSpace(q + " is:favorite")
+	}
+	if q != "" {
+		// The server never falls back to the cached passphrase of the local UIs
+		passphrase := r.Header.Get("X-Gopass-Passphrase")
+		if passphrase == "" && service.QueryUsesIndex(q) {
+			http.Error(w, "X-Gopass-Passphrase header is required", http.StatusBadRequest)
+			return
+		}
+		results, err := service.Search(service.SearchRequest{
+			Query:      q,
+			Entries:    secrets,
+			Passphrase: passphrase,
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to search: %v", err), http.StatusBadRequest)
+			return
+		}
+		secrets = make([]string, 0, len(results))
+		for _, result := range results {
+			secrets = append(secrets, result.Entry)
 		}
 	}
 
@@ -71,7 +158,6 @@ func viewSecret(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req := service.DecryptRequest{
-		StoreDir:   config.PasswordStoreDir(),
 		Entry:      secretName,
 		Passphrase: passphrase,
 		CachePath:  service.GetDefaultCachePath(),
@@ -109,18 +195,9 @@ func updateSecret(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	encryptedValue, err := gpg.EncryptWithGPGKey([]byte(newValue), gpgID)
+	err = service.SaveEntry(secretName, []byte(newValue))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to encrypt secret: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	storeDir := config.PasswordStoreDir()
-	secretPath := filepath.Join(storeDir, secretName+".gpg")
-
-	err = os.WriteFile(secretPath, encryptedValue, 0600)
-	if err != nil {
-		http.Error(w, "Failed to write secret to store", http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to save secret: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -129,10 +206,8 @@ func updateSecret(w http.ResponseWriter, r *http.Request) {
 
 func deleteSecret(w http.ResponseWriter, r *http.Request) {
 	secretName := strings.TrimPrefix(r.URL.Path, "/secrets/")
-	storeDir := config.PasswordStoreDir()
-	secretPath := filepath.Join(storeDir, secretName+".gpg")
 
-	err := os.Remove(secretPath)
+	err := service.DeleteEntry(secretName)
 	if err != nil {
 		if os.IsNotExist(err) {
 			http.Error(w, "Secret not found", http.StatusNotFound)
@@ -158,9 +233,14 @@ func initHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gitRepoURL, ok := requestBody["git_repo_url"]
-	if !ok {
-		http.Error(w, "Missing 'git_repo_url' in request body", http.StatusBadRequest)
+	// Without 'git_repo_url' the new store stays local
+	gitRepoURL := requestBody["git_repo_url"]
+	if requestBody["mode"] == "clone" {
+		if gitRepoURL == "" {
+			http.Error(w, "Missing 'git_repo_url' in request body", http.StatusBadRequest)
+			return
+		}
+		cloneStore(w, gitRepoURL)
 		return
 	}
 
@@ -172,7 +252,7 @@ func initHandler(w http.ResponseWriter, r *http.Request) {
 
 	storeDir := config.PasswordStoreDir()
 
-	err = store.InitPasswordStore(storeDir, gpgKey, gitRepoURL)
+	err = service.InitStore(storeDir, gpgKey, gitRepoURL)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to initialize password store: %v", err), http.StatusInternalServerError)
 		return
@@ -180,3 +260,26 @@ func initHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// cloneStore clones the existing store at gitRepoURL and reports whether the
+// server key can decrypt it, with the public key to send to an admin if not.
+func cloneStore(w http.ResponseWriter, gitRepoURL string) {
+	result := service.CloneStore(config.PasswordStoreDir(), gitRepoURL)
+	if result.Err != nil {
+		http.Error(w, fmt.Sprintf("Failed to clone password store: %v", result.Err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]any{
+		"recipients":  result.Recipients,
+		"can_decrypt": result.CanDecrypt(),
+	}
+	if !result.CanDecrypt() {
+		response["help"] = result.AccessHelp()
+		response["key_id"] = result.Key.KeyID
+		response["fingerprint"] = result.Key.Fingerprint
+		response["public_key"] = result.PublicKey
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/gpg"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+type keyInfo struct {
+	KeyID       string   `json:"key_id"`
+	Fingerprint string   `json:"fingerprint"`
+	UIDs        []string `json:"uids"`
+	Expires     string   `json:"expires,omitempty"`
+	Trust       string   `json:"trust"`
+}
+
+type recipientInfo struct {
+	ID      string   `json:"id"`
+	Key     *keyInfo `json:"key,omitempty"`
+	Warning string   `json:"warning,omitempty"`
+}
+
+type folderRecipientsInfo struct {
+	Store      string          `json:"store"`
+	Folder     string          `json:"folder"`
+	Recipients []recipientInfo `json:"recipients"`
+	// Signature is why the .gpg-id is not trusted when signing is on
+	Signature string `json:"signature,omitempty"`
+}
+
+// adminRecipientsHandler manages the recipients of the folders. GET lists
+// them for every store and folder with its own .gpg-id, POST adds the key id
+// of the body to its folder and DELETE removes it. Both re-encrypt the
+// entries of the folder, decrypted with the X-Gopass-Passphrase header.
+func adminRecipientsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		listRecipients(w)
+		return
+	}
+
+	var body struct {
+		Folder string `json:"folder"`
+		ID     string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	passphrase := r.Header.Get("X-Gopass-Passphrase")
+	if passphrase == "" {
+		http.Error(w, "X-Gopass-Passphrase header is required", http.StatusBadRequest)
+		return
+	}
+
+	var n int
+	var err error
+	switch r.Method {
+	case http.MethodPost:
+		n, err = service.AddRecipient(body.Folder, body.ID, passphrase)
+	case http.MethodDelete:
+		n, err = service.RemoveRecipient(body.Folder, body.ID, passphrase)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update recipients: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"reencrypted": n})
+}
+
+func listRecipients(w http.ResponseWriter) {
+	folders, err := service.ListRecipients()
+	if err != nil {
+		http.Error(w, "Failed to list recipients: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	infos := []folderRecipientsInfo{}
+	for _, f := range folders {
+		info := folderRecipientsInfo{Store: f.Store, Folder: f.Folder, Recipients: []recipientInfo{}}
+		if f.Signature != nil {
+			info.Signature = f.Signature.Error()
+		}
+		for _, rc := range f.Recipients {
+			ri := recipientInfo{ID: rc.ID, Warning: rc.Warning()}
+			if !rc.Missing {
+				key := newKeyInfo(rc.Key)
+				ri.Key = &key
+			}
+			info.Recipients = append(info.Recipients, ri)
+		}
+		infos = append(infos, info)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+// adminRecipientsSignHandler signs the .gpg-id of the folder of the body with
+// the signing keys, unlocked with the X-Gopass-Passphrase header.
+func adminRecipientsSignHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Folder string `json:"folder"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := service.SignRecipients(body.Folder, r.Header.Get("X-Gopass-Passphrase")); err != nil {
+		http.Error(w, "Failed to sign recipients: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	listRecipients(w)
+}
+
+// adminKeysHandler imports the public key of the request body, armored or
+// binary, into the keyring and the .public-keys of the store named by the
+// store query parameter, the root store by default.
+func adminKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil || len(data) == 0 {
+		http.Error(w, "Missing public key in request body", http.StatusBadRequest)
+		return
+	}
+	key, err := service.ImportPublicKey(r.URL.Query().Get("store"), data)
+	if err != nil {
+		http.Error(w, "Failed to import key: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newKeyInfo(key))
+}
+
+func newKeyInfo(k gpg.KeyInfo) keyInfo {
+	info := keyInfo{KeyID: k.KeyID, Fingerprint: k.Fingerprint, UIDs: k.UIDs, Trust: k.Trust()}
+	if !k.Expires.IsZero() {
+		info.Expires = k.Expires.Format(time.RFC3339)
+	}
+	return info
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+)
+
+// reloadConfig reads the configuration file again and reopens the audit
+// sink, which may have moved.
+func reloadConfig() error {
+	if err := config.Reload(); err != nil {
+		return err
+	}
+	return audit.Setup("server")
+}
+
+// reloadOnSignal reloads the configuration on SIGHUP.
+func reloadOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if err := reloadConfig(); err != nil {
+			log.Printf("Configuration not reloaded: %v", err)
+			continue
+		}
+		log.Printf("Configuration reloaded from %s", config.ConfigFile())
+	}
+}
+
+// adminReloadHandler reloads the configuration like SIGHUP, reporting the
+// validation errors of an invalid file.
+func adminReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := reloadConfig(); err != nil {
+		http.Error(w, "Configuration not reloaded: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"config": config.ConfigFile()})
+}
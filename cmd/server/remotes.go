@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+type remoteInfo struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Sync bool   `json:"sync"`
+}
+
+// adminRemotesHandler manages the git remotes of a store, the root store
+// unless the store query parameter names another one. GET lists them, POST
+// adds one, PUT changes its URL and DELETE removes the one named by the name
+// query parameter.
+func adminRemotesHandler(w http.ResponseWriter, r *http.Request) {
+	store := r.URL.Query().Get("store")
+	var body struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		listRemotes(w, store)
+		return
+	case http.MethodPost:
+		err = service.AddRemote(store, body.Name, body.URL)
+	case http.MethodPut:
+		err = service.SetRemoteURL(store, body.Name, body.URL)
+	case http.MethodDelete:
+		err = service.RemoveRemote(store, r.URL.Query().Get("name"))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to update remotes: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	listRemotes(w, store)
+}
+
+func listRemotes(w http.ResponseWriter, store string) {
+	remotes, err := service.Remotes(store)
+	if err != nil {
+		http.Error(w, "Failed to list remotes: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	infos := []remoteInfo{}
+	for _, r := range remotes {
+		infos = append(infos, remoteInfo{Name: r.Name, URL: r.URL, Sync: r.Sync})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(infos)
+}
+
+// adminRemoteSyncHandler makes the remote named in the body the one the store
+// syncs with.
+func adminRemoteSyncHandler(w http.ResponseWriter, r *http.Request) {
+	remoteAction(w, r, service.SetSyncRemote)
+}
+
+// adminRemotePushHandler pushes the store to the remote named in the body,
+// e.g. a local store to a new empty repository.
+func adminRemotePushHandler(w http.ResponseWriter, r *http.Request) {
+	remoteAction(w, r, service.PushToRemote)
+}
+
+func remoteAction(w http.ResponseWriter, r *http.Request, action func(store, name string) error) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+		http.Error(w, "Missing 'name' in request body", http.StatusBadRequest)
+		return
+	}
+	store := r.URL.Query().Get("store")
+	if err := action(store, body.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	listRemotes(w, store)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+type storeInfo struct {
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+	Path   string `json:"path"`
+	GPGId  string `json:"gpg_id"`
+	Git    bool   `json:"git"`
+}
+
+// listStoresHandler lists the root store and the stores mounted in it.
+func listStoresHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stores := []storeInfo{}
+	for _, m := range config.Mounts() {
+		stores = append(stores, storeInfo{
+			Name:   m.Name,
+			Prefix: m.Prefix,
+			Path:   m.Path,
+			GPGId:  m.GPGId,
+			Git:    service.IsGitStore(m),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stores)
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/duykhoa/gopass/internal/audit"
 	"github.com/duykhoa/gopass/internal/pico"
 )
 
@@ -28,6 +29,10 @@ func main() {
 	// 4. Set the new logger as the default for the application.
 	slog.SetDefault(logger)
 
+	if err := audit.Setup("tui"); err != nil {
+		slog.Error("Failed to open audit log", slog.Any("error", err))
+	}
+
 	application := pico.NewPico()
 	application.Run()
 }
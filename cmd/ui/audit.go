@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+var auditColumns = []string{"Time", "Actor", "Client", "Operation", "Entry", "Result"}
+
+func auditRecordCell(r audit.Record, col int) string {
+	switch col {
+	case 0:
+		return r.Time.Local().Format(time.DateTime)
+	case 1:
+		if r.ClaimedActor != "" {
+			return fmt.Sprintf("%s (claims %s)", r.Actor, r.ClaimedActor)
+		}
+		return r.Actor
+	case 2:
+		return r.Client
+	case 3:
+		return string(r.Operation)
+	case 4:
+		return r.Entry
+	case 5:
+		return r.Result
+	}
+	return ""
+}
+
+func auditLogUI(a *ui.App) fyne.CanvasObject {
+	logPath := config.AuditLog()
+	status := widget.NewLabel("")
+	var records []audit.Record
+
+	load := func() {
+		if logPath == "" || logPath == audit.SyslogTarget {
+			status.SetText("Audit log is not written to a file, nothing to show")
+			records = nil
+			return
+		}
+		var err error
+		records, err = audit.ReadRecords(logPath)
+		if err != nil {
+			status.SetText(fmt.Sprintf("Failed to read audit log: %v", err))
+			return
+		}
+		status.SetText(fmt.Sprintf("%d records in %s", len(records), logPath))
+	}
+	load()
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(records), len(auditColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			// Newest first
+			idx := len(records) - 1 - id.Row
+			if idx >= 0 && idx < len(records) {
+				o.(*widget.Label).SetText(auditRecordCell(records[idx], id.Col))
+			}
+		},
+	)
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		if id.Row == -1 && id.Col >= 0 && id.Col < len(auditColumns) {
+			o.(*widget.Label).SetText(auditColumns[id.Col])
+		}
+	}
+	for col, width := range []float32{150, 90, 70, 90, 160, 200} {
+		table.SetColumnWidth(col, width)
+	}
+
+	backBtn := widget.NewButton("Back", func() {
+		a.ShowScreen("Main")
+	})
+	refreshBtn := widget.NewButton("Refresh", func() {
+		load()
+		table.Refresh()
+	})
+	verifyBtn := widget.NewButton("Verify", func() {
+		if logPath == "" || logPath == audit.SyslogTarget {
+			return
+		}
+		count, err := audit.VerifyFile(logPath)
+		if err != nil {
+			ui.ShowErrorDialog(a.Window, err)
+			return
+		}
+		dialog.ShowInformation("Audit Log", fmt.Sprintf("Hash chain is intact, %d records verified.", count), a.Window)
+	})
+
+	btnRow := container.NewHBox(backBtn, refreshBtn, verifyBtn)
+
+	return container.NewBorder(btnRow, status, nil, nil, table)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/backup"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+var exportFormats = map[string]string{
+	"Encrypted bundle":   backup.FormatBundle,
+	"CSV (unencrypted)":  backup.FormatCSV,
+	"JSON (unencrypted)": backup.FormatJSON,
+}
+
+// showExportDialog asks for the export options, then where to save the file.
+func showExportDialog(w fyne.Window) {
+	formatSelect := widget.NewSelect([]string{"Encrypted bundle", "CSV (unencrypted)", "JSON (unencrypted)"}, nil)
+	formatSelect.SetSelected("Encrypted bundle")
+	subtreeEntry := widget.NewEntry()
+	subtreeEntry.SetPlaceHolder("Whole store")
+	passEntry := widget.NewPasswordEntry()
+	bundlePassEntry := widget.NewPasswordEntry()
+	repeatEntry := widget.NewPasswordEntry()
+
+	formItems := []*widget.FormItem{
+		widget.NewFormItem("Format", formatSelect),
+		widget.NewFormItem("Folder", subtreeEntry),
+	}
+	cachedPass, cached := service.GetCachedPassphrase()
+	if !cached {
+		formItems = append(formItems, widget.NewFormItem("GPG passphrase", passEntry))
+	}
+	formItems = append(formItems,
+		widget.NewFormItem("Bundle passphrase", bundlePassEntry),
+		widget.NewFormItem("Repeat", repeatEntry),
+	)
+
+	d := dialog.NewForm("Export", "Export", "Cancel", formItems, func(ok bool) {
+		if !ok {
+			return
+		}
+		req := backup.ExportRequest{
+			Format:           exportFormats[formatSelect.Selected],
+			Subtree:          subtreeEntry.Text,
+			Passphrase:       passEntry.Text,
+			BundlePassphrase: bundlePassEntry.Text,
+		}
+		if cached {
+			req.Passphrase = cachedPass
+		}
+
+		if req.Format == backup.FormatBundle {
+			if req.BundlePassphrase == "" || req.BundlePassphrase != repeatEntry.Text {
+				dialog.ShowError(fmt.Errorf("bundle passphrases are empty or do not match"), w)
+				return
+			}
+			saveExport(w, req, "gopass-backup.gpg")
+			return
+		}
+
+		dialog.ShowConfirm("Unencrypted Export",
+			"The exported file will contain every secret in clear text. Anyone who can read it can read your passwords. Continue?",
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				req.ConfirmPlaintext = true
+				saveExport(w, req, "gopass-export."+req.Format)
+			}, w)
+	}, w)
+	d.Resize(fyne.NewSize(450, 300))
+	d.Show()
+}
+
+func saveExport(w fyne.Window, req backup.ExportRequest, fileName string) {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		count, err := backup.Export(writer, req)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		dialog.ShowInformation("Export", fmt.Sprintf("Exported %d entries to %s.", count, writer.URI().Name()), w)
+	}, w)
+	save.SetFileName(fileName)
+	save.Show()
+}
+
+// showRestoreDialog picks a bundle, then verifies and restores it.
+func showRestoreDialog(w fyne.Window) {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		passEntry := widget.NewPasswordEntry()
+		overwriteCheck := widget.NewCheck("Overwrite existing entries", nil)
+		d := dialog.NewForm("Restore "+reader.URI().Name(), "Restore", "Cancel",
+			[]*widget.FormItem{
+				widget.NewFormItem("Bundle passphrase", passEntry),
+				widget.NewFormItem("", overwriteCheck),
+			},
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				result := backup.Restore(backup.RestoreRequest{
+					Data:             data,
+					BundlePassphrase: passEntry.Text,
+					Overwrite:        overwriteCheck.Checked,
+				})
+				if result.Err != nil {
+					dialog.ShowError(result.Err, w)
+					return
+				}
+				dialog.ShowInformation("Restore", fmt.Sprintf("Restored %d entries, skipped %d existing entries.",
+					result.Restored, len(result.Skipped)), w)
+			}, w)
+		d.Resize(fyne.NewSize(400, 200))
+		d.Show()
+	}, w)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+// fyneClipboard is the window clipboard for the clipboard service, which
+// restores it from its own goroutine, so every call runs on the main one.
+type fyneClipboard struct {
+	clipboard fyne.Clipboard
+}
+
+func (c fyneClipboard) Content() (text string, ok bool) {
+	fyne.DoAndWait(func() {
+		text = c.clipboard.Content()
+	})
+	return text, true
+}
+
+func (c fyneClipboard) SetContent(text string) {
+	fyne.DoAndWait(func() {
+		c.clipboard.SetContent(text)
+	})
+}
+
+// onClipboard is set by the main screen to show the clipboard countdown in
+// its status bar.
+var onClipboard func(ev service.Event)
+
+// watchClipboard forwards the clipboard countdown to onClipboard.
+func watchClipboard() {
+	service.Events.Subscribe(func(ev service.Event) {
+		if ev.Type != service.EventClipboard {
+			return
+		}
+		fyne.Do(func() {
+			if onClipboard != nil {
+				onClipboard(ev)
+			}
+		})
+	})
+}
+
+// copyField copies a field of an entry, the clipboard is cleared after the
+// configured timeout.
+func copyField(w fyne.Window, entry, field, value string) {
+	service.ReportActivity()
+	board := fyneClipboard{w.Clipboard()}
+	go func() {
+		result := service.CopyToClipboard(service.CopyRequest{Clipboard: board, Value: value, Entry: entry, Field: field})
+		if result.Err != nil {
+			fyne.Do(func() {
+				dialog.ShowError(result.Err, w)
+			})
+		}
+	}()
+}
+
+// closeWindow clears a copied secret from the clipboard before quitting.
+func closeWindow(w fyne.Window) {
+	go func() {
+		service.ClearClipboard()
+		fyne.Do(w.Close)
+	}()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+// showCloneDialog clones an existing store into the store folder, onDone
+// runs once it is there.
+func showCloneDialog(a *ui.App, onDone func()) {
+	remoteEntry := widget.NewEntry()
+	remoteEntry.SetPlaceHolder("git@host:path/to/repo.git")
+	form := widget.NewForm(
+		widget.NewFormItem("Folder", widget.NewLabel(config.PasswordStoreDir())),
+		widget.NewFormItem("Remote Git URL", remoteEntry),
+	)
+	dialog.ShowCustomConfirm("Clone Password Store", "Clone", "Cancel", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		if remoteEntry.Text == "" {
+			ui.ShowErrorDialog(a.Window, fmt.Errorf("enter the remote to clone"))
+			return
+		}
+
+		progress := dialog.NewCustomWithoutButtons("Cloning", widget.NewProgressBarInfinite(), a.Window)
+		progress.Show()
+		go func() {
+			result := service.CloneStore(config.PasswordStoreDir(), remoteEntry.Text)
+			fyne.Do(func() {
+				progress.Hide()
+				if result.Err != nil {
+					ui.ShowErrorDialog(a.Window, fmt.Errorf("failed to clone password store: %w", result.Err))
+					return
+				}
+				if !result.CanDecrypt() {
+					showAccessDialog(a, result, onDone)
+					return
+				}
+				onDone()
+			})
+		}()
+	}, a.Window)
+}
+
+// showAccessDialog explains how to get access to a cloned store the user
+// cannot decrypt yet, with the public key to send to an admin.
+func showAccessDialog(a *ui.App, result service.CloneResult, onDone func()) {
+	help := widget.NewLabel(result.AccessHelp())
+	help.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(help)
+	if result.PublicKey != "" {
+		key := widget.NewMultiLineEntry()
+		key.SetText(result.PublicKey)
+		key.SetMinRowsVisible(8)
+		key.Disable()
+		copyBtn := widget.NewButton("Copy public key", func() {
+			// A public key is no secret, it is not cleared from the clipboard
+			a.Window.Clipboard().SetContent(result.PublicKey)
+		})
+		content.Add(key)
+		content.Add(copyBtn)
+	}
+	d := dialog.NewCustom("No access to the store yet", "Continue", content, a.Window)
+	d.SetOnClosed(onDone)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+var fsckColumns = []string{"Severity", "Path", "Problem", "Fix"}
+
+func fsckProblemCell(p service.Problem, col int) string {
+	switch col {
+	case 0:
+		return string(p.Severity)
+	case 1:
+		return p.Path
+	case 2:
+		return p.Message
+	case 3:
+		if p.Fixed {
+			return "fixed"
+		}
+		if p.FixErr != nil {
+			return p.FixErr.Error()
+		}
+	}
+	return ""
+}
+
+// fsckUI checks the store integrity and fixes the problems found.
+func fsckUI(a *ui.App) fyne.CanvasObject {
+	var problems []service.Problem
+	status := widget.NewLabel("")
+
+	run := func(req service.FsckRequest) {
+		result := service.Fsck(req)
+		if result.Err != nil {
+			ui.ShowErrorDialog(a.Window, result.Err)
+			return
+		}
+		problems = result.Problems
+		status.SetText(fmt.Sprintf("Checked %d entries: %d errors, %d warnings",
+			result.Checked, result.Count(service.SeverityError), result.Count(service.SeverityWarning)))
+	}
+	run(service.FsckRequest{})
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(problems), len(fsckColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			if id.Row < len(problems) {
+				o.(*widget.Label).SetText(fsckProblemCell(problems[id.Row], id.Col))
+			}
+		},
+	)
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		if id.Row == -1 && id.Col >= 0 && id.Col < len(fsckColumns) {
+			o.(*widget.Label).SetText(fsckColumns[id.Col])
+		}
+	}
+	for col, width := range []float32{70, 180, 300, 150} {
+		table.SetColumnWidth(col, width)
+	}
+
+	fix := func(passphrase string) {
+		hasPlaintext := false
+		for _, p := range problems {
+			if p.Kind == service.ProblemPlaintext {
+				hasPlaintext = true
+			}
+		}
+		req := service.FsckRequest{Passphrase: passphrase, Fix: true}
+		if !hasPlaintext {
+			run(req)
+			table.Refresh()
+			return
+		}
+		dialog.ShowConfirm("Plaintext Entries",
+			"Encrypt the plaintext entries and delete the plaintext files? Other problems are fixed either way.",
+			func(ok bool) {
+				req.RemovePlaintext = ok
+				run(req)
+				table.Refresh()
+			}, a.Window)
+	}
+
+	checkBtn := widget.NewButton("Check Again", func() {
+		run(service.FsckRequest{})
+		table.Refresh()
+	})
+	fixBtn := widget.NewButton("Fix", func() {
+		if pass, valid := service.GetCachedPassphrase(); valid {
+			fix(pass)
+			return
+		}
+		// Re-encrypting needs the passphrase, chmod does not
+		passEntry := widget.NewPasswordEntry()
+		d := dialog.NewForm("Enter GPG Passphrase", "OK", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Passphrase", passEntry)},
+			func(ok bool) {
+				if ok {
+					fix(passEntry.Text)
+				}
+			}, a.Window)
+		d.Resize(fyne.NewSize(400, 200))
+		d.Show()
+	})
+	backBtn := widget.NewButton("Back", func() {
+		a.ShowScreen("Main")
+	})
+
+	btnRow := container.NewHBox(backBtn, checkBtn, fixBtn)
+	return container.NewBorder(btnRow, status, nil, nil, table)
+}
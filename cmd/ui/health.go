@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+// healthUI is the password health dashboard.
+func healthUI(a *ui.App) fyne.CanvasObject {
+	defaults := service.DefaultAuditRequest("")
+
+	breachEntry := widget.NewEntry()
+	breachEntry.SetText(defaults.BreachFile)
+	breachEntry.SetPlaceHolder("Optional HIBP SHA-1 list")
+	chooseBtn := widget.NewButton("Choose...", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			reader.Close()
+			breachEntry.SetText(reader.URI().Path())
+		}, a.Window)
+	})
+	maxAgeEntry := widget.NewEntry()
+	maxAgeEntry.SetText(strconv.Itoa(int(defaults.MaxAge / (24 * time.Hour))))
+
+	progress := widget.NewProgressBar()
+	progress.Hide()
+	status := widget.NewLabel("Run the audit to check every password of the store")
+	summary := widget.NewLabel("")
+	results := widget.NewAccordion()
+
+	showReport := func(report service.AuditReport) {
+		summary.SetText(fmt.Sprintf("%d entries audited, %d issues", report.Total, report.Issues()))
+
+		var weak, reused, old, breached []string
+		for _, w := range report.Weak {
+			weak = append(weak, fmt.Sprintf("%s (%.0f bits)", w.Entry, w.Entropy))
+		}
+		for _, group := range report.Reused {
+			reused = append(reused, strings.Join(group, ", "))
+		}
+		for _, o := range report.Old {
+			old = append(old, fmt.Sprintf("%s (changed %s)", o.Entry, o.LastChanged.Local().Format(time.DateOnly)))
+		}
+		for _, b := range report.Breached {
+			breached = append(breached, fmt.Sprintf("%s (seen %d times)", b.Entry, b.Count))
+		}
+		var failed []string
+		for entry, err := range report.Errors {
+			failed = append(failed, fmt.Sprintf("%s: %v", entry, err))
+		}
+		sort.Strings(failed)
+
+		section := func(title string, lines []string) *widget.AccordionItem {
+			text := strings.Join(lines, "\n")
+			if text == "" {
+				text = "Nothing found"
+			}
+			return widget.NewAccordionItem(fmt.Sprintf("%s (%d)", title, len(lines)), widget.NewLabel(text))
+		}
+		results.Items = []*widget.AccordionItem{
+			section("Weak passwords", weak),
+			section("Reused passwords", reused),
+			section("Old passwords", old),
+			section("Breached passwords", breached),
+		}
+		if len(failed) > 0 {
+			results.Items = append(results.Items, section("Not checked", failed))
+		}
+		results.Refresh()
+	}
+
+	var runBtn *widget.Button
+	run := func(passphrase string) {
+		days, err := strconv.Atoi(maxAgeEntry.Text)
+		if err != nil || days < 0 {
+			ui.ShowErrorDialog(a.Window, fmt.Errorf("invalid age in days: %s", maxAgeEntry.Text))
+			return
+		}
+		req := service.AuditRequest{
+			Passphrase: passphrase,
+			MaxAge:     time.Duration(days) * 24 * time.Hour,
+			BreachFile: breachEntry.Text,
+		}
+
+		runBtn.Disable()
+		progress.SetValue(0)
+		progress.Show()
+		status.SetText("Auditing passwords...")
+		sub := service.Events.Subscribe(func(ev service.Event) {
+			if p, ok := ev.Data.(service.AuditProgress); ok && p.Total > 0 {
+				fyne.Do(func() { progress.SetValue(float64(p.Done) / float64(p.Total)) })
+			}
+		})
+
+		go func() {
+			report, err := service.Audit(context.Background(), req)
+			sub.Unsubscribe()
+			fyne.Do(func() {
+				runBtn.Enable()
+				progress.Hide()
+				if err != nil {
+					status.SetText("Audit failed")
+					ui.ShowErrorDialog(a.Window, err)
+					return
+				}
+				status.SetText(fmt.Sprintf("Audit completed at %s", time.Now().Format(time.TimeOnly)))
+				showReport(report)
+			})
+		}()
+	}
+
+	runBtn = widget.NewButton("Run Audit", func() {
+		if pass, valid := service.GetCachedPassphrase(); valid {
+			run(pass)
+			return
+		}
+		passEntry := widget.NewPasswordEntry()
+		d := dialog.NewForm("Enter GPG Passphrase", "OK", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Passphrase", passEntry)},
+			func(ok bool) {
+				if ok {
+					run(passEntry.Text)
+				}
+			}, a.Window)
+		d.Resize(fyne.NewSize(400, 200))
+		d.Show()
+	})
+	backBtn := widget.NewButton("Back", func() {
+		a.ShowScreen("Main")
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Breached passwords", container.NewBorder(nil, nil, nil, chooseBtn, breachEntry)),
+		widget.NewFormItem("Max age (days)", maxAgeEntry),
+	)
+	top := container.NewVBox(container.NewHBox(backBtn, runBtn), form, progress, summary)
+
+	return container.NewBorder(top, status, nil, nil, container.NewVScroll(results))
+}
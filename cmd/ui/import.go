@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/importer"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+var importColumns = []string{"Entry", "Action", "Conflict"}
+
+// importUI is a two step wizard: pick an export and preview the plan, then
+// import it.
+func importUI(a *ui.App) fyne.CanvasObject {
+	var data []byte
+	var plan importer.Plan
+
+	var formats []string
+	for _, f := range importer.Formats {
+		formats = append(formats, f.Name)
+	}
+	formatSelect := widget.NewSelect(formats, nil)
+	formatSelect.SetSelected(formats[0])
+
+	fileLabel := widget.NewLabel("No file selected")
+	prefixEntry := widget.NewEntry()
+	prefixEntry.SetText("imported")
+	strategyRadio := widget.NewRadioGroup([]string{
+		string(importer.StrategySkip),
+		string(importer.StrategyOverwrite),
+		string(importer.StrategySuffix),
+	}, nil)
+	strategyRadio.Horizontal = true
+	strategyRadio.SetSelected(string(importer.StrategySkip))
+
+	status := widget.NewLabel("Select an export file and preview the import")
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(plan.Items), len(importColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			if id.Row >= len(plan.Items) {
+				return
+			}
+			item := plan.Items[id.Row]
+			text := ""
+			switch id.Col {
+			case 0:
+				text = item.Entry
+			case 1:
+				text = string(item.Action)
+				if item.Err != nil {
+					text = fmt.Sprintf("failed: %v", item.Err)
+				}
+			case 2:
+				if item.Conflict {
+					text = "exists"
+				}
+			}
+			o.(*widget.Label).SetText(text)
+		},
+	)
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		if id.Row == -1 && id.Col >= 0 && id.Col < len(importColumns) {
+			o.(*widget.Label).SetText(importColumns[id.Col])
+		}
+	}
+	for col, width := range []float32{300, 140, 80} {
+		table.SetColumnWidth(col, width)
+	}
+
+	request := func(dryRun bool) importer.ImportRequest {
+		return importer.ImportRequest{
+			Format:   formatSelect.Selected,
+			Data:     data,
+			Prefix:   prefixEntry.Text,
+			Strategy: importer.Strategy(strategyRadio.Selected),
+			DryRun:   dryRun,
+		}
+	}
+
+	var importBtn *widget.Button
+	previewBtn := widget.NewButton("Preview", func() {
+		importBtn.Disable()
+		if data == nil {
+			ui.ShowErrorDialog(a.Window, fmt.Errorf("no export file selected"))
+			return
+		}
+		result := importer.Import(request(true))
+		if result.Err != nil {
+			ui.ShowErrorDialog(a.Window, result.Err)
+			return
+		}
+		plan = result.Plan
+		table.Refresh()
+		status.SetText(fmt.Sprintf("%d records: %d new, %d overwritten, %d renamed, %d skipped",
+			len(plan.Items), plan.Count(importer.ActionCreate), plan.Count(importer.ActionOverwrite),
+			plan.Count(importer.ActionRename), plan.Count(importer.ActionSkip)))
+		if len(plan.Items) > 0 {
+			importBtn.Enable()
+		}
+	})
+
+	importBtn = widget.NewButton("Import", func() {
+		importBtn.Disable()
+		result := importer.Import(request(false))
+		plan = result.Plan
+		table.Refresh()
+		if result.Err != nil {
+			ui.ShowErrorDialog(a.Window, result.Err)
+		}
+		status.SetText(fmt.Sprintf("Imported %d entries", result.Imported))
+		dialog.ShowInformation("Import", fmt.Sprintf("Imported %d entries.", result.Imported), a.Window)
+	})
+	importBtn.Disable()
+
+	// Changing any option invalidates the preview
+	resetPreview := func() {
+		plan = importer.Plan{}
+		table.Refresh()
+		importBtn.Disable()
+	}
+	formatSelect.OnChanged = func(string) { resetPreview() }
+	strategyRadio.OnChanged = func(string) { resetPreview() }
+	prefixEntry.OnChanged = func(string) { resetPreview() }
+
+	chooseBtn := widget.NewButton("Choose File...", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				ui.ShowErrorDialog(a.Window, err)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			defer reader.Close()
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				ui.ShowErrorDialog(a.Window, err)
+				return
+			}
+			data = content
+			fileLabel.SetText(reader.URI().Name())
+			resetPreview()
+		}, a.Window)
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Format", formatSelect),
+		widget.NewFormItem("File", container.NewHBox(chooseBtn, fileLabel)),
+		widget.NewFormItem("Folder", prefixEntry),
+		widget.NewFormItem("Existing entries", strategyRadio),
+	)
+
+	backBtn := widget.NewButton("Back", func() {
+		a.ShowScreen("Main")
+	})
+	btnRow := container.NewHBox(backBtn, previewBtn, importBtn)
+
+	top := container.NewVBox(btnRow, form)
+	return container.NewBorder(top, status, nil, nil, table)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+// lockShortcut locks the store, Ctrl+L or Cmd+L on macOS.
+var lockShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyL, Modifier: fyne.KeyModifierShortcutDefault}
+
+// secretDialogs are the open dialogs showing decrypted content, they are
+// closed when the store locks.
+var secretDialogs []dialog.Dialog
+
+// showSecretDialog shows a dialog holding decrypted content.
+func showSecretDialog(d dialog.Dialog) {
+	secretDialogs = append(secretDialogs, d)
+	d.SetOnClosed(func() {
+		secretDialogs = slices.DeleteFunc(secretDialogs, func(open dialog.Dialog) bool { return open == d })
+	})
+	d.Show()
+}
+
+// onLocked is set by the main screen to show why the store locked.
+var onLocked func(ev service.Event)
+
+// watchLock runs the auto-lock and closes the secret dialogs when the store
+// locks, whichever app locked it.
+func watchLock() {
+	service.Events.Subscribe(func(ev service.Event) {
+		if ev.Type != service.EventLocked {
+			return
+		}
+		fyne.Do(func() {
+			for _, d := range slices.Clone(secretDialogs) {
+				d.Hide()
+			}
+			if onLocked != nil {
+				onLocked(ev)
+			}
+		})
+	})
+	go service.AutoLock(context.Background())
+}
+
+// lockStore locks the store now, off the main goroutine as clearing the
+// clipboard waits for it.
+func lockStore(a *ui.App) {
+	go func() {
+		if err := service.Lock(); err != nil {
+			fyne.Do(func() {
+				ui.ShowErrorDialog(a.Window, err)
+			})
+		}
+	}()
+}
+
+// active wraps a callback to report it as user activity, postponing the
+// auto-lock.
+func active(f func()) func() {
+	return func() {
+		service.ReportActivity()
+		f()
+	}
+}
@@ -1,10 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
+	"path"
 	"regexp"
 	"strings"
 
@@ -17,32 +18,53 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/audit"
 	"github.com/duykhoa/gopass/internal/config"
 	"github.com/duykhoa/gopass/internal/ui"
 
-	"github.com/duykhoa/gopass/internal/git"
 	"github.com/duykhoa/gopass/internal/gpg"
 	"github.com/duykhoa/gopass/internal/service"
-	"github.com/duykhoa/gopass/internal/store"
+	"github.com/duykhoa/gopass/internal/watcher"
 )
 
 func listPasswordEntries(dir string) ([]string, error) {
+	return service.ListPasswordEntries(dir)
+}
+
+// allStores is the store switcher choice listing the entries of every store.
+const allStores = "All stores"
+
+// listStoreEntries lists the entries of one store, or of every store.
+func listStoreEntries(store string) ([]string, error) {
+	all, err := service.ListEntries()
+	if err != nil || store == allStores {
+		return all, err
+	}
 	var entries []string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".gpg") {
-			rel, _ := filepath.Rel(dir, path)
-			entries = append(entries, strings.TrimSuffix(rel, ".gpg"))
+	for _, entry := range all {
+		if service.StoreOf(entry) == store {
+			entries = append(entries, entry)
 		}
-		return nil
-	})
-	return entries, err
+	}
+	return entries, nil
+}
+
+// Helper: filter entries with a search query, best matches first
+func filterEntries(entries []string, query string) ([]string, error) {
+	results, err := service.Search(service.SearchRequest{Query: query, Entries: entries})
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]string, 0, len(results))
+	for _, result := range results {
+		filtered = append(filtered, result.Entry)
+	}
+	return filtered, nil
 }
 
 // Helper: show add/edit dialog with dynamic fields
-func showAddOrEditDialog(w fyne.Window, title, okLabel, cancelLabel, entryName, templateName string, initialValues map[string]string, onSave func(entryName, templateName string, values map[string]string)) {
+// namePrefix prefills the name of a new entry, e.g. with the selected folder.
+func showAddOrEditDialog(w fyne.Window, title, okLabel, cancelLabel, entryName, namePrefix, templateName string, initialValues map[string]string, onSave func(entryName, templateName string, values map[string]string, meta service.EntryMeta)) {
 	var showDialog func(selectedTemplate string, entryNameValue string)
 	showDialog = func(selectedTemplate string, entryNameValue string) {
 		templateNames := []string{service.TemplateFreeForm, service.TemplateEmailAndPassword}
@@ -75,6 +97,8 @@ func showAddOrEditDialog(w fyne.Window, title, okLabel, cancelLabel, entryName,
 				formItems = append(formItems, widget.NewFormItem(c.String(field), entry))
 			}
 		}
+		tagsEntry, favoriteCheck, metaItems := newMetaFormItems(service.EntryMeta{})
+		formItems = append(formItems, metaItems...)
 		d := dialog.NewForm(title, okLabel, cancelLabel, formItems, func(ok bool) {
 			if !ok {
 				return
@@ -85,7 +109,7 @@ func showAddOrEditDialog(w fyne.Window, title, okLabel, cancelLabel, entryName,
 			for k, entry := range fieldWidgets {
 				values[k] = entry.Text
 			}
-			onSave(entryNameVal, templateName, values)
+			onSave(entryNameVal, templateName, values, metaFromForm(tagsEntry, favoriteCheck))
 		}, w)
 		d.Resize(fyne.NewSize(500, 400))
 		d.Show()
@@ -96,14 +120,38 @@ func showAddOrEditDialog(w fyne.Window, title, okLabel, cancelLabel, entryName,
 	}
 	// Start with initial template
 	if templateName != "" {
-		showDialog(templateName, "")
+		showDialog(templateName, namePrefix)
 	} else {
-		showDialog(service.TemplateFreeForm, "")
+		showDialog(service.TemplateFreeForm, namePrefix)
+	}
+}
+
+// Helper: tags and favorite form items shared by the add and edit dialogs
+func newMetaFormItems(meta service.EntryMeta) (*widget.Entry, *widget.Check, []*widget.FormItem) {
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("Comma separated, e.g. work, dev")
+	tagsEntry.SetText(strings.Join(meta.Tags, ", "))
+	favoriteCheck := widget.NewCheck("", nil)
+	favoriteCheck.SetChecked(meta.Favorite)
+	return tagsEntry, favoriteCheck, []*widget.FormItem{
+		widget.NewFormItem("Tags", tagsEntry),
+		widget.NewFormItem("Favorite", favoriteCheck),
 	}
 }
 
+// Helper: read tags and favorite back from the form items
+func metaFromForm(tagsEntry *widget.Entry, favoriteCheck *widget.Check) service.EntryMeta {
+	var tags []string
+	for _, tag := range strings.Split(tagsEntry.Text, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return service.EntryMeta{Tags: tags, Favorite: favoriteCheck.Checked}
+}
+
 // Helper: show edit dialog with fields from decrypted content
-func showEditDialogWithContent(w fyne.Window, entryName, content string, onSave func(values map[string]string)) {
+func showEditDialogWithContent(w fyne.Window, entryName, content string, onSave func(values map[string]string, meta service.EntryMeta)) {
 	templateName := getTemplateFromContent(content)
 	_ = entryName
 	tmpl := service.GetTemplateByName(templateName)
@@ -135,6 +183,8 @@ func showEditDialogWithContent(w fyne.Window, entryName, content string, onSave
 		fieldWidgets[field] = entry
 		formItems = append(formItems, widget.NewFormItem(c.String(field), entry))
 	}
+	tagsEntry, favoriteCheck, metaItems := newMetaFormItems(service.ParseEntry(content).EntryMeta())
+	formItems = append(formItems, metaItems...)
 	d := dialog.NewForm("Edit Entry", "Save", "Cancel", formItems, func(ok bool) {
 		if !ok {
 			return
@@ -143,40 +193,30 @@ func showEditDialogWithContent(w fyne.Window, entryName, content string, onSave
 		for k, entry := range fieldWidgets {
 			newValues[k] = entry.Text
 		}
-		onSave(newValues)
+		onSave(newValues, metaFromForm(tagsEntry, favoriteCheck))
 	}, w)
 	d.Resize(fyne.NewSize(500, 400))
-	d.Show()
+	showSecretDialog(d)
 }
 
 // Helper: parse template name from content
 func getTemplateFromContent(content string) string {
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "template:") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "template:"))
-		}
-	}
-	return service.TemplateFreeForm
+	return service.ParseEntry(content).Template
 }
 
 // Helper: parse fields from content
 func parseFieldsFromContent(content string, tmpl *service.Template) map[string]string {
+	parsed := service.ParseEntry(content)
 	values := map[string]string{}
-	lines := strings.Split(content, "\n")
 	for _, field := range tmpl.Fields {
-		for _, line := range lines {
-			if strings.HasPrefix(line, field+":") {
-				values[field] = strings.TrimSpace(strings.TrimPrefix(line, field+":"))
-			}
-		}
+		values[field] = parsed.Get(field)
 	}
 	return values
 }
 
 func checkPasswordStoreAndInitIfNotExist(a *ui.App) fyne.CanvasObject {
 	// Show setup message and Init button
-	info := widget.NewLabel("Password store is not set up yet. Please press Init to start.")
+	info := widget.NewLabel("Password store is not set up yet. Please press Init to create one, or Clone to join an existing one.")
 	info.Wrapping = fyne.TextWrapWord
 
 	initBtn := widget.NewButton("Init", func() {
@@ -191,7 +231,7 @@ func checkPasswordStoreAndInitIfNotExist(a *ui.App) fyne.CanvasObject {
 		help := widget.NewLabel("If you don't have a GPG key, create it using 'gpg --full-generate-key', then run 'gpg -K' to find the key id.")
 		form := widget.NewForm(
 			widget.NewFormItem("Folder Name", folderEntry),
-			widget.NewFormItem("Remote Git URL (SSH)", remoteEntry),
+			widget.NewFormItem("Remote Git URL (SSH, optional)", remoteEntry),
 			widget.NewFormItem("Key ID", keyIdEntry),
 		)
 		dialog.ShowCustomConfirm(
@@ -201,16 +241,16 @@ func checkPasswordStoreAndInitIfNotExist(a *ui.App) fyne.CanvasObject {
 				if !ok {
 					return
 				}
-				home, _ := os.UserHomeDir()
-				baseDir := filepath.Join(home, folderEntry.Text)
+				baseDir := config.PasswordStoreDir()
 				remote := remoteEntry.Text
 				keyId := keyIdEntry.Text
 
-				if matched, err := regexp.MatchString(`^git@[\w\.-]+:[\w\./-]+\.git$`, remote); !matched || err != nil {
+				// Without remote the store stays local, one can be added later
+				if matched, err := regexp.MatchString(`^git@[\w\.-]+:[\w\./-]+\.git$`, remote); remote != "" && (!matched || err != nil) {
 					ui.ShowErrorDialog(a.Window, fmt.Errorf("remote url doesn't follow expected format"))
 					return
 				}
-				err := store.InitPasswordStore(baseDir, keyId, remote)
+				err := service.InitStore(baseDir, keyId, remote)
 				if err != nil {
 					ui.ShowErrorDialog(a.Window, fmt.Errorf("failed to init password store: %w", err))
 					return
@@ -220,6 +260,7 @@ func checkPasswordStoreAndInitIfNotExist(a *ui.App) fyne.CanvasObject {
 				d := dialog.NewCustom("Success", "Cancel", content, a.Window)
 				d.SetButtons([]fyne.CanvasObject{widget.NewButtonWithIcon("OK", theme.ConfirmIcon(), func() {
 					d.Hide()
+					watchStores()
 					a.ShowScreen("Main")
 				})})
 
@@ -227,59 +268,227 @@ func checkPasswordStoreAndInitIfNotExist(a *ui.App) fyne.CanvasObject {
 			}, a.Window)
 	})
 
+	cloneBtn := widget.NewButton("Clone", func() {
+		showCloneDialog(a, func() {
+			watchStores()
+			a.ShowScreen("Main")
+		})
+	})
+
 	infoWrap := container.NewGridWrap(fyne.NewSize(400, 60), info)
 
 	vbox := container.NewVBox(
 		infoWrap,
 		initBtn,
+		cloneBtn,
 	)
 	vbox.Resize(fyne.NewSize(500, 200))
 
 	return vbox
 }
 
+// onStoreChanged is set by the main screen to reload its entries when the
+// store changes, either through the service or on disk.
+var onStoreChanged func(ev service.Event)
+
+// watchStores reloads the visible entries whenever a store changes.
+func watchStores() {
+	service.Events.Subscribe(func(ev service.Event) {
+		if !ev.IsEntryEvent() {
+			return
+		}
+		fyne.Do(func() {
+			if onStoreChanged != nil {
+				onStoreChanged(ev)
+			}
+		})
+	})
+
+	w, err := watcher.NewMounts(config.Mounts(), service.Events)
+	if err != nil {
+		slog.Error("Store watcher is disabled", slog.Any("error", err))
+		return
+	}
+	go w.Run(context.Background())
+}
+
 func mainUI(a *ui.App) fyne.CanvasObject {
 	status := widget.NewLabel("")
-	entries, _ := listPasswordEntries(config.PasswordStoreDir())
-	selectedIdx := -1
-	var decryptBtn, addBtn, editBtn, deleteBtn, syncBtn *widget.Button
+	storeFilter := allStores
+	entries, _ := listStoreEntries(storeFilter)
+	// Either an entry or a folder of the tree is selected
+	var selectedEntry, selectedFolder string
+	var decryptBtn, addBtn, editBtn, deleteBtn, moveBtn, reencryptBtn, syncBtn *widget.Button
+	updateButtons := func() {
+		for _, btn := range []*widget.Button{decryptBtn, editBtn} {
+			if btn == nil {
+				continue
+			}
+			if selectedEntry != "" {
+				btn.Enable()
+			} else {
+				btn.Disable()
+			}
+		}
+		for _, btn := range []*widget.Button{deleteBtn, moveBtn, reencryptBtn} {
+			if btn == nil {
+				continue
+			}
+			if selectedEntry != "" || selectedFolder != "" {
+				btn.Enable()
+			} else {
+				btn.Disable()
+			}
+		}
+	}
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search entries, e.g. aws url:amazon")
+
+	// Tag sidebar: All, Favorites, then every tag from the search index
+	tagFilter := ""
+	var tags []string
+	tagList := widget.NewList(
+		func() int {
+			return len(tags) + 2
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			switch {
+			case i == 0:
+				o.(*widget.Label).SetText("All")
+			case i == 1:
+				o.(*widget.Label).SetText("Favorites")
+			case i-2 < len(tags):
+				o.(*widget.Label).SetText("# " + tags[i-2])
+			}
+		},
+	)
+	loadTags := func() {
+		// Tags live in the index, they are only listed once the store is unlocked
+		tags, _ = service.ListTags("")
+		tagList.Refresh()
+	}
+	loadTags()
 
 	entriesList := widget.NewList(
 		func() int {
-			entries, _ = listPasswordEntries(config.PasswordStoreDir())
+			all, _ := listStoreEntries(storeFilter)
+			var err error
+			entries, err = filterEntries(all, strings.TrimSpace(tagFilter+" "+searchEntry.Text))
+			if err != nil {
+				status.SetText(err.Error())
+			}
 			return len(entries)
 		},
 		func() fyne.CanvasObject {
 			return widget.NewLabel("")
 		},
 		func(i widget.ListItemID, o fyne.CanvasObject) {
-			entries, _ = listPasswordEntries(config.PasswordStoreDir())
 			if i < len(entries) {
 				o.(*widget.Label).SetText(entries[i])
 			}
 		},
 	)
 	entriesList.OnSelected = func(id widget.ListItemID) {
-		selectedIdx = id
+		service.ReportActivity()
+		selectedEntry, selectedFolder = entries[id], ""
 		status.SetText(fmt.Sprintf("Selected: %s", entries[id]))
-		if decryptBtn != nil {
-			decryptBtn.Enable()
+		updateButtons()
+	}
+
+	// Without a search or a tag the entries are shown in their folders
+	var tree *service.TreeNode
+	entriesTree := newEntriesTree(func() *service.TreeNode { return tree })
+	loadTree := func() {
+		all, _ := listStoreEntries(storeFilter)
+		tree = service.BuildTree(all)
+	}
+	loadTree()
+	entriesTree.OnSelected = func(uid widget.TreeNodeID) {
+		service.ReportActivity()
+		if isFolderUID(uid) {
+			selectedEntry, selectedFolder = "", folderOfUID(uid)
+			status.SetText(fmt.Sprintf("Selected folder: %s", selectedFolder))
+		} else {
+			selectedEntry, selectedFolder = uid, ""
+			status.SetText(fmt.Sprintf("Selected: %s", uid))
 		}
-		if editBtn != nil {
-			editBtn.Enable()
+		updateButtons()
+	}
+	refreshEntries := func() {
+		loadTree()
+		entriesTree.Refresh()
+		entriesList.Refresh()
+	}
+	clearSelection := func() {
+		selectedEntry, selectedFolder = "", ""
+		entriesList.UnselectAll()
+		entriesTree.UnselectAll()
+		updateButtons()
+	}
+
+	onStoreChanged = func(ev service.Event) {
+		loadTags()
+		refreshEntries()
+	}
+	onClipboard = func(ev service.Event) {
+		status.SetText(ev.Message)
+	}
+	onLocked = func(ev service.Event) {
+		status.SetText(ev.Message)
+	}
+
+	searchEntry.OnChanged = func(string) {
+		service.ReportActivity()
+		clearSelection()
+		if strings.TrimSpace(tagFilter+" "+searchEntry.Text) == "" {
+			entriesList.Hide()
+			entriesTree.Show()
+		} else {
+			entriesTree.Hide()
+			entriesList.Show()
 		}
-		if deleteBtn != nil {
-			deleteBtn.Enable()
+		refreshEntries()
+	}
+
+	tagList.OnSelected = func(id widget.ListItemID) {
+		switch {
+		case id == 0:
+			tagFilter = ""
+		case id == 1:
+			tagFilter = "is:favorite"
+		case id-2 < len(tags):
+			tagFilter = "tag:" + tags[id-2]
 		}
+		searchEntry.OnChanged(searchEntry.Text)
+	}
+	tagList.Select(0)
+
+	// Store switcher, only shown when stores are mounted
+	storeNames := []string{allStores}
+	for _, m := range config.Mounts() {
+		storeNames = append(storeNames, m.Name)
+	}
+	storeSelect := widget.NewSelect(storeNames, func(name string) {
+		storeFilter = name
+		searchEntry.OnChanged(searchEntry.Text)
+	})
+	storeSelect.SetSelected(allStores)
+	if len(storeNames) <= 2 {
+		storeSelect.Hide()
 	}
 
 	refreshBtn := widget.NewButton("Refresh", func() {
-		entriesList.Refresh()
+		loadTags()
+		refreshEntries()
 		status.SetText("Entries refreshed")
 	})
 
 	syncBtn = widget.NewButton("Sync", func() {
-		err := git.SyncWithRemote(config.PasswordStoreDir())
+		err := service.Sync()
 		if err != nil {
 			dialog.ShowError(err, a.Window)
 			return
@@ -288,28 +497,38 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 	})
 
 	addBtn = widget.NewButton("Add", func() {
-		showAddOrEditDialog(a.Window, "Add Entry", "Add", "Cancel", "", "", nil, func(entryName, templateName string, values map[string]string) {
+		prefix := selectedFolder
+		if selectedEntry != "" {
+			prefix = path.Dir(selectedEntry)
+		}
+		if prefix == "." {
+			prefix = ""
+		} else if prefix != "" {
+			prefix += "/"
+		}
+		showAddOrEditDialog(a.Window, "Add Entry", "Add", "Cancel", "", prefix, "", nil, func(entryName, templateName string, values map[string]string, meta service.EntryMeta) {
 			req := service.AddEditRequest{
 				EntryName:    entryName,
 				TemplateName: templateName,
 				Fields:       values,
+				Meta:         meta,
 			}
 			result := service.AddOrEditEntry(req)
 			if result.Err != nil {
 				dialog.ShowError(result.Err, a.Window)
 				return
 			}
-			entriesList.Refresh()
+			refreshEntries()
 			status.SetText("Entry added")
 		})
 	})
 
 	editBtn = widget.NewButton("Edit", func() {
-		if selectedIdx < 0 || selectedIdx >= len(entries) {
+		if selectedEntry == "" {
 			dialog.ShowError(fmt.Errorf("no entry selected"), a.Window)
 			return
 		}
-		entry := entries[selectedIdx]
+		entry := selectedEntry
 		pass, valid := service.GetCachedPassphrase()
 		if !valid {
 			passDialog := widget.NewPasswordEntry()
@@ -325,18 +544,20 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 						dialog.ShowError(result.Err, a.Window)
 						return
 					}
-					showEditDialogWithContent(a.Window, entry, result.Plaintext, func(values map[string]string) {
+					showEditDialogWithContent(a.Window, entry, result.Plaintext, func(values map[string]string, meta service.EntryMeta) {
 						req := service.AddEditRequest{
 							EntryName:    entry,
 							TemplateName: getTemplateFromContent(result.Plaintext),
 							Fields:       values,
+							Meta:         meta,
+							ExtraMeta:    service.ParseEntry(result.Plaintext).Meta,
 						}
 						editResult := service.AddOrEditEntry(req)
 						if editResult.Err != nil {
 							dialog.ShowError(editResult.Err, a.Window)
 							return
 						}
-						entriesList.Refresh()
+						refreshEntries()
 						status.SetText("Entry updated")
 					})
 				}, a.Window)
@@ -349,29 +570,52 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 			dialog.ShowError(result.Err, a.Window)
 			return
 		}
-		showEditDialogWithContent(a.Window, entry, result.Plaintext, func(values map[string]string) {
+		showEditDialogWithContent(a.Window, entry, result.Plaintext, func(values map[string]string, meta service.EntryMeta) {
 			req := service.AddEditRequest{
 				EntryName:    entry,
 				TemplateName: getTemplateFromContent(result.Plaintext),
 				Fields:       values,
+				Meta:         meta,
+				ExtraMeta:    service.ParseEntry(result.Plaintext).Meta,
 			}
 			editResult := service.AddOrEditEntry(req)
 			if editResult.Err != nil {
 				dialog.ShowError(editResult.Err, a.Window)
 				return
 			}
-			entriesList.Refresh()
+			refreshEntries()
 			status.SetText("Entry updated")
 		})
 	})
 	editBtn.Disable()
 
 	deleteBtn = widget.NewButton("Delete", func() {
-		if selectedIdx < 0 || selectedIdx >= len(entries) {
+		if selectedFolder != "" {
+			folder := selectedFolder
+			count := 0
+			if node := tree.Folder(folder); node != nil {
+				count = node.Count
+			}
+			dialog.ShowConfirm("Delete Folder", fmt.Sprintf("Are you sure you want to delete the folder '%s' and its %d entries?", folder, count), func(ok bool) {
+				if !ok {
+					return
+				}
+				n, err := service.DeleteFolder(folder)
+				if err != nil {
+					dialog.ShowError(err, a.Window)
+				} else {
+					status.SetText(fmt.Sprintf("Deleted %d entries", n))
+				}
+				clearSelection()
+				refreshEntries()
+			}, a.Window)
+			return
+		}
+		if selectedEntry == "" {
 			dialog.ShowError(fmt.Errorf("no entry selected"), a.Window)
 			return
 		}
-		entry := entries[selectedIdx]
+		entry := selectedEntry
 		dialog.ShowConfirm("Delete Entry", fmt.Sprintf("Are you sure you want to delete '%s'?", entry), func(ok bool) {
 			if !ok {
 				return
@@ -381,13 +625,38 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 				dialog.ShowError(err, a.Window)
 				return
 			}
-			entriesList.Refresh()
+			refreshEntries()
 			status.SetText("Entry deleted")
 		}, a.Window)
 	})
 	deleteBtn.Disable()
 
-	showDecryptedDialog := func(parent fyne.Window, text string) {
+	moveBtn = widget.NewButton("Move", func() {
+		name, folder := selectedEntry, selectedFolder != ""
+		if folder {
+			name = selectedFolder
+		}
+		showMoveDialog(a, name, folder, func(to string) {
+			clearSelection()
+			refreshEntries()
+			status.SetText(fmt.Sprintf("Moved %s to %s", name, to))
+		})
+	})
+	moveBtn.Disable()
+
+	reencryptBtn = widget.NewButton("Re-encrypt", func() {
+		name := selectedEntry
+		if selectedFolder != "" {
+			name = selectedFolder
+		}
+		status.SetText(fmt.Sprintf("Re-encrypting %s...", name))
+		reencrypt(a, name, func(n int) {
+			status.SetText(fmt.Sprintf("Re-encrypted %d entries", n))
+		})
+	})
+	reencryptBtn.Disable()
+
+	showDecryptedDialog := func(parent fyne.Window, entryName, text string) {
 		templateName := getTemplateFromContent(text)
 		tmpl := service.GetTemplateByName(templateName)
 		if tmpl == nil {
@@ -396,7 +665,6 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 		values := parseFieldsFromContent(text, tmpl)
 		var items []fyne.CanvasObject
 		c := cases.Title(language.English)
-		clipboard := parent.Clipboard()
 		// Minimum width is now hardcoded as 400 in entry.Resize
 		for _, field := range tmpl.Fields {
 			value := values[field]
@@ -411,7 +679,7 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 				entry.Wrapping = fyne.TextWrapWord
 				entryWrap := container.NewGridWrap(fyne.NewSize(400, 60), entry)
 				copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
-					clipboard.SetContent(entry.Text)
+					copyField(parent, entryName, field, entry.Text)
 				})
 				row := container.NewHBox(entryWrap, copyBtn)
 				items = append(items, label, row)
@@ -420,7 +688,7 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 				entry.SetText(value)
 				entryWrap := container.NewGridWrap(fyne.NewSize(400, 40), entry)
 				copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
-					clipboard.SetContent(entry.Text)
+					copyField(parent, entryName, field, entry.Text)
 				})
 				row := container.NewHBox(entryWrap, copyBtn)
 				items = append(items, label, row)
@@ -429,15 +697,15 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 		content := container.NewVBox(items...)
 		d := dialog.NewCustom("Decrypted", "OK", content, parent)
 		d.Resize(fyne.NewSize(500, 400))
-		d.Show()
+		showSecretDialog(d)
 	}
 
 	decryptBtn = widget.NewButton("Decrypt", func() {
-		if selectedIdx < 0 || selectedIdx >= len(entries) {
+		if selectedEntry == "" {
 			dialog.ShowError(fmt.Errorf("no entry selected"), a.Window)
 			return
 		}
-		entry := entries[selectedIdx]
+		entry := selectedEntry
 		pass, valid := service.GetCachedPassphrase()
 
 		if !valid {
@@ -455,7 +723,7 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 						dialog.ShowError(result.Err, a.Window)
 						return
 					}
-					showDecryptedDialog(a.Window, result.Plaintext)
+					showDecryptedDialog(a.Window, entry, result.Plaintext)
 				}, a.Window)
 			d.Resize(fyne.NewSize(400, 200))
 			d.Show()
@@ -466,37 +734,62 @@ func mainUI(a *ui.App) fyne.CanvasObject {
 			dialog.ShowError(result.Err, a.Window)
 			return
 		}
-		showDecryptedDialog(a.Window, result.Plaintext)
+		showDecryptedDialog(a.Window, entry, result.Plaintext)
 	})
 	decryptBtn.Disable()
 
+	for _, btn := range []*widget.Button{refreshBtn, addBtn, editBtn, deleteBtn, moveBtn, reencryptBtn, decryptBtn, syncBtn} {
+		btn.OnTapped = active(btn.OnTapped)
+	}
+	lockItem := fyne.NewMenuItem("Lock", func() { lockStore(a) })
+	lockItem.Shortcut = lockShortcut
+	a.Window.Canvas().AddShortcut(lockShortcut, func(fyne.Shortcut) { lockStore(a) })
+
 	// Add the buttons to the UI so they are used
-	btnRow := container.NewHBox(refreshBtn, addBtn, editBtn, deleteBtn, decryptBtn, syncBtn)
+	btnRow := container.NewHBox(refreshBtn, addBtn, editBtn, deleteBtn, moveBtn, reencryptBtn, decryptBtn, syncBtn)
 	entriesLabel := widget.NewLabel("Password Entries")
-	entriesScroll := container.NewVScroll(entriesList)
+	entriesScroll := container.NewVScroll(container.NewStack(entriesTree, entriesList))
 	entriesScroll.SetMinSize(fyne.NewSize(400, 300))
 	mainContent := container.NewVBox(
 		btnRow,
+		container.NewBorder(nil, nil, storeSelect, nil, searchEntry),
 		entriesLabel,
 		entriesScroll,
 	)
-	content := container.NewBorder(nil, status, nil, nil, mainContent)
+	tagScroll := container.NewVScroll(tagList)
+	tagScroll.SetMinSize(fyne.NewSize(140, 300))
+	content := container.NewBorder(nil, status, tagScroll, nil, mainContent)
 
 	// Add File menu
 	fileMenu := fyne.NewMenu("File",
-		fyne.NewMenuItem("Quit", func() { a.Window.Close() }),
+		fyne.NewMenuItem("Export...", func() { showExportDialog(a.Window) }),
+		fyne.NewMenuItem("Restore Backup...", func() { showRestoreDialog(a.Window) }),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Reload Configuration", func() { reloadConfig(a) }),
+		fyne.NewMenuItemSeparator(),
+		lockItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Quit", func() { closeWindow(a.Window) }),
 	)
 	gitMenu := fyne.NewMenu("Git",
 		fyne.NewMenuItem("Sync", func() {
-			err := git.SyncWithRemote(config.PasswordStoreDir())
+			err := service.Sync()
 			if err != nil {
 				dialog.ShowError(err, a.Window)
 				return
 			}
 			status.SetText("Sync completed")
 		}),
+		fyne.NewMenuItem("Remotes...", func() { a.ShowScreen("Remotes") }),
+	)
+	toolsMenu := fyne.NewMenu("Tools",
+		fyne.NewMenuItem("Import...", func() { a.ShowScreen("Import") }),
+		fyne.NewMenuItem("Password Health", func() { a.ShowScreen("PasswordHealth") }),
+		fyne.NewMenuItem("Check Store", func() { a.ShowScreen("Fsck") }),
+		fyne.NewMenuItem("Recipients", func() { a.ShowScreen("Recipients") }),
+		fyne.NewMenuItem("Audit Log", func() { a.ShowScreen("AuditLog") }),
 	)
-	mainMenu := fyne.NewMainMenu(fileMenu, gitMenu)
+	mainMenu := fyne.NewMainMenu(fileMenu, gitMenu, toolsMenu)
 	a.Window.SetMainMenu(mainMenu)
 
 	return content
@@ -508,14 +801,31 @@ func main() {
 	screens := ui.NewScreens()
 	screens.AddScreen("Main", mainUI)
 	screens.AddScreen("InitStore", checkPasswordStoreAndInitIfNotExist)
+	screens.AddScreen("AuditLog", auditLogUI)
+	screens.AddScreen("Import", importUI)
+	screens.AddScreen("PasswordHealth", healthUI)
+	screens.AddScreen("Fsck", fsckUI)
+	screens.AddScreen("Remotes", remotesUI)
+	screens.AddScreen("Recipients", recipientsUI)
 
 	app := &ui.App{Window: w, Screens: screens}
+	w.SetCloseIntercept(func() { closeWindow(w) })
+	watchClipboard()
+	watchLock()
 
 	if !gpg.CheckGPGAvailable() {
 		ui.ShowErrorDialog(w, fmt.Errorf("gpg command not found. Please install GnuPG (gpg) and restart the app"))
 		return
 	}
 
+	applyTheme(a)
+	if err := config.Err(); err != nil {
+		ui.ShowErrorDialog(w, fmt.Errorf("invalid configuration, using the defaults: %w", err))
+	}
+	if err := audit.Setup("ui"); err != nil {
+		slog.Error("Failed to open audit log", slog.Any("error", err))
+	}
+
 	_, err := os.Stat(config.PasswordStoreDir())
 	slog.Info("Checking password store directory", "path", config.PasswordStoreDir(), "error", err)
 
@@ -529,6 +839,7 @@ func main() {
 			return
 		}
 	} else {
+		watchStores()
 		app.ShowScreen("Main")
 	}
 
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+var recipientColumns = []string{"Recipient", "Fingerprint", "User IDs", "Expires", "Trust"}
+
+// recipientsUI manages who can read a folder: its recipients with their keys,
+// importing a teammate's public key, adding and removing recipients.
+func recipientsUI(a *ui.App) fyne.CanvasObject {
+	var folders []service.FolderRecipients
+	var current service.FolderRecipients
+	selected := -1
+	status := widget.NewLabel("")
+	warnings := widget.NewLabel("")
+	warnings.Wrapping = fyne.TextWrapWord
+	folderSelect := widget.NewSelect(nil, nil)
+
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(current.Recipients), len(recipientColumns)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			if id.Row < len(current.Recipients) {
+				o.(*widget.Label).SetText(recipientCell(current.Recipients[id.Row], id.Col))
+			}
+		},
+	)
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		if id.Row == -1 && id.Col >= 0 && id.Col < len(recipientColumns) {
+			o.(*widget.Label).SetText(recipientColumns[id.Col])
+		}
+	}
+	for col, width := range []float32{160, 320, 240, 100, 80} {
+		table.SetColumnWidth(col, width)
+	}
+	table.OnSelected = func(id widget.TableCellID) {
+		selected = id.Row
+	}
+
+	show := func(folder string) {
+		current = service.FolderRecipients{}
+		for _, f := range folders {
+			if f.Folder == folder {
+				current = f
+			}
+		}
+		selected = -1
+		table.UnselectAll()
+		table.Refresh()
+		warnings.SetText(strings.Join(current.Warnings(), "\n"))
+	}
+	load := func() {
+		var err error
+		folders, err = service.ListRecipients()
+		if err != nil {
+			status.SetText(err.Error())
+		}
+		var names []string
+		for _, f := range folders {
+			names = append(names, f.Folder)
+		}
+		folderSelect.Options = names
+		if current.Folder == "" && len(names) > 0 {
+			current.Folder = names[0]
+		}
+		folderSelect.SetSelected(current.Folder)
+		show(current.Folder)
+	}
+	folderSelect.OnChanged = show
+
+	// withPassphrase asks for the passphrase re-encrypting needs, unless it is
+	// cached
+	withPassphrase := func(run func(passphrase string)) {
+		if pass, valid := service.GetCachedPassphrase(); valid {
+			run(pass)
+			return
+		}
+		passEntry := widget.NewPasswordEntry()
+		d := dialog.NewForm("Enter GPG Passphrase", "OK", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Passphrase", passEntry)},
+			func(ok bool) {
+				if ok {
+					run(passEntry.Text)
+				}
+			}, a.Window)
+		d.Resize(fyne.NewSize(400, 200))
+		d.Show()
+	}
+	change := func(action func(passphrase string) (int, error), format string, args ...any) {
+		withPassphrase(func(passphrase string) {
+			status.SetText("Re-encrypting entries...")
+			go func() {
+				n, err := action(passphrase)
+				fyne.Do(func() {
+					load()
+					if err != nil {
+						ui.ShowErrorDialog(a.Window, err)
+						return
+					}
+					status.SetText(fmt.Sprintf(format, args...) + fmt.Sprintf(", re-encrypted %d entries", n))
+				})
+			}()
+		})
+	}
+	imported := func(data []byte) {
+		key, err := service.ImportPublicKey(current.Store, data)
+		if err != nil {
+			ui.ShowErrorDialog(a.Window, err)
+			return
+		}
+		load()
+		status.SetText(fmt.Sprintf("Imported %s, add it as a recipient to share the folder", key))
+	}
+
+	importFileBtn := widget.NewButton("Import Key File...", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				ui.ShowErrorDialog(a.Window, err)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				ui.ShowErrorDialog(a.Window, err)
+				return
+			}
+			imported(data)
+		}, a.Window)
+	})
+	importClipboardBtn := widget.NewButton("Import From Clipboard", func() {
+		text := a.Window.Clipboard().Content()
+		if strings.TrimSpace(text) == "" {
+			ui.ShowErrorDialog(a.Window, fmt.Errorf("the clipboard is empty, copy an armored public key first"))
+			return
+		}
+		imported([]byte(text))
+	})
+	addBtn := widget.NewButton("Add", func() {
+		idEntry := widget.NewEntry()
+		idEntry.SetPlaceHolder("key id, fingerprint or email")
+		d := dialog.NewForm("Add Recipient to "+current.Folder, "Add", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Key", idEntry)},
+			func(ok bool) {
+				if ok {
+					folder := current.Folder
+					change(func(passphrase string) (int, error) {
+						return service.AddRecipient(folder, idEntry.Text, passphrase)
+					}, "Added %s", idEntry.Text)
+				}
+			}, a.Window)
+		d.Resize(fyne.NewSize(500, 150))
+		d.Show()
+	})
+	removeBtn := widget.NewButton("Remove", func() {
+		if selected < 0 || selected >= len(current.Recipients) {
+			dialog.ShowInformation("Recipients", "Select a recipient first.", a.Window)
+			return
+		}
+		id := current.Recipients[selected].ID
+		folder := current.Folder
+		dialog.ShowConfirm("Remove Recipient",
+			fmt.Sprintf("Remove %s from %s? It can still read the older versions in the git history.", id, folder),
+			func(ok bool) {
+				if ok {
+					change(func(passphrase string) (int, error) {
+						return service.RemoveRecipient(folder, id, passphrase)
+					}, "Removed %s", id)
+				}
+			}, a.Window)
+	})
+	signBtn := widget.NewButton("Sign", func() {
+		folder := current.Folder
+		dialog.ShowConfirm("Sign Recipients",
+			fmt.Sprintf("Sign the recipients of %s? Check the keys first, everyone listed will be able to read the entries encrypted from now on.", folder),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				withPassphrase(func(passphrase string) {
+					if err := service.SignRecipients(folder, passphrase); err != nil {
+						ui.ShowErrorDialog(a.Window, err)
+						return
+					}
+					load()
+					status.SetText("Signed the recipients of " + folder)
+				})
+			}, a.Window)
+	})
+	backBtn := widget.NewButton("Back", func() {
+		a.ShowScreen("Main")
+	})
+
+	load()
+	btnRow := container.NewHBox(backBtn, folderSelect, addBtn, removeBtn, signBtn, importFileBtn, importClipboardBtn)
+	return container.NewBorder(btnRow, container.NewVBox(warnings, status), nil, nil, table)
+}
+
+func recipientCell(r service.Recipient, col int) string {
+	if r.Missing && col > 0 {
+		if col == 1 {
+			return "no public key"
+		}
+		return ""
+	}
+	switch col {
+	case 0:
+		return r.ID
+	case 1:
+		return r.Key.Fingerprint
+	case 2:
+		return strings.Join(r.Key.UIDs, ", ")
+	case 3:
+		if r.Key.Expires.IsZero() {
+			return "never"
+		}
+		return r.Key.Expires.Format(time.DateOnly)
+	case 4:
+		return r.Key.Trust()
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/git"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+// remotesUI manages the git remotes of a store: adding, changing and
+// removing them, picking the one synced with and publishing the store.
+func remotesUI(a *ui.App) fyne.CanvasObject {
+	var remotes []git.Remote
+	selected := -1
+	store := config.RootStoreName
+	status := widget.NewLabel("")
+
+	list := widget.NewList(
+		func() int {
+			return len(remotes)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			r := remotes[id]
+			text := fmt.Sprintf("%s  %s", r.Name, r.URL)
+			if r.Sync {
+				text += "  (sync)"
+			}
+			o.(*widget.Label).SetText(text)
+		},
+	)
+
+	load := func() {
+		var err error
+		remotes, err = service.Remotes(store)
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		switch {
+		case err != nil:
+			status.SetText(err.Error())
+		case len(remotes) == 0:
+			status.SetText("The store is local, add a remote to sync it")
+		default:
+			status.SetText(fmt.Sprintf("%d remotes", len(remotes)))
+		}
+	}
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+	}
+
+	var names []string
+	for _, m := range config.Mounts() {
+		names = append(names, m.Name)
+	}
+	storeSelect := widget.NewSelect(names, func(name string) {
+		store = name
+		load()
+	})
+	storeSelect.SetSelected(store)
+
+	// current returns the selected remote, after telling to select one
+	current := func() (git.Remote, bool) {
+		if selected < 0 || selected >= len(remotes) {
+			dialog.ShowInformation("Remotes", "Select a remote first.", a.Window)
+			return git.Remote{}, false
+		}
+		return remotes[selected], true
+	}
+	done := func(err error, format string, args ...any) {
+		if err != nil {
+			ui.ShowErrorDialog(a.Window, err)
+			return
+		}
+		load()
+		status.SetText(fmt.Sprintf(format, args...))
+	}
+
+	addBtn := widget.NewButton("Add", func() {
+		nameEntry := widget.NewEntry()
+		if len(remotes) == 0 {
+			nameEntry.SetText("origin")
+		}
+		urlEntry := widget.NewEntry()
+		urlEntry.SetPlaceHolder("git@host:path/to/repo.git")
+		d := dialog.NewForm("Add Remote", "Add", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Name", nameEntry), widget.NewFormItem("URL", urlEntry)},
+			func(ok bool) {
+				if ok {
+					done(service.AddRemote(store, nameEntry.Text, urlEntry.Text), "Added %s, push to publish the store there", nameEntry.Text)
+				}
+			}, a.Window)
+		d.Resize(fyne.NewSize(500, 200))
+		d.Show()
+	})
+	editBtn := widget.NewButton("Change URL", func() {
+		r, ok := current()
+		if !ok {
+			return
+		}
+		urlEntry := widget.NewEntry()
+		urlEntry.SetText(r.URL)
+		d := dialog.NewForm("Change "+r.Name, "Save", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("URL", urlEntry)},
+			func(ok bool) {
+				if ok {
+					done(service.SetRemoteURL(store, r.Name, urlEntry.Text), "Changed the URL of %s", r.Name)
+				}
+			}, a.Window)
+		d.Resize(fyne.NewSize(500, 150))
+		d.Show()
+	})
+	removeBtn := widget.NewButton("Remove", func() {
+		r, ok := current()
+		if !ok {
+			return
+		}
+		dialog.ShowConfirm("Remove Remote", fmt.Sprintf("Remove the remote %s? Nothing is deleted there.", r.Name), func(ok bool) {
+			if ok {
+				done(service.RemoveRemote(store, r.Name), "Removed %s", r.Name)
+			}
+		}, a.Window)
+	})
+	syncBtn := widget.NewButton("Sync With", func() {
+		if r, ok := current(); ok {
+			done(service.SetSyncRemote(store, r.Name), "Syncing with %s", r.Name)
+		}
+	})
+	pushBtn := widget.NewButton("Push", func() {
+		r, ok := current()
+		if !ok {
+			return
+		}
+		status.SetText(fmt.Sprintf("Pushing to %s...", r.Name))
+		go func() {
+			err := service.PushToRemote(store, r.Name)
+			fyne.Do(func() {
+				done(err, "Pushed the store to %s", r.Name)
+			})
+		}()
+	})
+	backBtn := widget.NewButton("Back", func() {
+		a.ShowScreen("Main")
+	})
+
+	btnRow := container.NewHBox(backBtn, storeSelect, addBtn, editBtn, removeBtn, syncBtn, pushBtn)
+	return container.NewBorder(btnRow, status, nil, nil, list)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+// variantTheme forces the light or dark variant of the default theme,
+// whatever the system uses.
+type variantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+func (t variantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, t.variant)
+}
+
+// applyTheme applies the theme of the configuration.
+func applyTheme(a fyne.App) {
+	switch config.Theme() {
+	case config.ThemeLight:
+		a.Settings().SetTheme(variantTheme{theme.DefaultTheme(), theme.VariantLight})
+	case config.ThemeDark:
+		a.Settings().SetTheme(variantTheme{theme.DefaultTheme(), theme.VariantDark})
+	default:
+		a.Settings().SetTheme(theme.DefaultTheme())
+	}
+}
+
+// reloadConfig reads the configuration file again and rebuilds the main
+// screen, so mounted stores and the theme are up to date.
+func reloadConfig(a *ui.App) {
+	if err := config.Reload(); err != nil {
+		ui.ShowErrorDialog(a.Window, fmt.Errorf("configuration not reloaded: %w", err))
+		return
+	}
+	if err := audit.Setup("ui"); err != nil {
+		slog.Error("Failed to open audit log", slog.Any("error", err))
+	}
+	applyTheme(fyne.CurrentApp())
+	a.ShowScreen("Main")
+}
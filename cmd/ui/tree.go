@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/ui"
+)
+
+// Tree node ids are entry names, folders end with a slash as an entry may
+// have the name of a folder. The root is "".
+func isFolderUID(uid widget.TreeNodeID) bool {
+	return uid == "" || strings.HasSuffix(uid, "/")
+}
+
+func folderOfUID(uid widget.TreeNodeID) string {
+	return strings.TrimSuffix(uid, "/")
+}
+
+// newEntriesTree shows the entries of root in collapsible folders with their
+// entry counts. The tree keeps the open folders across refreshes.
+func newEntriesTree(root func() *service.TreeNode) *widget.Tree {
+	find := func(uid widget.TreeNodeID) *service.TreeNode {
+		if isFolderUID(uid) {
+			return root().Folder(folderOfUID(uid))
+		}
+		folder := root().Folder(path.Dir(uid))
+		if path.Dir(uid) == "." {
+			folder = root()
+		}
+		if folder == nil {
+			return nil
+		}
+		for _, child := range folder.Children {
+			if child.Entry && child.Path == uid {
+				return child
+			}
+		}
+		return nil
+	}
+
+	return widget.NewTree(
+		func(uid widget.TreeNodeID) []widget.TreeNodeID {
+			node := find(uid)
+			if node == nil || node.Entry {
+				return nil
+			}
+			ids := make([]widget.TreeNodeID, 0, len(node.Children))
+			for _, child := range node.Children {
+				if child.Entry {
+					ids = append(ids, child.Path)
+				} else {
+					ids = append(ids, child.Path+"/")
+				}
+			}
+			return ids
+		},
+		isFolderUID,
+		func(bool) fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(uid widget.TreeNodeID, branch bool, o fyne.CanvasObject) {
+			node := find(uid)
+			if node == nil {
+				return
+			}
+			text := node.Name
+			if branch {
+				text = fmt.Sprintf("%s/ (%d)", node.Name, node.Count)
+			}
+			o.(*widget.Label).SetText(text)
+		},
+	)
+}
+
+// showMoveDialog asks for the new name of an entry or a folder.
+func showMoveDialog(a *ui.App, name string, folder bool, onMoved func(to string)) {
+	target := widget.NewEntry()
+	target.SetText(name)
+	title, move := "Move Entry", service.MoveEntry
+	if folder {
+		title, move = "Move Folder", service.MoveFolder
+	}
+	d := dialog.NewForm(title, "Move", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("New name", target)},
+		func(ok bool) {
+			to := strings.TrimSpace(target.Text)
+			if !ok || to == name {
+				return
+			}
+			if err := move(name, to); err != nil {
+				ui.ShowErrorDialog(a.Window, err)
+				return
+			}
+			onMoved(to)
+		}, a.Window)
+	d.Resize(fyne.NewSize(400, 150))
+	d.Show()
+}
+
+// reencrypt encrypts an entry or a folder again for the keys of its store,
+// asking for the passphrase when none is cached.
+func reencrypt(a *ui.App, name string, onDone func(n int)) {
+	run := func(passphrase string) {
+		go func() {
+			n, err := service.ReencryptFolder(name, passphrase)
+			fyne.Do(func() {
+				if err != nil {
+					ui.ShowErrorDialog(a.Window, fmt.Errorf("re-encrypted %d entries, then failed: %w", n, err))
+					return
+				}
+				onDone(n)
+			})
+		}()
+	}
+
+	if pass, valid := service.GetCachedPassphrase(); valid {
+		run(pass)
+		return
+	}
+	passEntry := widget.NewPasswordEntry()
+	d := dialog.NewForm("Enter GPG Passphrase", "OK", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Passphrase", passEntry)},
+		func(ok bool) {
+			if ok {
+				run(passEntry.Text)
+			}
+		}, a.Window)
+	d.Resize(fyne.NewSize(400, 200))
+	d.Show()
+}
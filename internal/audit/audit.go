@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/user"
+	"sync"
+	"time"
+)
+
+type Operation string
+
+const (
+	OpDecrypt   Operation = "decrypt"
+	OpCopy      Operation = "copy"
+	OpAdd       Operation = "add"
+	OpEdit      Operation = "edit"
+	OpDelete    Operation = "delete"
+	OpMove      Operation = "move"
+	OpSync      Operation = "sync"
+	OpReinit    Operation = "reinit"
+	OpClone     Operation = "clone"
+	OpRemote    Operation = "remote"
+	OpRecipient Operation = "recipient"
+	OpLock      Operation = "lock"
+	OpExport    Operation = "export"
+	OpRestore   Operation = "restore"
+	OpFsck      Operation = "fsck"
+	OpReencrypt Operation = "reencrypt"
+	OpHTTP      Operation = "http"
+)
+
+const (
+	ResultOK = "ok"
+)
+
+// Record is a single line of the audit log. It never contains secret values,
+// only the name of the entry that was touched. ClaimedActor is who the client
+// says it acts for, e.g. the X-Gopass-Actor header of a server request;
+// unlike Actor nothing vouches for it.
+type Record struct {
+	Time         time.Time `json:"time"`
+	Actor        string    `json:"actor"`
+	ClaimedActor string    `json:"claimed_actor,omitempty"`
+	Token        string    `json:"token,omitempty"`
+	Client       string    `json:"client"`
+	Entry        string    `json:"entry,omitempty"`
+	Operation    Operation `json:"operation"`
+	Result       string    `json:"result"`
+	PrevHash     string    `json:"prev_hash"`
+	Hash         string    `json:"hash"`
+}
+
+// computeHash returns the chain hash of the record, which covers the previous
+// hash and every field except Hash itself.
+func computeHash(r Record) (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(r.PrevHash+"\n"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sink is where audit lines are written to.
+type Sink interface {
+	// LastHash returns the hash of the last record written to the sink, or an
+	// empty string if the sink is empty.
+	LastHash() (string, error)
+	Write(line []byte) error
+	Close() error
+}
+
+// lockingSink is a sink shared with other processes, Logger.Write holds its
+// lock from reading the last hash until the line is written.
+type lockingSink interface {
+	Lock() error
+	Unlock() error
+}
+
+type Logger struct {
+	mu     sync.Mutex
+	sink   Sink
+	client string
+	actor  string
+}
+
+func NewLogger(sink Sink, client string) *Logger {
+	actor := "unknown"
+	if usr, err := user.Current(); err == nil {
+		actor = usr.Username
+	}
+
+	return &Logger{sink: sink, client: client, actor: actor}
+}
+
+// Write chains and appends the record. Empty Time, Actor and Client are
+// filled with the logger defaults.
+func (l *Logger) Write(r Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+	if r.Actor == "" {
+		r.Actor = l.actor
+	}
+	if r.Client == "" {
+		r.Client = l.client
+	}
+
+	if ls, ok := l.sink.(lockingSink); ok {
+		if err := ls.Lock(); err != nil {
+			return err
+		}
+		defer ls.Unlock()
+	}
+
+	prev, err := l.sink.LastHash()
+	if err != nil {
+		return fmt.Errorf("failed to read last audit hash: %w", err)
+	}
+	r.PrevHash = prev
+	r.Hash, err = computeHash(r)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return l.sink.Write(line)
+}
+
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sink.Close()
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger *Logger
+)
+
+// SetDefault sets the logger used by Log, closing the previous one. A nil
+// logger disables auditing.
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	prev := defaultLogger
+	defaultLogger = l
+	defaultMu.Unlock()
+
+	if prev != nil && prev != l {
+		prev.Close()
+	}
+}
+
+// Write appends the record to the default logger, if any.
+func Write(r Record) error {
+	defaultMu.RLock()
+	l := defaultLogger
+	defaultMu.RUnlock()
+
+	if l == nil {
+		return nil
+	}
+
+	return l.Write(r)
+}
+
+// Log records the outcome of an operation on an entry with the default logger.
+func Log(op Operation, entry string, opErr error) {
+	result := ResultOK
+	if opErr != nil {
+		result = "error: " + opErr.Error()
+	}
+
+	if err := Write(Record{Operation: op, Entry: entry, Result: result}); err != nil {
+		// Auditing must not break the operation itself
+		slog.Error("Failed to write audit record", slog.Any("error", err))
+	}
+}
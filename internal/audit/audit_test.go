@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestLog(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	l := NewLogger(sink, "test")
+	for _, op := range []Operation{OpAdd, OpDecrypt, OpDelete} {
+		if err := l.Write(Record{ClaimedActor: "alice", Operation: op, Entry: "work/github", Result: ResultOK}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	return path
+}
+
+func TestVerifyIntactLog(t *testing.T) {
+	path := writeTestLog(t)
+
+	count, err := VerifyFile(path)
+	if err != nil {
+		t.Fatalf("VerifyFile failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 records, got %d", count)
+	}
+
+	records, err := ReadRecords(path)
+	if err != nil {
+		t.Fatalf("ReadRecords failed: %v", err)
+	}
+	if records[1].PrevHash != records[0].Hash {
+		t.Errorf("records are not chained")
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	cases := map[string]func(lines []string) []string{
+		"modified": func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"decrypt"`, `"add"`, 1)
+			return lines
+		},
+		"claimed actor": func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"alice"`, `"bob"`, 1)
+			return lines
+		},
+		"removed": func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		},
+		"reordered": func(lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		},
+	}
+
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := writeTestLog(t)
+			data, _ := os.ReadFile(path)
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			lines = tamper(lines)
+			os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+
+			_, err := VerifyFile(path)
+			var tamperErr *TamperError
+			if !errors.As(err, &tamperErr) {
+				t.Fatalf("expected TamperError, got %v", err)
+			}
+			if tamperErr.Line != 2 {
+				t.Errorf("expected tampering at line 2, got %d", tamperErr.Line)
+			}
+		})
+	}
+}
+
+// TestFileSinkLockBlocksWriters holds the lock of one sink, like another
+// process between LastHash and Write, while a second logger writes.
+func TestFileSinkLockBlocksWriters(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("the audit log is not locked on " + runtime.GOOS)
+	}
+	path := writeTestLog(t)
+	held, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	if err := held.(lockingSink).Lock(); err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- NewLogger(sink, "server").Write(Record{Operation: OpDecrypt, Entry: "work/github", Result: ResultOK})
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Write did not wait for the lock: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := held.(lockingSink).Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	count, err := VerifyFile(path)
+	if err != nil {
+		t.Fatalf("VerifyFile failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("expected 4 records, got %d", count)
+	}
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type fileSink struct {
+	path string
+	// locked is the log file while Logger.Write holds its lock
+	locked *os.File
+}
+
+// NewFileSink returns a sink appending JSON lines to the file at path.
+func NewFileSink(path string) (Sink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return &fileSink{path: path}, f.Close()
+}
+
+// Lock takes an exclusive lock on the log file, so no other process appends
+// between LastHash and Write.
+func (s *fileSink) Lock() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	s.locked = f
+	return nil
+}
+
+// Unlock releases the lock of Lock.
+func (s *fileSink) Unlock() error {
+	f := s.locked
+	s.locked = nil
+	if f == nil {
+		return nil
+	}
+	// Closing the file releases the lock
+	return f.Close()
+}
+
+// LastHash reads the hash from the last line on every call, so several
+// processes (e.g. the desktop UI and the server) can share one log file.
+// Logger.Write holds the file lock from here until the line is appended.
+func (s *fileSink) LastHash() (string, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	line, err := lastLine(f)
+	if err != nil || len(line) == 0 {
+		return "", err
+	}
+
+	var r Record
+	if err := json.Unmarshal(line, &r); err != nil {
+		return "", fmt.Errorf("malformed last audit record: %w", err)
+	}
+
+	return r.Hash, nil
+}
+
+func (s *fileSink) Write(line []byte) error {
+	if s.locked != nil {
+		_, err := s.locked.Write(append(line, '\n'))
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return nil
+}
+
+// lastLine returns the last non-empty line of f, reading backwards in chunks.
+func lastLine(f *os.File) ([]byte, error) {
+	const chunkSize = 4096
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	offset := info.Size()
+	for offset > 0 {
+		n := int64(chunkSize)
+		if offset < n {
+			n = offset
+		}
+		offset -= n
+
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		buf = append(chunk, buf...)
+
+		trimmed := bytes.TrimRight(buf, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+	}
+
+	return bytes.TrimRight(buf, "\n"), nil
+}
@@ -0,0 +1,11 @@
+//go:build windows || plan9 || js || wasip1
+
+package audit
+
+import "os"
+
+// lockFile is a no-op where flock is missing, processes sharing a log file
+// on these platforms can break its chain.
+func lockFile(f *os.File) error {
+	return nil
+}
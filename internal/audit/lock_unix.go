@@ -0,0 +1,13 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package audit
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until it holds an exclusive flock on f.
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
@@ -0,0 +1,21 @@
+package audit
+
+import "github.com/duykhoa/gopass/internal/config"
+
+// Setup opens the audit sink from the configuration and makes it the default
+// logger for the given client (ui, tui, server, cli).
+func Setup(client string) error {
+	target := config.AuditLog()
+	if target == "" {
+		SetDefault(nil)
+		return nil
+	}
+
+	l, err := Open(target, client)
+	if err != nil {
+		return err
+	}
+	SetDefault(l)
+
+	return nil
+}
@@ -0,0 +1,261 @@
+// Package backup exports the password store for disaster recovery and
+// restores it. Bundles are tar archives of the decrypted entries as JSON,
+// encrypted as a whole with a passphrase so they can be restored without the
+// store's GPG key.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/gpg"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+const (
+	FormatBundle = "bundle"
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+)
+
+const (
+	bundleVersion = 1
+	manifestName  = "manifest.json"
+	entriesDir    = "entries"
+)
+
+// ErrPlaintextNotConfirmed is returned for CSV and JSON exports, which write
+// every secret in clear text, unless the caller confirmed it.
+var ErrPlaintextNotConfirmed = errors.New("plaintext export writes every secret unencrypted, it must be confirmed explicitly")
+
+// Entry is a decrypted entry. Content is the exact decrypted file, the
+// template, fields and metadata are parsed from it for readability.
+type Entry struct {
+	Name     string            `json:"name"`
+	Template string            `json:"template"`
+	Fields   []service.Field   `json:"fields"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Content  string            `json:"content"`
+}
+
+type ManifestEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+// Manifest lists the entries of a bundle with their checksums, restore
+// refuses bundles whose files do not match it.
+type Manifest struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Subtree   string          `json:"subtree,omitempty"`
+	Entries   []ManifestEntry `json:"entries"`
+}
+
+func NewEntry(name, content string) Entry {
+	parsed := service.ParseEntry(content)
+	return Entry{
+		Name:     filepath.ToSlash(name),
+		Template: parsed.Template,
+		Fields:   parsed.Fields,
+		Meta:     parsed.Meta,
+		Content:  content,
+	}
+}
+
+type ExportRequest struct {
+	Format string
+	// Subtree limits the export to a folder, the whole store when empty
+	Subtree string
+	// Passphrase unlocks the GPG key to decrypt the entries
+	Passphrase string
+	// BundlePassphrase encrypts the bundle
+	BundlePassphrase string
+	// ConfirmPlaintext must be set for the CSV and JSON formats
+	ConfirmPlaintext bool
+}
+
+// Export decrypts the entries and writes them to w in the requested format.
+// It returns the number of exported entries.
+func Export(w io.Writer, req ExportRequest) (int, error) {
+	if req.Format == "" {
+		req.Format = FormatBundle
+	}
+	switch req.Format {
+	case FormatBundle:
+		if req.BundlePassphrase == "" {
+			return 0, fmt.Errorf("a bundle passphrase is required")
+		}
+	case FormatCSV, FormatJSON:
+		if !req.ConfirmPlaintext {
+			return 0, ErrPlaintextNotConfirmed
+		}
+	default:
+		return 0, fmt.Errorf("unknown export format: %s", req.Format)
+	}
+
+	entries, err := decryptEntries(req.Subtree, req.Passphrase)
+	if err == nil {
+		switch req.Format {
+		case FormatBundle:
+			err = writeBundle(w, entries, req.Subtree, req.BundlePassphrase)
+		case FormatCSV:
+			err = writeCSV(w, entries)
+		case FormatJSON:
+			err = writeJSON(w, entries)
+		}
+	}
+	audit.Log(audit.OpExport, req.Subtree, err)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
+
+func inSubtree(entry, subtree string) bool {
+	subtree = strings.Trim(filepath.ToSlash(subtree), "/")
+	return subtree == "" || entry == subtree || strings.HasPrefix(entry, subtree+"/")
+}
+
+func decryptEntries(subtree, passphrase string) ([]Entry, error) {
+	names, err := service.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, name := range names {
+		if !inSubtree(filepath.ToSlash(name), subtree) {
+			continue
+		}
+		result := service.Decrypt(service.DecryptRequest{Entry: name, Passphrase: passphrase})
+		if result.Err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", name, result.Err)
+		}
+		entries = append(entries, NewEntry(name, result.Plaintext))
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no entries to export")
+	}
+
+	return entries, nil
+}
+
+// writeBundle writes a gzipped tar of the manifest and one JSON file per
+// entry, encrypted with the passphrase.
+func writeBundle(w io.Writer, entries []Entry, subtree, passphrase string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	now := time.Now().UTC()
+
+	manifest := Manifest{Version: bundleVersion, CreatedAt: now, Subtree: subtree}
+	files := map[string][]byte{}
+	for _, entry := range entries {
+		data, err := json.MarshalIndent(entry, "", "  ")
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		manifest.Entries = append(manifest.Entries, ManifestEntry{
+			Name:   entry.Name,
+			SHA256: hex.EncodeToString(sum[:]),
+			Size:   len(data),
+		})
+		files[entry.Name] = data
+	}
+
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add(manifestName, data); err != nil {
+		return err
+	}
+	for _, me := range manifest.Entries {
+		if err := add(path.Join(entriesDir, me.Name+".json"), files[me.Name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	ciphertext, err := gpg.EncryptWithPassphrase(buf.Bytes(), passphrase)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(ciphertext)
+	return err
+}
+
+func writeJSON(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// writeCSV writes one row per entry with the columns the importer reads, so
+// a CSV export can be imported again.
+func writeCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"folder", "name", "url", "username", "password", "notes", "tags", "favorite"}); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		parsed := service.ParseEntry(entry.Content)
+		folder, name := path.Split(entry.Name)
+		notes := parsed.Get("extra")
+		if parsed.Template == service.TemplateFreeForm {
+			notes = parsed.Get("content")
+		}
+		favorite := ""
+		if parsed.EntryMeta().Favorite {
+			favorite = "true"
+		}
+		row := []string{
+			strings.TrimSuffix(folder, "/"),
+			name,
+			parsed.Get("domain"),
+			parsed.Get("email"),
+			parsed.Get("password"),
+			notes,
+			strings.Join(parsed.EntryMeta().Tags, ","),
+			favorite,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/duykhoa/gopass/internal/importer"
+)
+
+func testEntries() []Entry {
+	return []Entry{
+		NewEntry("work/github", "domain: github.com\nemail: me@example.com\npassword: hunter2\nextra: 2FA\n---\ntemplate: Email and password\ntags: work\nfavorite: true\n"),
+		NewEntry("wifi", "s3cret\n"),
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeBundle(&buf, testEntries(), "", "bundle pass"); err != nil {
+		t.Fatalf("writeBundle failed: %v", err)
+	}
+
+	manifest, entries, err := ReadBundle(buf.Bytes(), "bundle pass")
+	if err != nil {
+		t.Fatalf("ReadBundle failed: %v", err)
+	}
+	if len(manifest.Entries) != 2 || len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d in manifest and %d read", len(manifest.Entries), len(entries))
+	}
+	for i, want := range testEntries() {
+		if entries[i].Name != want.Name || entries[i].Content != want.Content {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want, entries[i])
+		}
+	}
+
+	if _, _, err := ReadBundle(buf.Bytes(), "wrong"); err == nil {
+		t.Error("expected an error for a wrong passphrase")
+	}
+	tampered := bytes.Clone(buf.Bytes())
+	tampered[len(tampered)/2] ^= 0xff
+	if _, _, err := ReadBundle(tampered, "bundle pass"); err == nil {
+		t.Error("expected an error for a tampered bundle")
+	}
+}
+
+func TestExportPlaintextNeedsConfirmation(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSON} {
+		_, err := Export(&bytes.Buffer{}, ExportRequest{Format: format})
+		if !errors.Is(err, ErrPlaintextNotConfirmed) {
+			t.Errorf("%s: expected ErrPlaintextNotConfirmed, got %v", format, err)
+		}
+	}
+}
+
+func TestCSVExportCanBeImported(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, testEntries()); err != nil {
+		t.Fatalf("writeCSV failed: %v", err)
+	}
+
+	records, err := importer.Parse("chrome", buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	r := records[0]
+	if r.Folder != "work" || r.Name != "github" || r.Password != "hunter2" || r.Notes != "2FA" || !r.Favorite || len(r.Tags) != 1 {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if records[1].Notes != "s3cret" {
+		t.Errorf("unexpected record %+v", records[1])
+	}
+}
+
+func TestValidEntryName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"work/github":   true,
+		"../etc/passwd": false,
+		"/etc/passwd":   false,
+		".git/config":   false,
+		"a//b":          false,
+	} {
+		if got := validEntryName(name); got != want {
+			t.Errorf("validEntryName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/gpg"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+// maxBundleFileSize bounds the size of a single file read from a bundle.
+const maxBundleFileSize = 16 << 20
+
+type RestoreRequest struct {
+	Data             []byte
+	BundlePassphrase string
+	// Overwrite replaces existing entries, they are skipped otherwise
+	Overwrite bool
+	// VerifyOnly checks the bundle without writing anything
+	VerifyOnly bool
+}
+
+type RestoreResult struct {
+	Manifest Manifest
+	Restored int
+	Skipped  []string
+	Err      error
+}
+
+// Restore decrypts and verifies a bundle, then writes its entries to the
+// store in a single git commit.
+func Restore(req RestoreRequest) RestoreResult {
+	manifest, entries, err := ReadBundle(req.Data, req.BundlePassphrase)
+	if err != nil || req.VerifyOnly {
+		return RestoreResult{Manifest: manifest, Err: err}
+	}
+
+	result := RestoreResult{Manifest: manifest}
+	for _, entry := range entries {
+		if _, err := os.Stat(service.EntryPath(entry.Name)); err == nil && !req.Overwrite {
+			result.Skipped = append(result.Skipped, entry.Name)
+			continue
+		}
+		if err := service.SaveEntry(filepath.FromSlash(entry.Name), []byte(entry.Content)); err != nil {
+			result.Err = fmt.Errorf("failed to restore %s: %w", entry.Name, err)
+			break
+		}
+		result.Restored++
+	}
+
+	if result.Restored > 0 {
+		message := fmt.Sprintf("Restore %d entries from backup of %s", result.Restored, manifest.CreatedAt.Format("2006-01-02 15:04"))
+		if err := service.CommitStores(message); err != nil && result.Err == nil {
+			result.Err = fmt.Errorf("entries were restored but not committed: %w", err)
+		}
+	}
+	audit.Log(audit.OpRestore, manifest.Subtree, result.Err)
+
+	return result
+}
+
+// ReadBundle decrypts a bundle and checks every entry against the manifest.
+// It fails on a wrong passphrase, a modified bundle, or entries missing from
+// or not listed in the manifest.
+func ReadBundle(data []byte, passphrase string) (Manifest, []Entry, error) {
+	var manifest Manifest
+	plaintext, err := gpg.DecryptWithPassphrase(data, passphrase)
+	if err != nil {
+		return manifest, nil, err
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(plaintext))
+	if err != nil {
+		return manifest, nil, fmt.Errorf("malformed bundle: %w", err)
+	}
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return manifest, nil, fmt.Errorf("malformed bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxBundleFileSize))
+		if err != nil {
+			return manifest, nil, fmt.Errorf("malformed bundle: %w", err)
+		}
+		files[hdr.Name] = content
+	}
+
+	data, ok := files[manifestName]
+	if !ok {
+		return manifest, nil, fmt.Errorf("malformed bundle: missing %s", manifestName)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, nil, fmt.Errorf("malformed manifest: %w", err)
+	}
+	if manifest.Version != bundleVersion {
+		return manifest, nil, fmt.Errorf("unsupported bundle version %d", manifest.Version)
+	}
+	delete(files, manifestName)
+
+	entries := make([]Entry, 0, len(manifest.Entries))
+	for _, me := range manifest.Entries {
+		if !validEntryName(me.Name) {
+			return manifest, nil, fmt.Errorf("invalid entry name in manifest: %q", me.Name)
+		}
+		name := path.Join(entriesDir, me.Name+".json")
+		content, ok := files[name]
+		if !ok {
+			return manifest, nil, fmt.Errorf("integrity check failed: %s is missing", me.Name)
+		}
+		delete(files, name)
+
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != me.SHA256 || len(content) != me.Size {
+			return manifest, nil, fmt.Errorf("integrity check failed: %s does not match the manifest", me.Name)
+		}
+		var entry Entry
+		if err := json.Unmarshal(content, &entry); err != nil {
+			return manifest, nil, fmt.Errorf("malformed entry %s: %w", me.Name, err)
+		}
+		if entry.Name != me.Name {
+			return manifest, nil, fmt.Errorf("integrity check failed: %s is named %s", me.Name, entry.Name)
+		}
+		entries = append(entries, entry)
+	}
+	if len(files) > 0 {
+		return manifest, nil, fmt.Errorf("integrity check failed: %d files are not in the manifest", len(files))
+	}
+
+	return manifest, entries, nil
+}
+
+// validEntryName rejects names escaping the store directory.
+func validEntryName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	return true
+}
@@ -1,52 +1,246 @@
 package config
 
 import (
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 )
 
+// state is the loaded configuration and what is derived from it. It is
+// replaced as a whole by Reload, getters never see half of a reload.
+type state struct {
+	cfg          *Config
+	storeDirName string
+	gpgId        string
+	mounts       []Mount
+	// signingErr is why the signing keys are unknown, see SigningKeysErr
+	signingErr error
+}
+
 var (
-	passwordStoreDir     string
-	passwordStoreDirName string
-	gpgId                string
-	passphraseKey        []byte
-	initOnce             sync.Once
+	mu      sync.RWMutex
+	current *state
+	loadErr error
 )
 
+// get returns the current state, loading the configuration on first use. An
+// invalid configuration file falls back to the defaults and the environment
+// with the signing keys of the file, the error is kept for Err.
+func get() *state {
+	mu.RLock()
+	s := current
+	mu.RUnlock()
+	if s != nil {
+		return s
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		cfg, err := Load()
+		var signingErr error
+		if err != nil {
+			loadErr = err
+			cfg = Default()
+			applyEnv(cfg)
+			if validate(cfg) != nil {
+				// The environment is broken too
+				cfg = Default()
+			}
+			// Without its signing keys any .gpg-id would be encrypted for
+			cfg.Store.SigningKeys, signingErr = fallbackSigningKeys(ConfigFile())
+		}
+		current = newState(cfg)
+		current.signingErr = signingErr
+	}
+	return current
+}
+
+func newState(cfg *Config) *state {
+	s := &state{cfg: cfg, storeDirName: filepath.Base(cfg.Store.Path)}
+	s.gpgId = cfg.Store.Key
+	if s.gpgId == "" {
+		s.gpgId = readGPGId(cfg.Store.Path)
+	}
+	s.mounts = []Mount{{Name: RootStoreName, Path: cfg.Store.Path, GPGId: s.gpgId}}
+	for _, mc := range cfg.Mounts {
+		name := mc.Name
+		if name == "" {
+			name = mc.Prefix
+		}
+		s.mounts = append(s.mounts, Mount{Name: name, Prefix: mc.Prefix, Path: mc.Path, GPGId: readGPGId(mc.Path)})
+	}
+	return s
+}
+
+// Reload reads the configuration file and the environment again. When the
+// new configuration is invalid the current one is kept and the validation
+// errors are returned.
+func Reload() error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	s := newState(cfg)
+
+	mu.Lock()
+	current = s
+	loadErr = nil
+	mu.Unlock()
+
+	return nil
+}
+
+// Err returns why the configuration file could not be loaded at startup, the
+// defaults are in use then.
+func Err() error {
+	get()
+	mu.RLock()
+	defer mu.RUnlock()
+	return loadErr
+}
+
+// Current returns a copy of the configuration in use.
+func Current() Config {
+	cfg := *get().cfg
+	cfg.Mounts = append([]MountConfig(nil), cfg.Mounts...)
+	cfg.Server.Listen = append([]string(nil), cfg.Server.Listen...)
+	cfg.TUI.Keys = TUIKeys()
+	return cfg
+}
+
 func PasswordStoreDir() string {
-	initOnce.Do(loadConfig)
-	return passwordStoreDir
+	return get().cfg.Store.Path
+}
+
+// SetStorePath makes path the root store, e.g. after the first-run wizard
+// created it elsewhere. Without a configuration file one is written, an
+// existing file is not rewritten and store.path has to be changed there.
+func SetStorePath(path string) error {
+	path = expandHome(path)
+	if filepath.Clean(path) == filepath.Clean(PasswordStoreDir()) {
+		return nil
+	}
+	if os.Getenv("PASSWORD_STORE_DIR") != "" {
+		return fmt.Errorf("PASSWORD_STORE_DIR is set, point it to %s to use the new store", path)
+	}
+	file := ConfigFile()
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("set store.path to %s in %s to use the new store", path, file)
+	}
+	cfg := Default()
+	cfg.Store.Path = path
+	if err := cfg.Write(file); err != nil {
+		return err
+	}
+	return Reload()
 }
 
 func PasswordStoreDirName() string {
-	initOnce.Do(loadConfig)
-	return passwordStoreDirName
+	return get().storeDirName
 }
 
+// GPGId is the key of the root store, from PASSWORD_STORE_KEY or its .gpg-id.
 func GPGId() string {
-	initOnce.Do(loadConfig)
-	return gpgId
+	return get().gpgId
+}
+
+// SigningKeys are the fingerprints of the keys trusted to sign .gpg-id files,
+// empty when .gpg-id files are not signed.
+func SigningKeys() []string {
+	return append([]string(nil), get().cfg.Store.SigningKeys...)
 }
 
-func PassphraseKey() []byte {
-	initOnce.Do(loadConfig)
-	return passphraseKey
+// SigningKeysErr tells why the signing keys are unknown: the configuration
+// file could not be loaded and may set keys that cannot be read from it.
+// Nothing must be encrypted for a .gpg-id then.
+func SigningKeysErr() error {
+	return get().signingErr
 }
 
-func loadConfig() {
-	home, _ := os.UserHomeDir()
-	passwordStoreDirName = ".password-store"
-	passwordStoreDir = filepath.Join(home, passwordStoreDirName)
-	gpgIdPath := filepath.Join(passwordStoreDir, ".gpg-id")
-	gpgIdBytes, err := os.ReadFile(gpgIdPath)
-	if err == nil {
-		gpgId = strings.TrimSpace(string(gpgIdBytes))
+// AuditLog returns where audit records are written: a file path, "syslog",
+// or an empty string when auditing is turned off.
+func AuditLog() string {
+	sink := get().cfg.Audit.Sink
+	if sink == AuditOff {
+		return ""
 	}
+	return sink
+}
+
+// PasswordMaxAge is how long a password may stay unchanged before the
+// password health audit reports it.
+func PasswordMaxAge() time.Duration {
+	return get().cfg.Health.PasswordMaxAge.Duration
+}
+
+// BreachFile is the offline Have I Been Pwned SHA-1 list checked by the
+// password health audit, empty when there is none.
+func BreachFile() string {
+	return get().cfg.Health.BreachFile
+}
+
+// CryptoBackend is the OpenPGP implementation entries are encrypted with.
+func CryptoBackend() string {
+	return get().cfg.Crypto.Backend
+}
+
+// CacheTTL is how long an unlocked passphrase stays cached.
+func CacheTTL() time.Duration {
+	return get().cfg.Cache.TTL.Duration
+}
+
+// ClipboardTimeout is how long a copied secret stays in the clipboard.
+func ClipboardTimeout() time.Duration {
+	return get().cfg.Clipboard.Timeout.Duration
+}
+
+// AutoLockIdle is the inactivity after which the store locks, 0 never locks.
+func AutoLockIdle() time.Duration {
+	return get().cfg.AutoLock.Idle.Duration
+}
 
-	// This is a hardcoded passphrase encryption key, it is
-	// probably a good idea to generate a random passphrase
-	// in the first time running the app
-	passphraseKey = []byte("gopass-demo-static-key-32bytes!!")
+// ServerListen returns the addresses the HTTP server listens on.
+func ServerListen() []string {
+	return append([]string(nil), get().cfg.Server.Listen...)
+}
+
+// Theme is the desktop UI theme: system, light or dark.
+func Theme() string {
+	return get().cfg.UI.Theme
+}
+
+// TUIKeymap is the key binding preset of the terminal UI.
+func TUIKeymap() string {
+	return get().cfg.TUI.Keymap
+}
+
+// TUIKeys returns the key bindings overriding the preset, by page then
+// action. The terminal UI checks the page, action and key names.
+func TUIKeys() map[string]map[string]string {
+	keys := map[string]map[string]string{}
+	for page, actions := range get().cfg.TUI.Keys {
+		keys[page] = maps.Clone(actions)
+	}
+	return keys
+}
+
+func readGPGId(storeDir string) string {
+	data, err := os.ReadFile(filepath.Join(storeDir, ".gpg-id"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[1:])
+	}
+	return path
 }
@@ -0,0 +1,246 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func clearEnv(t *testing.T) {
+	for _, name := range []string{"PASSWORD_STORE_DIR", "PASSWORD_STORE_KEY", "PASSWORD_STORE_SIGNING_KEY", "PASSWORD_STORE_CLIP_TIME",
+		"GOPASS_AUDIT_LOG", "GOPASS_PASSWORD_MAX_AGE_DAYS", "GOPASS_BREACH_FILE"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func TestLoadFileDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("a missing file should load the defaults: %v", err)
+	}
+	if cfg.Cache.TTL.Duration != 30*time.Minute || cfg.Crypto.Backend != BackendGopenPGP {
+		t.Errorf("unexpected defaults %+v", cfg)
+	}
+}
+
+func TestLoadFileAndEnvironment(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte(`
+[store]
+path = "/stores/main"
+
+[cache]
+ttl = "5m"
+
+[clipboard]
+timeout = "10s"
+
+[server]
+listen = ["127.0.0.1:9000", "[::1]:9000"]
+
+[health]
+password_max_age = "90d"
+
+[ui]
+theme = "dark"
+
+[tui]
+keymap = "vim"
+
+[tui.keys.main]
+sync = "Ctrl+S S"
+`), 0600)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Store.Path != "/stores/main" || cfg.Cache.TTL.Duration != 5*time.Minute || cfg.UI.Theme != ThemeDark {
+		t.Errorf("file settings not applied: %+v", cfg)
+	}
+	if cfg.Health.PasswordMaxAge.Duration != 90*24*time.Hour {
+		t.Errorf("expected 90 days, got %v", cfg.Health.PasswordMaxAge)
+	}
+	if len(cfg.Server.Listen) != 2 {
+		t.Errorf("expected 2 listeners, got %v", cfg.Server.Listen)
+	}
+	if cfg.TUI.Keymap != KeymapVim || cfg.TUI.Keys["main"]["sync"] != "Ctrl+S S" {
+		t.Errorf("tui settings not applied: %+v", cfg.TUI)
+	}
+
+	t.Setenv("PASSWORD_STORE_DIR", "/env/store")
+	t.Setenv("PASSWORD_STORE_KEY", "ABCDEF")
+	t.Setenv("PASSWORD_STORE_CLIP_TIME", "20")
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Store.Path != "/env/store" || cfg.Store.Key != "ABCDEF" || cfg.Clipboard.Timeout.Duration != 20*time.Second {
+		t.Errorf("environment overrides not applied: %+v", cfg)
+	}
+}
+
+func TestLoadFileValidation(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte(`
+[crypto]
+backend = "gpg2"
+
+[server]
+listen = ["8080"]
+
+[ui]
+theme = "pink"
+colour = "x"
+`), 0600)
+
+	_, err := LoadFile(path)
+	if err == nil || !strings.Contains(err.Error(), "unknown settings: ui.colour") {
+		t.Fatalf("expected unknown settings to be rejected, got %v", err)
+	}
+
+	os.WriteFile(path, []byte("[crypto]\nbackend = \"gpg2\"\n[server]\nlisten = [\"8080\"]\n[ui]\ntheme = \"pink\"\n[tui]\nkeymap = \"nano\"\n"), 0600)
+	_, err = LoadFile(path)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"crypto.backend", "server.listen", "ui.theme", "tui.keymap"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error about %s, got %v", want, err)
+		}
+	}
+
+	os.WriteFile(path, nil, 0600)
+	t.Setenv("PASSWORD_STORE_CLIP_TIME", "soon")
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), "clipboard.timeout") {
+		t.Errorf("expected a bad PASSWORD_STORE_CLIP_TIME to be reported, got %v", err)
+	}
+}
+
+func TestLoadFileSigningKeys(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte("[store]\nsigning_keys = [\"c363ef9aaf1bf3e03ae528d28699e9e85aeed7f5\"]\n"), 0600)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if want := []string{"C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5"}; !slices.Equal(cfg.Store.SigningKeys, want) {
+		t.Errorf("expected signing keys %v, got %v", want, cfg.Store.SigningKeys)
+	}
+
+	t.Setenv("PASSWORD_STORE_SIGNING_KEY", "C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5  8699E9E85AEED7F5")
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), `"8699E9E85AEED7F5" must be a full 40 character fingerprint`) {
+		t.Errorf("expected a short key id to be refused, got %v", err)
+	}
+}
+
+func TestInvalidFileKeepsSigningKeys(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv("GOPASS_CONFIG", path)
+	reset := func() {
+		mu.Lock()
+		current, loadErr = nil, nil
+		mu.Unlock()
+	}
+	t.Cleanup(reset)
+
+	fingerprint := "C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5"
+	tests := []struct {
+		name    string
+		file    string
+		env     string
+		want    []string
+		unknown bool
+	}{
+		{"invalid setting", "[store]\nsigning_keys = [\"" + strings.ToLower(fingerprint) + "\"]\n\n[cache]\nttl = \"-1m\"\n", "", []string{fingerprint}, false},
+		{"unknown setting", "[store]\nsigning_keys = [\"" + fingerprint + "\"]\nbogus = true\n", "", []string{fingerprint}, false},
+		{"environment", "[cache\n", fingerprint, []string{fingerprint}, false},
+		{"broken file without signing keys", "[cache\nttl = 1\n", "", nil, false},
+		{"broken file with signing keys", "[store]\nsigning_keys = [\"" + fingerprint + "\"\n", "", nil, true},
+		{"short signing key", "[store]\nsigning_keys = [\"8699E9E85AEED7F5\"]\nbogus = true\n", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset()
+			t.Setenv("PASSWORD_STORE_SIGNING_KEY", tt.env)
+			os.WriteFile(path, []byte(tt.file), 0600)
+
+			if Err() == nil {
+				t.Fatal("expected the configuration file to be invalid")
+			}
+			if !slices.Equal(SigningKeys(), tt.want) {
+				t.Errorf("expected signing keys %v, got %v", tt.want, SigningKeys())
+			}
+			if err := SigningKeysErr(); (err != nil) != tt.unknown {
+				t.Errorf("expected the signing keys unknown to be %t, got %v", tt.unknown, err)
+			}
+		})
+	}
+}
+
+func TestReloadKeepsConfigOnError(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	t.Setenv("GOPASS_CONFIG", path)
+	t.Cleanup(func() {
+		mu.Lock()
+		current, loadErr = nil, nil
+		mu.Unlock()
+	})
+
+	os.WriteFile(path, []byte("[cache]\nttl = \"1m\"\n"), 0600)
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if CacheTTL() != time.Minute {
+		t.Fatalf("expected 1m, got %v", CacheTTL())
+	}
+
+	os.WriteFile(path, []byte("[cache]\nttl = \"-1m\"\n"), 0600)
+	if err := Reload(); err == nil {
+		t.Fatal("expected Reload to fail")
+	}
+	if CacheTTL() != time.Minute {
+		t.Errorf("the previous configuration should be kept, got %v", CacheTTL())
+	}
+}
+
+func TestSetStorePath(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	t.Setenv("GOPASS_CONFIG", path)
+	t.Cleanup(func() {
+		mu.Lock()
+		current, loadErr = nil, nil
+		mu.Unlock()
+	})
+
+	store := filepath.Join(dir, "store")
+	if err := SetStorePath(store); err != nil {
+		t.Fatalf("SetStorePath failed: %v", err)
+	}
+	if PasswordStoreDir() != store {
+		t.Errorf("expected the store at %s, got %s", store, PasswordStoreDir())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected a configuration file: %v", err)
+	}
+
+	if err := SetStorePath(store); err != nil {
+		t.Errorf("the store in use should be accepted: %v", err)
+	}
+	if err := SetStorePath(filepath.Join(dir, "other")); err == nil || !strings.Contains(err.Error(), "store.path") {
+		t.Errorf("an existing file should not be rewritten, got %v", err)
+	}
+}
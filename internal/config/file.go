@@ -0,0 +1,445 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	BackendGopenPGP = "gopenpgp"
+
+	AuditOff    = "off"
+	AuditSyslog = "syslog"
+
+	ThemeSystem = "system"
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+
+	KeymapDefault = "default"
+	KeymapVim     = "vim"
+	KeymapEmacs   = "emacs"
+)
+
+// Config is the gopass configuration file. Durations are Go durations such as
+// "45s" or "30m", and may also be given in days, e.g. "365d".
+//
+//	[store]
+//	path = "~/.password-store"
+//	signing_keys = ["C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5"]
+//
+//	[[mounts]]
+//	prefix = "team"
+//	path = "~/.password-store-team"
+//
+//	[cache]
+//	ttl = "30m"
+//
+//	[clipboard]
+//	timeout = "45s"
+//
+//	[autolock]
+//	idle = "10m"
+//
+//	[server]
+//	listen = ["127.0.0.1:8080"]
+//
+//	[audit]
+//	sink = "syslog"
+//
+//	[tui]
+//	keymap = "vim"
+//
+//	[tui.keys.main]
+//	sync = "Ctrl+S S"
+type Config struct {
+	Store     StoreConfig     `toml:"store"`
+	Mounts    []MountConfig   `toml:"mounts"`
+	Crypto    CryptoConfig    `toml:"crypto"`
+	Cache     CacheConfig     `toml:"cache"`
+	Clipboard ClipboardConfig `toml:"clipboard"`
+	AutoLock  AutoLockConfig  `toml:"autolock"`
+	Server    ServerConfig    `toml:"server"`
+	Audit     AuditConfig     `toml:"audit"`
+	Health    HealthConfig    `toml:"health"`
+	UI        UIConfig        `toml:"ui"`
+	TUI       TUIConfig       `toml:"tui"`
+}
+
+type StoreConfig struct {
+	Path string `toml:"path"`
+	// Key overrides the .gpg-id of the root store, like PASSWORD_STORE_KEY
+	Key string `toml:"key,omitempty"`
+	// SigningKeys are the fingerprints of the keys trusted to sign .gpg-id
+	// files, like PASSWORD_STORE_SIGNING_KEY. When set, entries are only
+	// encrypted for a .gpg-id signed by one of them.
+	SigningKeys []string `toml:"signing_keys,omitempty"`
+}
+
+// MountConfig mounts the store at Path under Prefix, see Mount. Name
+// defaults to the prefix.
+type MountConfig struct {
+	Name   string `toml:"name,omitempty"`
+	Prefix string `toml:"prefix"`
+	Path   string `toml:"path"`
+}
+
+type CryptoConfig struct {
+	// Backend is the OpenPGP implementation, only gopenpgp is available
+	Backend string `toml:"backend"`
+}
+
+type CacheConfig struct {
+	TTL Duration `toml:"ttl"`
+}
+
+type ClipboardConfig struct {
+	Timeout Duration `toml:"timeout"`
+}
+
+type AutoLockConfig struct {
+	// Idle locks the store after this much inactivity, 0 turns it off
+	Idle Duration `toml:"idle"`
+}
+
+type ServerConfig struct {
+	Listen []string `toml:"listen"`
+}
+
+type AuditConfig struct {
+	// Sink is a file path, "syslog" or "off"
+	Sink string `toml:"sink"`
+}
+
+type HealthConfig struct {
+	PasswordMaxAge Duration `toml:"password_max_age"`
+	BreachFile     string   `toml:"breach_file,omitempty"`
+}
+
+type UIConfig struct {
+	Theme string `toml:"theme"`
+}
+
+// TUIConfig are the key bindings of the terminal UI. Keys overrides the
+// bindings of the preset by page then action, with space separated key names
+// such as "Ctrl+A", "Alt+s", "F1" or "q".
+type TUIConfig struct {
+	Keymap string                       `toml:"keymap"`
+	Keys   map[string]map[string]string `toml:"keys,omitempty"`
+}
+
+// Duration is a time.Duration written as text in the configuration file.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := parseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	if d.Duration != 0 && d.Duration%(24*time.Hour) == 0 {
+		return []byte(strconv.FormatInt(int64(d.Duration/(24*time.Hour)), 10) + "d"), nil
+	}
+	return []byte(d.Duration.String()), nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// ConfigFile is the gopass configuration file, in the user configuration
+// folder ($XDG_CONFIG_HOME on Linux), or GOPASS_CONFIG when set.
+func ConfigFile() string {
+	if v := os.Getenv("GOPASS_CONFIG"); v != "" {
+		return v
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gopass", "config.toml")
+}
+
+// Default returns the configuration used when there is no file.
+func Default() *Config {
+	home, _ := os.UserHomeDir()
+	return &Config{
+		Store:     StoreConfig{Path: filepath.Join(home, ".password-store")},
+		Crypto:    CryptoConfig{Backend: BackendGopenPGP},
+		Cache:     CacheConfig{TTL: Duration{30 * time.Minute}},
+		Clipboard: ClipboardConfig{Timeout: Duration{45 * time.Second}},
+		AutoLock:  AutoLockConfig{Idle: Duration{10 * time.Minute}},
+		Server:    ServerConfig{Listen: []string{":8080"}},
+		Audit:     AuditConfig{Sink: filepath.Join(home, ".gopass", "audit.log")},
+		Health:    HealthConfig{PasswordMaxAge: Duration{365 * 24 * time.Hour}},
+		UI:        UIConfig{Theme: ThemeSystem},
+		TUI:       TUIConfig{Keymap: KeymapDefault},
+	}
+}
+
+// Load reads ConfigFile over the defaults, applies the environment overrides
+// and validates the result. A missing file is not an error.
+func Load() (*Config, error) {
+	return LoadFile(ConfigFile())
+}
+
+// LoadFile is Load for another file.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, 0, len(undecoded))
+			for _, key := range undecoded {
+				keys = append(keys, key.String())
+			}
+			return nil, fmt.Errorf("%s: unknown settings: %s", path, strings.Join(keys, ", "))
+		}
+	}
+
+	applyEnv(cfg)
+	if err := validate(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// applyEnv applies the environment variables of pass and gopass, they win
+// over the file.
+func applyEnv(cfg *Config) {
+	if v := os.Getenv("PASSWORD_STORE_DIR"); v != "" {
+		cfg.Store.Path = v
+	}
+	if v := os.Getenv("PASSWORD_STORE_KEY"); v != "" {
+		cfg.Store.Key = v
+	}
+	if v := os.Getenv("PASSWORD_STORE_SIGNING_KEY"); v != "" {
+		// Whitespace separated like pass
+		cfg.Store.SigningKeys = strings.Fields(v)
+	}
+	if v, ok := os.LookupEnv("PASSWORD_STORE_CLIP_TIME"); ok {
+		// Seconds like pass, validate reports a bad value
+		seconds, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			seconds = -1
+		}
+		cfg.Clipboard.Timeout = Duration{time.Duration(seconds) * time.Second}
+	}
+	if v, ok := os.LookupEnv("GOPASS_AUDIT_LOG"); ok {
+		if v == "" {
+			v = AuditOff
+		}
+		cfg.Audit.Sink = v
+	}
+	if v, ok := os.LookupEnv("GOPASS_PASSWORD_MAX_AGE_DAYS"); ok {
+		days, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			days = -1
+		}
+		cfg.Health.PasswordMaxAge = Duration{time.Duration(days) * 24 * time.Hour}
+	}
+	if v := os.Getenv("GOPASS_BREACH_FILE"); v != "" {
+		cfg.Health.BreachFile = v
+	}
+}
+
+// validate checks every setting, normalizes paths and prefixes, and returns
+// all the problems found at once.
+func validate(cfg *Config) error {
+	var errs []error
+	problem := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if cfg.Store.Path == "" {
+		problem("store.path: must be set")
+	}
+	cfg.Store.Path = expandHome(cfg.Store.Path)
+	if strings.ContainsAny(cfg.Store.Key, " \t") {
+		problem("store.key: %q must be a single key id", cfg.Store.Key)
+	}
+	for i, key := range cfg.Store.SigningKeys {
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if !isFingerprint(key) {
+			problem("store.signing_keys: %q must be a full 40 character fingerprint", cfg.Store.SigningKeys[i])
+		}
+		cfg.Store.SigningKeys[i] = key
+	}
+
+	names := map[string]bool{RootStoreName: true}
+	prefixes := map[string]bool{}
+	for i := range cfg.Mounts {
+		m := &cfg.Mounts[i]
+		m.Prefix = strings.Trim(filepath.ToSlash(m.Prefix), "/")
+		m.Path = expandHome(m.Path)
+		label := fmt.Sprintf("mounts[%d]", i)
+		if m.Prefix == "" {
+			problem("%s.prefix: must be set", label)
+			continue
+		}
+		label = fmt.Sprintf("mounts %q", m.Prefix)
+		if m.Path == "" {
+			problem("%s: path must be set", label)
+		}
+		name := m.Name
+		if name == "" {
+			name = m.Prefix
+		}
+		if names[name] {
+			problem("%s: duplicate store name %q", label, name)
+		}
+		if prefixes[m.Prefix] {
+			problem("%s: duplicate prefix", label)
+		}
+		names[name], prefixes[m.Prefix] = true, true
+	}
+	sort.SliceStable(cfg.Mounts, func(i, j int) bool { return cfg.Mounts[i].Prefix < cfg.Mounts[j].Prefix })
+
+	if cfg.Crypto.Backend != BackendGopenPGP {
+		problem("crypto.backend: unsupported backend %q, only %s is available", cfg.Crypto.Backend, BackendGopenPGP)
+	}
+	if cfg.Cache.TTL.Duration < 0 {
+		problem("cache.ttl: must not be negative")
+	}
+	if cfg.Clipboard.Timeout.Duration < 0 {
+		problem("clipboard.timeout: must not be negative (PASSWORD_STORE_CLIP_TIME is in seconds)")
+	}
+	if cfg.AutoLock.Idle.Duration < 0 {
+		problem("autolock.idle: must not be negative")
+	}
+	if len(cfg.Server.Listen) == 0 {
+		problem("server.listen: at least one address is needed")
+	}
+	for _, addr := range cfg.Server.Listen {
+		if !strings.Contains(addr, ":") {
+			problem("server.listen: %q is not a host:port address", addr)
+		}
+	}
+	if cfg.Audit.Sink == "" {
+		problem("audit.sink: must be a file path, %q or %q", AuditSyslog, AuditOff)
+	} else if cfg.Audit.Sink != AuditOff && cfg.Audit.Sink != AuditSyslog {
+		cfg.Audit.Sink = expandHome(cfg.Audit.Sink)
+	}
+	if cfg.Health.PasswordMaxAge.Duration < 0 {
+		problem("health.password_max_age: must not be negative")
+	}
+	cfg.Health.BreachFile = expandHome(cfg.Health.BreachFile)
+	switch cfg.UI.Theme {
+	case ThemeSystem, ThemeLight, ThemeDark:
+	default:
+		problem("ui.theme: %q must be %s, %s or %s", cfg.UI.Theme, ThemeSystem, ThemeLight, ThemeDark)
+	}
+	switch cfg.TUI.Keymap {
+	case KeymapDefault, KeymapVim, KeymapEmacs:
+	default:
+		problem("tui.keymap: %q must be %s, %s or %s", cfg.TUI.Keymap, KeymapDefault, KeymapVim, KeymapEmacs)
+	}
+
+	return errors.Join(errs...)
+}
+
+// fallbackSigningKeys returns the signing keys of a configuration file that
+// failed to load, from the environment or the file itself. It fails when the
+// file may set signing keys that cannot be read.
+func fallbackSigningKeys(path string) ([]string, error) {
+	var keys []string
+	if v := os.Getenv("PASSWORD_STORE_SIGNING_KEY"); v != "" {
+		keys = strings.Fields(v)
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("store.signing_keys of %s cannot be read: %w", path, err)
+		}
+		var file struct {
+			Store struct {
+				SigningKeys []string `toml:"signing_keys"`
+			} `toml:"store"`
+		}
+		if _, err := toml.Decode(string(data), &file); err != nil {
+			if bytes.Contains(data, []byte("signing_keys")) {
+				return nil, fmt.Errorf("store.signing_keys of %s cannot be read: %w", path, err)
+			}
+			return nil, nil
+		}
+		keys = file.Store.SigningKeys
+	}
+
+	for i, key := range keys {
+		keys[i] = strings.ToUpper(strings.TrimSpace(key))
+		if !isFingerprint(keys[i]) {
+			return nil, fmt.Errorf("store.signing_keys: %q must be a full 40 character fingerprint", key)
+		}
+	}
+	return keys, nil
+}
+
+// isFingerprint reports whether s is a v4 key fingerprint, short key ids and
+// user ids are too easy to collide with to trust a signature.
+func isFingerprint(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789ABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode writes the configuration as TOML.
+func (c *Config) Encode(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(c)
+}
+
+// Write saves the configuration to a new file, e.g. to create a starting
+// file. An existing file is not replaced.
+func (c *Config) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := c.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
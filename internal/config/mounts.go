@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// RootStoreName is the name of the store mounted at the root of the
+// namespace, the one at PasswordStoreDir.
+const RootStoreName = "default"
+
+// Mount is a password store mounted at a prefix of the entry namespace. The
+// entry "team/aws" lives in the store mounted at "team" as "aws". The root
+// store has an empty prefix. Every store is its own git repository with its
+// own remote and .gpg-id.
+type Mount struct {
+	Name   string
+	Prefix string
+	Path   string
+	GPGId  string
+}
+
+// Entry returns the namespace name of an entry of the store.
+func (m Mount) Entry(rel string) string {
+	if m.Prefix == "" {
+		return rel
+	}
+	return m.Prefix + "/" + rel
+}
+
+// Mounts returns the root store followed by the mounted stores, sorted by
+// prefix.
+func Mounts() []Mount {
+	return append([]Mount(nil), get().mounts...)
+}
+
+// MountFor returns the store holding an entry and the entry name inside that
+// store. The longest matching prefix wins, so "team/ops" can be mounted
+// separately from "team".
+func MountFor(entry string) (Mount, string) {
+	return mountFor(Mounts(), entry)
+}
+
+func mountFor(all []Mount, entry string) (Mount, string) {
+	entry = filepath.ToSlash(entry)
+	best := all[0]
+	for _, m := range all[1:] {
+		if (entry == m.Prefix || strings.HasPrefix(entry, m.Prefix+"/")) && len(m.Prefix) > len(best.Prefix) {
+			best = m
+		}
+	}
+	if best.Prefix == "" {
+		return best, entry
+	}
+	return best, strings.TrimPrefix(strings.TrimPrefix(entry, best.Prefix), "/")
+}
+
+// MountByName returns the store with the given name.
+func MountByName(name string) (Mount, bool) {
+	for _, m := range Mounts() {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Mount{}, false
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileMounts(t *testing.T) {
+	t.Setenv("PASSWORD_STORE_DIR", "")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	os.WriteFile(path, []byte(`
+[[mounts]]
+prefix = "team/"
+path = "`+filepath.Join(dir, "team")+`"
+
+[[mounts]]
+name = "acme"
+prefix = "clients/acme"
+path = "`+filepath.Join(dir, "acme")+`"
+`), 0600)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if len(cfg.Mounts) != 2 {
+		t.Fatalf("expected 2 mounts, got %v", cfg.Mounts)
+	}
+	if cfg.Mounts[0].Name != "acme" || cfg.Mounts[0].Prefix != "clients/acme" {
+		t.Errorf("unexpected first mount %+v", cfg.Mounts[0])
+	}
+	if cfg.Mounts[1].Prefix != "team" {
+		t.Errorf("unexpected second mount %+v", cfg.Mounts[1])
+	}
+
+	os.WriteFile(path, []byte("[[mounts]]\nprefix = \"team\"\npath = \"a\"\n[[mounts]]\nprefix = \"team\"\npath = \"b\"\n"), 0600)
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected an error for a duplicate prefix")
+	}
+}
+
+func TestMountFor(t *testing.T) {
+	all := []Mount{
+		{Name: RootStoreName, Path: "/root"},
+		{Name: "team", Prefix: "team", Path: "/team"},
+		{Name: "ops", Prefix: "team/ops", Path: "/ops"},
+	}
+	tests := []struct {
+		entry, store, rel string
+	}{
+		{"github", RootStoreName, "github"},
+		{"teamwork/x", RootStoreName, "teamwork/x"},
+		{"team/aws", "team", "aws"},
+		{"team/ops/db", "ops", "db"},
+	}
+	for _, tt := range tests {
+		m, rel := mountFor(all, tt.entry)
+		if m.Name != tt.store || rel != tt.rel {
+			t.Errorf("mountFor(%q) = %s, %q, want %s, %q", tt.entry, m.Name, rel, tt.store, tt.rel)
+		}
+	}
+}
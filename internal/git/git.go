@@ -3,13 +3,24 @@ package git
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	gitcfg "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 )
 
-// SyncWithRemote pulls and pushes the password store directory with its remote.
-// it uses ssh-keys from ssh-agent to authenticate.
-func SyncWithRemote(storeDir string) error {
+// SyncWithRemote pulls and pushes the password store directory with its sync
+// remote, see ListRemotes. A store without remote only commits its changes.
+// It uses ssh-keys from ssh-agent to authenticate. progress, if not nil, is
+// called with the name of each stage as it starts.
+func SyncWithRemote(storeDir string, progress func(stage string)) error {
+	if progress == nil {
+		progress = func(string) {}
+	}
+
 	repo, err := git.PlainOpen(storeDir)
 	if err != nil {
 		return fmt.Errorf("failed to open git repo: %w", err)
@@ -18,27 +29,140 @@ func SyncWithRemote(storeDir string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
-	// Pull from remote
-	err = w.Pull(&git.PullOptions{RemoteName: "origin", Force: true})
-	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return fmt.Errorf("git pull failed: %w", err)
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read git config: %w", err)
+	}
+	remote := syncRemote(cfg)
+	// Pull from remote, a new empty one gets the store on push
+	var pullErr error
+	if remote != "" {
+		progress("pulling")
+		pullErr = w.Pull(&git.PullOptions{RemoteName: remote, Force: true})
+		if pullErr != nil && pullErr != git.NoErrAlreadyUpToDate && !isEmptyRemote(pullErr) {
+			return fmt.Errorf("git pull failed: %w", pullErr)
+		}
 	}
 	// Add all changes
+	progress("committing")
 	_ = w.AddWithOptions(&git.AddOptions{All: true})
 	// Commit (if any changes)
-	_, err = w.Commit("gopass sync", &git.CommitOptions{AllowEmptyCommits: false})
-	if err != nil {
-		if errors.Is(err, git.ErrEmptyCommit) {
-			return nil
-		}
-
+	_, err = w.Commit("gopass sync", commitOptions(repo))
+	if err != nil && !errors.Is(err, git.ErrEmptyCommit) {
 		return fmt.Errorf("git commit failed: %w", err)
 	}
-	// Push to remote
-	err = repo.Push(&git.PushOptions{RemoteName: "origin"})
+	if remote == "" {
+		return nil
+	}
+	// Push to remote, also without a new commit as the changes may have been
+	// committed before, e.g. by CommitAll
+	progress("pushing")
+	err = repo.Push(&git.PushOptions{RemoteName: remote})
 	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
 		return fmt.Errorf("git push failed: %w", err)
 	}
 
 	return nil
 }
+
+// commitOptions commits as the user of the git config, or as gopass when
+// none is set, e.g. on a new machine, so committing never fails for it.
+func commitOptions(repo *git.Repository) *git.CommitOptions {
+	opts := &git.CommitOptions{AllowEmptyCommits: false}
+	cfg, err := repo.ConfigScoped(gitcfg.SystemScope)
+	if err == nil && cfg.User.Name == "" && cfg.User.Email == "" && cfg.Author.Name == "" && cfg.Author.Email == "" {
+		opts.Author = &object.Signature{Name: "gopass", Email: "gopass@localhost", When: time.Now()}
+	}
+	return opts
+}
+
+// CommitAll stages every change in the password store directory and commits
+// it with message. It does nothing when there is nothing to commit.
+func CommitAll(storeDir, message string) error {
+	repo, err := git.PlainOpen(storeDir)
+	if err != nil {
+		return fmt.Errorf("failed to open git repo: %w", err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := w.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return fmt.Errorf("git add failed: %w", err)
+	}
+	_, err = w.Commit(message, commitOptions(repo))
+	if err != nil && !errors.Is(err, git.ErrEmptyCommit) {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// LastModified returns the time of the newest commit touching each of the
+// paths, relative to the repository root. Paths never committed are missing
+// from the result.
+func LastModified(storeDir string, paths []string) (map[string]time.Time, error) {
+	repo, err := git.PlainOpen(storeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repo: %w", err)
+	}
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// No commit yet
+		return map[string]time.Time{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		wanted[p] = true
+	}
+	modified := make(map[string]time.Time, len(paths))
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+	defer commits.Close()
+
+	err = commits.ForEach(func(c *object.Commit) error {
+		tree, err := c.Tree()
+		if err != nil {
+			return err
+		}
+		var parentTree *object.Tree
+		if c.NumParents() > 0 {
+			parent, err := c.Parent(0)
+			if err != nil {
+				return err
+			}
+			if parentTree, err = parent.Tree(); err != nil {
+				return err
+			}
+		}
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+			if _, seen := modified[name]; wanted[name] && !seen {
+				modified[name] = c.Committer.When
+			}
+		}
+		if len(modified) == len(wanted) {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk git log: %w", err)
+	}
+
+	return modified, nil
+}
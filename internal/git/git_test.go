@@ -0,0 +1,63 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitcfg "github.com/go-git/go-git/v5/config"
+)
+
+func TestSyncPushesEarlierCommits(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name, cfg.User.Email = "test", "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte("KEY\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	remote := t.TempDir()
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&gitcfg.RemoteConfig{Name: "origin", URLs: []string{remote}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SyncWithRemote(dir, nil); err != nil {
+		t.Fatalf("SyncWithRemote failed: %v", err)
+	}
+
+	// Edits are committed as they happen, the sync has nothing to commit
+	if err := os.WriteFile(filepath.Join(dir, "site.gpg"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := CommitAll(dir, "edit site"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SyncWithRemote(dir, nil); err != nil {
+		t.Fatalf("SyncWithRemote failed: %v", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare, err := git.PlainOpen(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := bare.Reference(head.Name(), true)
+	if err != nil || ref.Hash() != head.Hash() {
+		t.Errorf("expected the remote at %s, got %v, %v", head.Hash(), ref, err)
+	}
+}
@@ -0,0 +1,169 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	gitcfg "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// syncSection and syncOption keep the name of the remote synced with in the
+// repository config, as "[gopass] remote = name".
+const (
+	syncSection = "gopass"
+	syncOption  = "remote"
+)
+
+// Remote is a git remote of a store.
+type Remote struct {
+	Name string
+	URL  string
+	// Sync is set on the remote SyncWithRemote pulls from and pushes to
+	Sync bool
+}
+
+// ListRemotes returns the remotes of the store directory by name.
+func ListRemotes(storeDir string) ([]Remote, error) {
+	repo, err := git.PlainOpen(storeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repo: %w", err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	syncName := syncRemote(cfg)
+	remotes := make([]Remote, 0, len(cfg.Remotes))
+	for name, rc := range cfg.Remotes {
+		r := Remote{Name: name, Sync: name == syncName}
+		if len(rc.URLs) > 0 {
+			r.URL = rc.URLs[0]
+		}
+		remotes = append(remotes, r)
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+// syncRemote returns the remote to sync with: the one set with
+// SetSyncRemote, else origin, else the only remote. It is empty for a store
+// that stays local.
+func syncRemote(cfg *gitcfg.Config) string {
+	if name := cfg.Raw.Section(syncSection).Option(syncOption); name != "" {
+		if _, ok := cfg.Remotes[name]; ok {
+			return name
+		}
+	}
+	if _, ok := cfg.Remotes["origin"]; ok {
+		return "origin"
+	}
+	if len(cfg.Remotes) == 1 {
+		for name := range cfg.Remotes {
+			return name
+		}
+	}
+	return ""
+}
+
+// AddRemote adds a remote to the store directory.
+func AddRemote(storeDir, name, url string) error {
+	if name == "" || url == "" {
+		return fmt.Errorf("a remote needs a name and a URL")
+	}
+	repo, err := git.PlainOpen(storeDir)
+	if err != nil {
+		return fmt.Errorf("failed to open git repo: %w", err)
+	}
+	if _, err := repo.CreateRemote(&gitcfg.RemoteConfig{Name: name, URLs: []string{url}}); err != nil {
+		return fmt.Errorf("failed to add remote %s: %w", name, err)
+	}
+	return nil
+}
+
+// SetRemoteURL changes the URL of a remote of the store directory.
+func SetRemoteURL(storeDir, name, url string) error {
+	if url == "" {
+		return fmt.Errorf("a remote needs a URL")
+	}
+	return updateConfig(storeDir, func(cfg *gitcfg.Config) error {
+		rc, ok := cfg.Remotes[name]
+		if !ok {
+			return fmt.Errorf("no remote %s", name)
+		}
+		rc.URLs = []string{url}
+		return nil
+	})
+}
+
+// RemoveRemote removes a remote of the store directory. Removing the remote
+// synced with makes the store sync with the next one SyncWithRemote picks.
+func RemoveRemote(storeDir, name string) error {
+	return updateConfig(storeDir, func(cfg *gitcfg.Config) error {
+		if _, ok := cfg.Remotes[name]; !ok {
+			return fmt.Errorf("no remote %s", name)
+		}
+		delete(cfg.Remotes, name)
+		if cfg.Raw.Section(syncSection).Option(syncOption) == name {
+			cfg.Raw.Section(syncSection).RemoveOption(syncOption)
+		}
+		return nil
+	})
+}
+
+// SetSyncRemote makes SyncWithRemote pull from and push to the remote name.
+func SetSyncRemote(storeDir, name string) error {
+	return updateConfig(storeDir, func(cfg *gitcfg.Config) error {
+		if _, ok := cfg.Remotes[name]; !ok {
+			return fmt.Errorf("no remote %s", name)
+		}
+		cfg.Raw.Section(syncSection).SetOption(syncOption, name)
+		return nil
+	})
+}
+
+func updateConfig(storeDir string, update func(cfg *gitcfg.Config) error) error {
+	repo, err := git.PlainOpen(storeDir)
+	if err != nil {
+		return fmt.Errorf("failed to open git repo: %w", err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read git config: %w", err)
+	}
+	if err := update(cfg); err != nil {
+		return err
+	}
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to write git config: %w", err)
+	}
+	return nil
+}
+
+// PushToRemote commits the pending changes and pushes every branch to the
+// remote name, e.g. to publish a local store to a new empty repository.
+func PushToRemote(storeDir, name, message string) error {
+	if err := CommitAll(storeDir, message); err != nil {
+		return err
+	}
+	repo, err := git.PlainOpen(storeDir)
+	if err != nil {
+		return fmt.Errorf("failed to open git repo: %w", err)
+	}
+	err = repo.Push(&git.PushOptions{
+		RemoteName: name,
+		RefSpecs:   []gitcfg.RefSpec{"refs/heads/*:refs/heads/*"},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("git push to %s failed: %w", name, err)
+	}
+	return nil
+}
+
+// isEmptyRemote reports whether err is a pull from a remote without commits.
+func isEmptyRemote(err error) bool {
+	return errors.Is(err, transport.ErrEmptyRemoteRepository)
+}
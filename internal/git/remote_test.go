@@ -0,0 +1,134 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// newStore returns a repository with a committer and a .gpg-id.
+func newStore(t *testing.T) string {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name, cfg.User.Email = "test", "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte("KEY\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRemotes(t *testing.T) {
+	dir := newStore(t)
+	if remotes, err := ListRemotes(dir); err != nil || len(remotes) != 0 {
+		t.Fatalf("expected a local store, got %v, %v", remotes, err)
+	}
+
+	if err := AddRemote(dir, "backup", "git@example.com:backup.git"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddRemote(dir, "origin", "git@example.com:store.git"); err != nil {
+		t.Fatal(err)
+	}
+	remotes, err := ListRemotes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remotes) != 2 || remotes[0].Name != "backup" || remotes[0].Sync || !remotes[1].Sync {
+		t.Errorf("expected origin to be synced by default, got %+v", remotes)
+	}
+
+	if err := SetSyncRemote(dir, "backup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetRemoteURL(dir, "backup", "git@example.com:other.git"); err != nil {
+		t.Fatal(err)
+	}
+	remotes, _ = ListRemotes(dir)
+	if !remotes[0].Sync || remotes[0].URL != "git@example.com:other.git" || remotes[1].Sync {
+		t.Errorf("expected backup to be synced with its new URL, got %+v", remotes)
+	}
+
+	if err := RemoveRemote(dir, "backup"); err != nil {
+		t.Fatal(err)
+	}
+	remotes, _ = ListRemotes(dir)
+	if len(remotes) != 1 || remotes[0].Name != "origin" || !remotes[0].Sync {
+		t.Errorf("expected origin to be synced again, got %+v", remotes)
+	}
+	if err := SetSyncRemote(dir, "missing"); err == nil {
+		t.Error("expected an unknown remote to be rejected")
+	}
+}
+
+func TestPushLocalStoreToEmptyRemote(t *testing.T) {
+	dir := newStore(t)
+	if err := SyncWithRemote(dir, nil); err != nil {
+		t.Fatalf("a local store should sync by committing: %v", err)
+	}
+
+	remote := t.TempDir()
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddRemote(dir, "origin", remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "site.gpg"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := PushToRemote(dir, "origin", "publish"); err != nil {
+		t.Fatalf("PushToRemote failed: %v", err)
+	}
+
+	if !hasBranch(t, remote) {
+		t.Error("expected the store to be pushed")
+	}
+
+	// Syncing with a new empty remote pushes even without new changes
+	mirror := t.TempDir()
+	if _, err := git.PlainInit(mirror, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddRemote(dir, "mirror", mirror); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSyncRemote(dir, "mirror"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SyncWithRemote(dir, nil); err != nil {
+		t.Fatalf("SyncWithRemote failed: %v", err)
+	}
+	if !hasBranch(t, mirror) {
+		t.Error("expected the store to be pushed on sync")
+	}
+}
+
+func hasBranch(t *testing.T, dir string) bool {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := repo.References()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	refs.ForEach(func(ref *plumbing.Reference) error {
+		found = found || ref.Name().IsBranch()
+		return nil
+	})
+	return found
+}
@@ -4,12 +4,12 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -18,11 +18,69 @@ type passphraseCache struct {
 	ExpiresAt  time.Time `json:"expires_at"`
 }
 
-// getCacheKey returns a static key for AES (for demo: use a fixed key, but in production use a better secret)
-func getCacheKey() []byte {
-	// Use SHA-256 to ensure a 32-byte key
-	sum := sha256.Sum256([]byte("gopass-demo-static-key"))
-	return sum[:]
+// cacheKeyFile is created next to the cache, with a random key generated on
+// first use, so every install has its own key.
+const cacheKeyFile = "cache.key"
+
+// getCacheKey returns the AES key of the passphrase cache at cachePath. The
+// key file is created exclusively, a process losing the race to create it
+// reads the key of the one that won.
+func getCacheKey(cachePath string) ([]byte, error) {
+	path := filepath.Join(filepath.Dir(cachePath), cacheKeyFile)
+	for attempt := 0; ; attempt++ {
+		key, err := os.ReadFile(path)
+		switch {
+		case err == nil && len(key) == 32:
+			return key, nil
+		case err == nil && attempt < 10:
+			// The process that created the key may still be writing it
+			time.Sleep(10 * time.Millisecond)
+			continue
+		case err == nil:
+			// A broken key is replaced, the cache it encrypted is lost anyway
+			key, err = newCacheKey()
+			if err != nil {
+				return nil, err
+			}
+			return key, os.WriteFile(path, key, 0600)
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+
+		key, err = createCacheKey(path)
+		if !errors.Is(err, os.ErrExist) {
+			return key, err
+		}
+	}
+}
+
+// createCacheKey writes a new key to path, it fails with os.ErrExist when the
+// file exists.
+func createCacheKey(path string) ([]byte, error) {
+	key, err := newCacheKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(key); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return key, f.Close()
+}
+
+func newCacheKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
 }
 
 func EncryptAndCachePassphrase(passphrase, cachePath string, duration time.Duration) error {
@@ -34,7 +92,11 @@ func EncryptAndCachePassphrase(passphrase, cachePath string, duration time.Durat
 	if err != nil {
 		return err
 	}
-	block, err := aes.NewCipher(getCacheKey())
+	key, err := getCacheKey(cachePath)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return err
 	}
@@ -60,7 +122,11 @@ func DecryptCachedPassphrase(cachePath string) (string, bool, error) {
 	if err != nil {
 		return "", false, err
 	}
-	block, err := aes.NewCipher(getCacheKey())
+	key, err := getCacheKey(cachePath)
+	if err != nil {
+		return "", false, err
+	}
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", false, err
 	}
@@ -83,7 +149,7 @@ func DecryptCachedPassphrase(cachePath string) (string, bool, error) {
 		return "", false, err
 	}
 
-	slog.Info("Decrypted cached passphrase", "expires_at", cache.ExpiresAt, "time now", time.Now())
+	slog.Debug("Decrypted cached passphrase", "expires_at", cache.ExpiresAt, "time now", time.Now())
 
 	if time.Now().After(cache.ExpiresAt) {
 		return "", false, nil
@@ -1,14 +1,17 @@
 package gpg
 
 import (
+	"bytes"
+	"errors"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestEncryptAndCachePassphraseAndDecrypt(t *testing.T) {
-	cachePath := "/tmp/gopass_test_passphrase.cache"
-	defer os.Remove(cachePath)
+	cachePath := filepath.Join(t.TempDir(), "passphrase.cache")
 	pass := "testpassphrase123"
 	duration := 2 * time.Second
 
@@ -38,3 +41,35 @@ func TestEncryptAndCachePassphraseAndDecrypt(t *testing.T) {
 		t.Errorf("Expected cache to be expired, but got valid")
 	}
 }
+
+func TestGetCacheKeyIsCreatedOnce(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "passphrase.cache")
+
+	keys := make([][]byte, 8)
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys[i], errs[i] = getCacheKey(cachePath)
+		}()
+	}
+	wg.Wait()
+	for i := range keys {
+		if errs[i] != nil {
+			t.Fatalf("getCacheKey failed: %v", errs[i])
+		}
+		if !bytes.Equal(keys[i], keys[0]) {
+			t.Fatal("expected every caller to get the same key")
+		}
+	}
+
+	path := filepath.Join(filepath.Dir(cachePath), cacheKeyFile)
+	if _, err := createCacheKey(path); !errors.Is(err, os.ErrExist) {
+		t.Errorf("expected an existing key not to be replaced, got %v", err)
+	}
+	if key, _ := os.ReadFile(path); !bytes.Equal(key, keys[0]) {
+		t.Error("expected the key file to hold the key")
+	}
+}
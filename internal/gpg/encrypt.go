@@ -2,24 +2,67 @@ package gpg
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
 )
 
-// EncryptWithGPGKey encrypts the content with the given GPG key id using gopenpgp and returns the armored ciphertext.
-func EncryptWithGPGKey(plaintext []byte, keyID string) ([]byte, error) {
-	armored, err := LoadArmoredPublicKey(keyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load armored public key: %w", err)
+// Recipients are the keys an entry is encrypted for.
+type Recipients struct {
+	// GPGId is the .gpg-id file listing the keys
+	GPGId string
+	// Keys are the keys, one per line, when they do not come from a .gpg-id,
+	// e.g. from PASSWORD_STORE_KEY
+	Keys string
+}
+
+// Encrypt encrypts the content for the recipients using gopenpgp and returns
+// the ciphertext. The keys of a .gpg-id are only trusted once VerifyGPGId
+// accepts its signature, anyone who can push to the store could have added
+// their own key otherwise.
+func Encrypt(plaintext []byte, r Recipients) ([]byte, error) {
+	gpgId := r.Keys
+	if r.GPGId != "" {
+		data, err := os.ReadFile(r.GPGId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", r.GPGId, err)
+		}
+		signers, err := signingKeys()
+		if err == nil {
+			err = verifyGPGId(r.GPGId, data, signers)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w, refusing to encrypt for its keys", err)
+		}
+		gpgId = string(data)
 	}
-	keyObj, err := crypto.NewKeyFromArmored(armored)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse armored public key: %w", err)
+	return EncryptForRecipients(plaintext, ParseRecipients(gpgId))
+}
+
+// EncryptForRecipients encrypts the content for every recipient, any of them
+// can decrypt it.
+func EncryptForRecipients(plaintext []byte, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no recipient to encrypt for")
 	}
-	keyRing, err := crypto.NewKeyRing(keyObj)
+	keyRing, err := crypto.NewKeyRing(nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create keyring: %w", err)
 	}
+	for _, recipient := range recipients {
+		armored, err := LoadArmoredPublicKey(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load armored public key %s: %w", recipient, err)
+		}
+		keyObj, err := crypto.NewKeyFromArmored(armored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse armored public key %s: %w", recipient, err)
+		}
+		if err := keyRing.AddKey(keyObj); err != nil {
+			return nil, fmt.Errorf("failed to add key %s: %w", recipient, err)
+		}
+	}
 	message := crypto.NewPlainMessage(plaintext)
 	encrypted, err := keyRing.Encrypt(message, nil)
 	if err != nil {
@@ -28,3 +71,15 @@ func EncryptWithGPGKey(plaintext []byte, keyID string) ([]byte, error) {
 
 	return encrypted.Data, nil
 }
+
+// ParseRecipients returns the keys of .gpg-id content, one per line like
+// pass, without blank lines and comments.
+func ParseRecipients(gpgId string) []string {
+	var recipients []string
+	for _, line := range strings.Split(gpgId, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			recipients = append(recipients, line)
+		}
+	}
+	return recipients
+}
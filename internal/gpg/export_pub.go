@@ -9,13 +9,25 @@ import (
 
 // ExportArmoredPublicKey exports the public key with the given keyID to ~/.gopass/<keyID>.public.asc in ASCII-armored format.
 func ExportArmoredPublicKey(keyID, outputPath string) error {
-	cmd := exec.Command("gpg", "--export", "--armor", keyID)
-	out, err := cmd.Output()
+	out, err := ArmoredPublicKey(keyID)
 	if err != nil {
-		return fmt.Errorf("failed to export public key: %w", err)
+		return err
 	}
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0700); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
-	return os.WriteFile(outputPath, out, 0600)
+	return os.WriteFile(outputPath, []byte(out), 0600)
+}
+
+// ArmoredPublicKey returns the ASCII-armored public key keyID of the keyring,
+// e.g. to send it to the admin of a store.
+func ArmoredPublicKey(keyID string) (string, error) {
+	out, err := exec.Command("gpg", "--export", "--armor", keyID).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to export public key: %w", err)
+	}
+	if len(out) == 0 {
+		return "", fmt.Errorf("no public key %s in the keyring", keyID)
+	}
+	return string(out), nil
 }
@@ -3,65 +3,260 @@ package gpg
 import (
 	"fmt"
 	"os"
-	"os/user"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
-	"github.com/duykhoa/gopass/internal/config"
 )
 
-// DecryptGPGFileWithKey decrypts a GPG file using the first private key found in the user's GPG keyring.
+// DecryptGPGFileWithKey decrypts a GPG file with the secret key of the user's
+// GPG keyring it is encrypted to.
 func DecryptGPGFileWithKey(gpgFile, passphrase string) (string, error) {
-	usr, err := user.Current()
+	d := NewDecrypter(passphrase)
+	defer d.Close()
+	return d.DecryptFile(gpgFile)
+}
+
+// Decrypter decrypts files with the secret keys of the user's GPG keyring.
+// Each key is exported to ~/.gopass and unlocked once, then shared: a
+// Decrypter is safe for concurrent use.
+type Decrypter struct {
+	passphrase string
+
+	mu   sync.Mutex
+	keys []*crypto.Key
+	// keyrings holds the unlocked keys by the key IDs of their primary key
+	// and subkeys, the IDs a message is encrypted to
+	keyrings map[uint64]*crypto.KeyRing
+}
+
+// NewDecrypter returns a Decrypter unlocking the secret keys with passphrase.
+func NewDecrypter(passphrase string) *Decrypter {
+	return &Decrypter{passphrase: passphrase, keyrings: map[uint64]*crypto.KeyRing{}}
+}
+
+// Unlock unlocks the secret key of one of the keys of a .gpg-id, e.g. to
+// check the passphrase before decrypting anything.
+func (d *Decrypter) Unlock(gpgId string) error {
+	recipients := ParseRecipients(gpgId)
+	for _, id := range recipients {
+		path, ok, err := recipientKeyFile(id, d.passphrase)
+		if err != nil {
+			return err
+		}
+		if ok {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			_, err := d.unlock(path)
+			return err
+		}
+	}
+	return fmt.Errorf("no secret key for any of %s", strings.Join(recipients, ", "))
+}
+
+// DecryptFile decrypts a GPG file with the secret key it is encrypted to.
+func (d *Decrypter) DecryptFile(gpgFile string) (string, error) {
+	ciphertext, err := os.ReadFile(gpgFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read gpg file: %w", err)
+	}
+
+	ids, err := MessageRecipients(ciphertext)
 	if err != nil {
-		return "", fmt.Errorf("failed to get current user: %w", err)
+		return "", err
 	}
 
-	// Find .gpg-id in the password store directory
-	keyID := config.GPGId()
-	gopassDir := filepath.Join(usr.HomeDir, ".gopass")
-	armoredKeyPath := filepath.Join(gopassDir, keyID+".secret.asc")
+	keyring, err := d.keyRing(ids)
+	if err != nil {
+		return "", err
+	}
 
-	// If armored key does not exist, export it
-	if _, err := os.Stat(armoredKeyPath); os.IsNotExist(err) {
-		if err := ExportArmoredPrivateKey(keyID, armoredKeyPath, passphrase); err != nil {
-			return "", fmt.Errorf("failed to export armored private key: %w", err)
+	plain, err := keyring.Decrypt(crypto.NewPGPMessage(ciphertext), nil, 0)
+	if err != nil {
+		return "", fmt.Errorf("decryption failed: %w", err)
+	}
+
+	return string(plain.GetBinary()), nil
+}
+
+// Close clears the unlocked keys.
+func (d *Decrypter) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, key := range d.keys {
+		key.ClearPrivateParams()
+	}
+	d.keys = nil
+	d.keyrings = map[uint64]*crypto.KeyRing{}
+}
+
+// keyRing returns the unlocked key for a message encrypted to ids, unlocking
+// it on first use.
+func (d *Decrypter) keyRing(ids []uint64) (*crypto.KeyRing, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, id := range ids {
+		if keyring, ok := d.keyrings[id]; ok {
+			return keyring, nil
 		}
 	}
 
-	keyData, err := os.ReadFile(armoredKeyPath)
+	path, err := secretKeyFile(ids, d.passphrase)
 	if err != nil {
-		return "", fmt.Errorf("failed to read armored private key: %w", err)
+		return nil, err
 	}
+	return d.unlock(path)
+}
 
-	keyObj, err := crypto.NewKeyFromArmored(string(keyData))
+// unlock unlocks the armored secret key of path, d.mu is held.
+func (d *Decrypter) unlock(path string) (*crypto.KeyRing, error) {
+	key, err := readArmoredKey(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse armored private key: %w", err)
+		return nil, err
+	}
+	if keyring, ok := d.keyrings[key.GetEntity().PrimaryKey.KeyId]; ok {
+		return keyring, nil
 	}
 
-	unlockedKey, err := keyObj.Unlock([]byte(passphrase))
+	unlockedKey, err := key.Unlock([]byte(d.passphrase))
 	if err != nil {
-		return "", fmt.Errorf("failed to unlock key: %w", err)
+		return nil, fmt.Errorf("failed to unlock key: %w", err)
+	}
+	keyring, err := crypto.NewKeyRing(unlockedKey)
+	if err != nil {
+		unlockedKey.ClearPrivateParams()
+		return nil, fmt.Errorf("failed to create keyring: %w", err)
 	}
 
-	defer keyObj.ClearPrivateParams()
+	d.keys = append(d.keys, unlockedKey)
+	for _, id := range keyIDs(key) {
+		d.keyrings[id] = keyring
+	}
+	return keyring, nil
+}
 
-	ciphertext, err := os.ReadFile(gpgFile)
+// secretKeyFile returns the armored secret key for a message encrypted to
+// ids, one already exported to ~/.gopass first, else it is exported from the
+// keyring. Any key the user owns may be the one, e.g. of a mounted store or
+// a folder with its own .gpg-id.
+func secretKeyFile(ids []uint64, passphrase string) (string, error) {
+	gopassDir, err := gopassDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to read gpg file: %w", err)
+		return "", err
 	}
 
-	message := crypto.NewPGPMessage(ciphertext)
+	exported, _ := filepath.Glob(filepath.Join(gopassDir, "*.secret.asc"))
+	for _, path := range exported {
+		if key, err := readArmoredKey(path); err == nil && hasKeyID(keyIDs(key), ids) {
+			return path, nil
+		}
+	}
 
-	keyring, err := crypto.NewKeyRing(unlockedKey)
+	keyID, err := secretKeyOf(ids)
 	if err != nil {
-		return "", fmt.Errorf("failed to create keyring: %w", err)
+		return "", err
+	}
+	path := filepath.Join(gopassDir, keyID+".secret.asc")
+	if err := ExportArmoredPrivateKey(keyID, path, passphrase); err != nil {
+		return "", fmt.Errorf("failed to export armored private key: %w", err)
 	}
+	return path, nil
+}
 
-	plain, err := keyring.Decrypt(message, nil, 0)
+// recipientKeyFile returns the armored secret key of a recipient of a
+// .gpg-id, exported from the keyring when it is not yet. It is false when the
+// user does not own the key.
+func recipientKeyFile(recipient, passphrase string) (string, bool, error) {
+	gopassDir, err := gopassDir()
 	if err != nil {
-		return "", fmt.Errorf("decryption failed: %w", err)
+		return "", false, err
 	}
 
-	return string(plain.GetBinary()), nil
+	path := filepath.Join(gopassDir, recipient+".secret.asc")
+	if _, err := os.Stat(path); err == nil {
+		return path, true, nil
+	}
+	if !HasSecretKey(recipient) {
+		return "", false, nil
+	}
+	if err := ExportArmoredPrivateKey(recipient, path, passphrase); err != nil {
+		return "", false, fmt.Errorf("failed to export armored private key: %w", err)
+	}
+	return path, true, nil
+}
+
+// secretKeyOf returns the key ID of the secret key of the keyring whose
+// primary key or a subkey is one of ids.
+func secretKeyOf(ids []uint64) (string, error) {
+	out, err := exec.Command("gpg", "--list-secret-keys", "--with-colons", "--fixed-list-mode").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list secret keys: %w", err)
+	}
+
+	var primary string
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 5 || (fields[0] != "sec" && fields[0] != "ssb") {
+			continue
+		}
+		if fields[0] == "sec" {
+			primary = fields[4]
+		}
+		if id, err := strconv.ParseUint(fields[4], 16, 64); err == nil && hasKeyID([]uint64{id}, ids) {
+			return primary, nil
+		}
+	}
+	return "", fmt.Errorf("no secret key for the message, it is encrypted to %s", formatKeyIDs(ids))
+}
+
+func readArmoredKey(path string) (*crypto.Key, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read armored private key: %w", err)
+	}
+	key, err := crypto.NewKeyFromArmored(string(keyData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse armored private key: %w", err)
+	}
+	return key, nil
+}
+
+// keyIDs returns the key IDs of the primary key and the subkeys of key.
+func keyIDs(key *crypto.Key) []uint64 {
+	entity := key.GetEntity()
+	ids := []uint64{entity.PrimaryKey.KeyId}
+	for _, subkey := range entity.Subkeys {
+		ids = append(ids, subkey.PublicKey.KeyId)
+	}
+	return ids
+}
+
+func hasKeyID(keyIDs, ids []uint64) bool {
+	for _, keyID := range keyIDs {
+		for _, id := range ids {
+			if keyID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatKeyIDs(ids []uint64) string {
+	formatted := make([]string, len(ids))
+	for i, id := range ids {
+		formatted[i] = fmt.Sprintf("%016X", id)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func gopassDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gopass"), nil
 }
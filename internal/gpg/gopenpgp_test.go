@@ -0,0 +1,47 @@
+package gpg
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func TestSecretKeyOf(t *testing.T) {
+	fingerprint := setupSigningKey(t)
+
+	public, err := exec.Command("gpg", "--export", fingerprint).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.NewKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := keyring.Encrypt(crypto.NewPlainMessageFromString("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := MessageRecipients(message.GetBinary())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The message is encrypted to the subkey, the primary key is exported
+	keyID, err := secretKeyOf(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fingerprint[len(fingerprint)-16:]; keyID != want {
+		t.Errorf("expected key %s, got %s", want, keyID)
+	}
+
+	if _, err := secretKeyOf([]uint64{0x1234}); err == nil || !strings.Contains(err.Error(), "0000000000001234") {
+		t.Errorf("expected an error naming the key of the message, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package gpg
 
 import (
+	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
 )
 
 // HasPublicKey returns true if the given keyID is present in the user's public keyring.
@@ -14,3 +19,149 @@ func HasPublicKey(keyID string) bool {
 	}
 	return strings.Contains(string(out), keyID)
 }
+
+// HasSecretKey returns true if the secret key of keyID, a key id, fingerprint
+// or user id, is in the user's keyring.
+func HasSecretKey(keyID string) bool {
+	out, err := exec.Command("gpg", "--list-secret-keys", "--with-colons", keyID).Output()
+	return err == nil && strings.Contains(string(out), "sec:")
+}
+
+// KeyInfo describes a key of the keyring.
+type KeyInfo struct {
+	KeyID       string
+	Fingerprint string
+	UIDs        []string
+	// Expires is zero for a key that does not expire
+	Expires time.Time
+	// Validity is the gpg validity letter, e.g. "u" ultimate, "f" full,
+	// "e" expired or "r" revoked
+	Validity string
+}
+
+// Usable reports whether the key is neither expired nor revoked.
+func (k KeyInfo) Usable() bool {
+	if k.Validity == "e" || k.Validity == "r" {
+		return false
+	}
+	return k.Expires.IsZero() || k.Expires.After(time.Now())
+}
+
+// Trust describes the validity of the key as gpg computes it from the trust
+// of the keyring.
+func (k KeyInfo) Trust() string {
+	switch k.Validity {
+	case "u":
+		return "ultimate"
+	case "f":
+		return "full"
+	case "m":
+		return "marginal"
+	case "n":
+		return "never"
+	case "e":
+		return "expired"
+	case "r":
+		return "revoked"
+	case "d":
+		return "disabled"
+	case "i":
+		return "invalid"
+	}
+	return "unknown"
+}
+
+// String returns the key id with its first user id, e.g. for a picker.
+func (k KeyInfo) String() string {
+	if len(k.UIDs) == 0 {
+		return k.KeyID
+	}
+	return fmt.Sprintf("%s %s", k.KeyID, k.UIDs[0])
+}
+
+// ListSecretKeys returns the keys of the keyring with a secret key, the ones
+// a store can be encrypted for and decrypted with.
+func ListSecretKeys() ([]KeyInfo, error) {
+	out, err := exec.Command("gpg", "--list-secret-keys", "--with-colons", "--fixed-list-mode").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secret keys: %w", err)
+	}
+	return parseKeyList(string(out), "sec"), nil
+}
+
+// PublicKey returns the public key id, a key id, fingerprint or user id, of
+// the keyring. It is false when the keyring has no such key.
+func PublicKey(id string) (KeyInfo, bool) {
+	out, err := exec.Command("gpg", "--list-keys", "--with-colons", "--fixed-list-mode", id).Output()
+	if err != nil {
+		return KeyInfo{}, false
+	}
+	keys := parseKeyList(string(out), "pub")
+	if len(keys) == 0 {
+		return KeyInfo{}, false
+	}
+	return keys[0], true
+}
+
+// ImportPublicKey adds a public key, armored or binary, to the keyring and
+// returns it with its armored form. Secret keys are refused.
+func ImportPublicKey(data []byte) (KeyInfo, string, error) {
+	key, err := crypto.NewKeyFromArmored(string(data))
+	if err != nil {
+		if key, err = crypto.NewKey(data); err != nil {
+			return KeyInfo{}, "", fmt.Errorf("not an OpenPGP public key: %w", err)
+		}
+	}
+	if key.IsPrivate() {
+		return KeyInfo{}, "", fmt.Errorf("this is a secret key, import the public key only")
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		return KeyInfo{}, "", fmt.Errorf("failed to armor public key: %w", err)
+	}
+
+	cmd := exec.Command("gpg", "--batch", "--import")
+	cmd.Stdin = strings.NewReader(armored)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return KeyInfo{}, "", fmt.Errorf("gpg import failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	fingerprint := strings.ToUpper(key.GetFingerprint())
+	info, ok := PublicKey(fingerprint)
+	if !ok {
+		return KeyInfo{}, "", fmt.Errorf("key %s not found after import", fingerprint)
+	}
+	return info, armored, nil
+}
+
+// parseKeyList reads the `gpg --with-colons` listing of the primary keys of
+// the given record type, "pub" or "sec".
+func parseKeyList(out, record string) []KeyInfo {
+	var keys []KeyInfo
+	var current *KeyInfo
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 10 {
+			continue
+		}
+		switch fields[0] {
+		case record:
+			keys = append(keys, KeyInfo{KeyID: fields[4], Validity: fields[1]})
+			current = &keys[len(keys)-1]
+			if secs, err := strconv.ParseInt(fields[6], 10, 64); err == nil && secs > 0 {
+				current.Expires = time.Unix(secs, 0)
+			}
+		case "fpr":
+			// The first fingerprint after the primary key is its own
+			if current != nil && current.Fingerprint == "" {
+				current.Fingerprint = fields[9]
+			}
+		case "uid":
+			if current != nil {
+				current.UIDs = append(current.UIDs, strings.ReplaceAll(fields[9], `\x3a`, ":"))
+			}
+		case "pub", "sec":
+			current = nil
+		}
+	}
+	return keys
+}
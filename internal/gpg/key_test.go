@@ -0,0 +1,78 @@
+package gpg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func TestParseKeyList(t *testing.T) {
+	out := `sec:u:3072:1:8699E9E85AEED7F5:1792433202:::u:::scESC:::+:::23::0:
+fpr:::::::::C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5:
+grp:::::::::6E014FB10AB21FFE7AD3E4B5A5A4B758D455FF25:
+uid:u::::1792433202::83492BC24327BB922F60542D1DDCD6EE64D97546::T <t@example.com>::::::::::0:
+ssb:u:3072:1:FC3A01ED98950005:1792433202::::::e:::+:::23:
+fpr:::::::::45CC3B978D4B7EAA8F8923E0FC3A01ED98950005:
+sec:e:255:22:1111222233334444:1600000000:1700000000::u:::scESC:::+:::ed25519::0:
+fpr:::::::::AAAABBBBCCCCDDDDEEEEFFFF1111222233334444:
+uid:e::::1600000000::HASH::Old \x3a key <old@example.com>::::::::::0:
+`
+	keys := parseKeyList(out, "sec")
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %+v", keys)
+	}
+
+	first := keys[0]
+	if first.KeyID != "8699E9E85AEED7F5" || first.Fingerprint != "C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5" {
+		t.Errorf("unexpected primary key %+v", first)
+	}
+	if len(first.UIDs) != 1 || first.UIDs[0] != "T <t@example.com>" || !first.Expires.IsZero() || !first.Usable() {
+		t.Errorf("unexpected key details %+v", first)
+	}
+
+	second := keys[1]
+	if second.UIDs[0] != "Old : key <old@example.com>" || !second.Expires.Equal(time.Unix(1700000000, 0)) || second.Usable() {
+		t.Errorf("unexpected expired key %+v", second)
+	}
+
+	if keys := parseKeyList(out, "pub"); len(keys) != 0 {
+		t.Errorf("expected no public key records, got %+v", keys)
+	}
+}
+
+func TestImportPublicKey(t *testing.T) {
+	if !CheckGPGAvailable() {
+		t.Skip("gpg is not installed")
+	}
+	t.Setenv("GNUPGHOME", t.TempDir())
+
+	key, err := crypto.GenerateKey("Teammate", "mate@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored, err := key.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, saved, err := ImportPublicKey([]byte(armored))
+	if err != nil {
+		t.Fatalf("ImportPublicKey failed: %v", err)
+	}
+	if info.Fingerprint != strings.ToUpper(key.GetFingerprint()) || len(info.UIDs) != 1 || info.UIDs[0] != "Teammate <mate@example.com>" {
+		t.Errorf("unexpected key %+v", info)
+	}
+	if !strings.HasPrefix(saved, "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+		t.Errorf("expected an armored key, got %q", saved)
+	}
+	if _, ok := PublicKey("mate@example.com"); !ok {
+		t.Error("expected the key in the keyring")
+	}
+
+	secret, _ := key.Armor()
+	if _, _, err := ImportPublicKey([]byte(secret)); err == nil {
+		t.Error("expected a secret key to be refused")
+	}
+}
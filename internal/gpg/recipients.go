@@ -0,0 +1,49 @@
+package gpg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// ErrNoRecipients is returned for data that is not an OpenPGP message
+// encrypted to a public key.
+var ErrNoRecipients = errors.New("not an OpenPGP message encrypted to a public key")
+
+// MessageRecipients returns the key IDs an encrypted message, binary or
+// armored, is encrypted to. It does not need the private key.
+func MessageRecipients(ciphertext []byte) ([]uint64, error) {
+	msg := crypto.NewPGPMessage(ciphertext)
+	if bytes.HasPrefix(bytes.TrimSpace(ciphertext), []byte("-----BEGIN PGP MESSAGE-----")) {
+		var err error
+		if msg, err = crypto.NewPGPMessageFromArmored(string(ciphertext)); err != nil {
+			return nil, fmt.Errorf("failed to parse armored message: %w", err)
+		}
+	}
+
+	ids, ok := msg.GetEncryptionKeyIDs()
+	if !ok || len(ids) == 0 {
+		return nil, ErrNoRecipients
+	}
+	return ids, nil
+}
+
+// PublicKeyIDs returns the key IDs of the primary keys and the subkeys of the
+// public keys of a .gpg-id, a message for them is encrypted to some of them.
+func PublicKeyIDs(keyID string) ([]uint64, error) {
+	var ids []uint64
+	for _, recipient := range ParseRecipients(keyID) {
+		armored, err := LoadArmoredPublicKey(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load armored public key: %w", err)
+		}
+		key, err := crypto.NewKeyFromArmored(armored)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse armored public key: %w", err)
+		}
+		ids = append(ids, keyIDs(key)...)
+	}
+	return ids, nil
+}
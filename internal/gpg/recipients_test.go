@@ -0,0 +1,51 @@
+package gpg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func TestMessageRecipients(t *testing.T) {
+	key, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	keyRing, err := crypto.NewKeyRing(key)
+	if err != nil {
+		t.Fatalf("NewKeyRing failed: %v", err)
+	}
+	encrypted, err := keyRing.Encrypt(crypto.NewPlainMessageFromString("secret"), nil)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	entity := key.GetEntity()
+	want := map[uint64]bool{entity.PrimaryKey.KeyId: true}
+	for _, subkey := range entity.Subkeys {
+		want[subkey.PublicKey.KeyId] = true
+	}
+
+	armored, _ := encrypted.GetArmored()
+	for _, data := range [][]byte{encrypted.GetBinary(), []byte(armored)} {
+		ids, err := MessageRecipients(data)
+		if err != nil {
+			t.Fatalf("MessageRecipients failed: %v", err)
+		}
+		if len(ids) != 1 || !want[ids[0]] {
+			t.Errorf("unexpected recipients %X", ids)
+		}
+	}
+
+	if _, err := MessageRecipients([]byte("user: me\npassword: plaintext\n")); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("expected ErrNoRecipients for plaintext, got %v", err)
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	got := ParseRecipients("# team\nALICE1234\n\n  bob@example.com \n")
+	if len(got) != 2 || got[0] != "ALICE1234" || got[1] != "bob@example.com" {
+		t.Errorf("unexpected recipients %q", got)
+	}
+}
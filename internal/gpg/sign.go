@@ -0,0 +1,122 @@
+package gpg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/config"
+)
+
+// SignatureSuffix is appended to a .gpg-id for its detached signature, like
+// pass.
+const SignatureSuffix = ".sig"
+
+// SignGPGId writes the detached signature of the .gpg-id file with the
+// signing keys of the configuration the keyring has a secret key for. It does
+// nothing when no signing key is configured. An empty passphrase leaves it to
+// the gpg agent.
+func SignGPGId(file, passphrase string) error {
+	signers, err := signingKeys()
+	if err != nil || len(signers) == 0 {
+		return err
+	}
+
+	args := []string{"--batch", "--yes", "--detach-sign", "--output", file + SignatureSuffix}
+	for _, key := range signers {
+		if HasSecretKey(key) {
+			args = append(args, "--local-user", key)
+		}
+	}
+	if !slices.Contains(args, "--local-user") {
+		return fmt.Errorf("cannot sign %s, no secret key for the signing keys %s", file, strings.Join(signers, ", "))
+	}
+	if passphrase != "" {
+		args = append(args, "--pinentry-mode", "loopback", "--passphrase-fd", "0")
+	}
+	cmd := exec.Command("gpg", append(args, file)...)
+	cmd.Stdin = strings.NewReader(passphrase)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to sign %s: %w: %s", file, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// VerifyGPGId checks that the .gpg-id file is signed by one of the signing
+// keys of the configuration. Without signing keys every .gpg-id is accepted.
+func VerifyGPGId(file string) error {
+	signers, err := signingKeys()
+	if err != nil || len(signers) == 0 {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return verifyGPGId(file, data, signers)
+}
+
+// signingKeys returns the signing keys of the configuration, or why they are
+// unknown, see config.SigningKeysErr.
+func signingKeys() ([]string, error) {
+	if err := config.SigningKeysErr(); err != nil {
+		return nil, err
+	}
+	return config.SigningKeys(), nil
+}
+
+// verifyGPGId checks the signature of data, the content of the .gpg-id file,
+// so the keys encrypted for are the ones verified.
+func verifyGPGId(file string, data []byte, signers []string) error {
+	if len(signers) == 0 {
+		return nil
+	}
+	sig := file + SignatureSuffix
+	if _, err := os.Stat(sig); err != nil {
+		return fmt.Errorf("%s is not signed", file)
+	}
+
+	cmd := exec.Command("gpg", "--batch", "--status-fd", "1", "--verify", sig, "-")
+	cmd.Stdin = bytes.NewReader(data)
+	// gpg exits with an error for a bad signature, the status tells why
+	out, _ := cmd.Output()
+	signer, err := signatureSigner(string(out))
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if !slices.Contains(signers, signer) {
+		return fmt.Errorf("%s is signed by %s, which is not a signing key", file, signer)
+	}
+	return nil
+}
+
+// signatureSigner returns the primary key fingerprint of a good signature
+// from the `gpg --status-fd` output of a verification.
+func signatureSigner(status string) (string, error) {
+	var signer string
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, "[GNUPG:] "))
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "VALIDSIG":
+			// The primary key fingerprint comes last, the first field is the
+			// fingerprint of the subkey that signed
+			signer = strings.ToUpper(fields[len(fields)-1])
+		case "REVKEYSIG":
+			return "", fmt.Errorf("the signing key %s is revoked", fields[1])
+		case "NO_PUBKEY":
+			return "", fmt.Errorf("signed by %s, a key not in the keyring", fields[1])
+		case "BADSIG":
+			return "", fmt.Errorf("the signature does not match, the file changed since it was signed")
+		}
+	}
+	if signer == "" {
+		return "", fmt.Errorf("no valid signature")
+	}
+	return signer, nil
+}
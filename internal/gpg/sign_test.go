@@ -0,0 +1,111 @@
+package gpg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/duykhoa/gopass/internal/config"
+)
+
+func TestSignatureSigner(t *testing.T) {
+	good := `[GNUPG:] NEWSIG
+[GNUPG:] GOODSIG 8699E9E85AEED7F5 T <t@example.com>
+[GNUPG:] VALIDSIG 45CC3B978D4B7EAA8F8923E0FC3A01ED98950005 2026-10-19 1792433202 0 4 0 1 10 00 C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5
+[GNUPG:] TRUST_ULTIMATE 0 pgp
+`
+	if signer, err := signatureSigner(good); err != nil || signer != "C363EF9AAF1BF3E03AE528D28699E9E85AEED7F5" {
+		t.Errorf("expected the primary key fingerprint, got %q, %v", signer, err)
+	}
+
+	for status, want := range map[string]string{
+		"[GNUPG:] ERRSIG 1111222233334444 1 10 00 1792433202 9\n[GNUPG:] NO_PUBKEY 1111222233334444\n": "not in the keyring",
+		"[GNUPG:] BADSIG 8699E9E85AEED7F5 T <t@example.com>\n":                                         "does not match",
+		"": "no valid signature",
+	} {
+		if _, err := signatureSigner(status); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error containing %q for %q, got %v", want, status, err)
+		}
+	}
+}
+
+// setupSigningKey imports a new secret key without passphrase into a
+// temporary keyring and returns its fingerprint.
+func setupSigningKey(t *testing.T) string {
+	t.Helper()
+	if !CheckGPGAvailable() {
+		t.Skip("gpg is not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("GNUPGHOME", home)
+	t.Setenv("GOPASS_CONFIG", filepath.Join(home, "config.toml"))
+	t.Cleanup(func() { config.Reload() })
+
+	key, err := crypto.GenerateKey("Admin", "admin@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := key.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("gpg", "--batch", "--import")
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gpg import failed: %v: %s", err, out)
+	}
+	return strings.ToUpper(key.GetFingerprint())
+}
+
+func TestSignAndVerifyGPGId(t *testing.T) {
+	fingerprint := setupSigningKey(t)
+	gpgId := filepath.Join(t.TempDir(), ".gpg-id")
+	os.WriteFile(gpgId, []byte("admin@example.com\n"), 0600)
+
+	// Without signing keys nothing is signed nor checked
+	t.Setenv("PASSWORD_STORE_SIGNING_KEY", "")
+	config.Reload()
+	if err := SignGPGId(gpgId, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(gpgId + SignatureSuffix); !os.IsNotExist(err) {
+		t.Error("expected no signature without signing keys")
+	}
+	if err := VerifyGPGId(gpgId); err != nil {
+		t.Errorf("expected any .gpg-id to be accepted without signing keys, got %v", err)
+	}
+
+	t.Setenv("PASSWORD_STORE_SIGNING_KEY", fingerprint)
+	config.Reload()
+	if err := VerifyGPGId(gpgId); err == nil || !strings.Contains(err.Error(), "is not signed") {
+		t.Errorf("expected an unsigned .gpg-id to be refused, got %v", err)
+	}
+	if _, err := Encrypt([]byte("secret"), Recipients{GPGId: gpgId}); err == nil || !strings.Contains(err.Error(), "is not signed") {
+		t.Errorf("expected Encrypt to refuse an unsigned .gpg-id, got %v", err)
+	}
+
+	if err := SignGPGId(gpgId, ""); err != nil {
+		t.Fatalf("SignGPGId failed: %v", err)
+	}
+	if err := VerifyGPGId(gpgId); err != nil {
+		t.Errorf("expected the signed .gpg-id to be accepted, got %v", err)
+	}
+
+	os.WriteFile(gpgId, []byte("admin@example.com\nintruder@example.com\n"), 0600)
+	if err := VerifyGPGId(gpgId); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected a changed .gpg-id to be refused, got %v", err)
+	}
+
+	t.Setenv("PASSWORD_STORE_SIGNING_KEY", strings.Repeat("A", 40))
+	config.Reload()
+	os.WriteFile(gpgId, []byte("admin@example.com\n"), 0600)
+	if err := VerifyGPGId(gpgId); err == nil || !strings.Contains(err.Error(), "not a signing key") {
+		t.Errorf("expected a signature by another key to be refused, got %v", err)
+	}
+	if err := SignGPGId(gpgId, ""); err == nil {
+		t.Error("expected an error signing without the secret signing key")
+	}
+}
@@ -0,0 +1,34 @@
+package gpg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// EncryptWithPassphrase encrypts the content with a passphrase (OpenPGP
+// symmetric encryption, the key is derived with S2K) and returns the binary
+// ciphertext, readable with `gpg --decrypt`.
+func EncryptWithPassphrase(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	encrypted, err := crypto.EncryptMessageWithPassword(crypto.NewPlainMessage(plaintext), []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("symmetric encryption failed: %w", err)
+	}
+
+	return encrypted.GetBinary(), nil
+}
+
+// DecryptWithPassphrase decrypts content encrypted by EncryptWithPassphrase.
+// It fails when the passphrase is wrong or the content was modified.
+func DecryptWithPassphrase(ciphertext []byte, passphrase string) ([]byte, error) {
+	plain, err := crypto.DecryptMessageWithPassword(crypto.NewPGPMessage(ciphertext), []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("symmetric decryption failed: %w", err)
+	}
+
+	return plain.GetBinary(), nil
+}
@@ -0,0 +1,30 @@
+package gpg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptWithPassphraseRoundTrip(t *testing.T) {
+	plaintext := []byte("backup bundle")
+
+	ciphertext, err := EncryptWithPassphrase(plaintext, "correct horse")
+	if err != nil {
+		t.Fatalf("EncryptWithPassphrase failed: %v", err)
+	}
+	got, err := DecryptWithPassphrase(ciphertext, "correct horse")
+	if err != nil {
+		t.Fatalf("DecryptWithPassphrase failed: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, got)
+	}
+
+	if _, err := DecryptWithPassphrase(ciphertext, "wrong"); err == nil {
+		t.Error("expected an error for a wrong passphrase")
+	}
+	ciphertext[len(ciphertext)-5] ^= 0xff
+	if _, err := DecryptWithPassphrase(ciphertext, "correct horse"); err == nil {
+		t.Error("expected an error for modified ciphertext")
+	}
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []struct {
+		FolderID string `json:"folderId"`
+		Name     string `json:"name"`
+		Notes    string `json:"notes"`
+		Favorite bool   `json:"favorite"`
+		Login    *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			TOTP     string `json:"totp"`
+			URIs     []struct {
+				URI string `json:"uri"`
+			} `json:"uris"`
+		} `json:"login"`
+		Card     map[string]any `json:"card"`
+		Identity map[string]any `json:"identity"`
+		Fields   []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"items"`
+}
+
+func parseBitwarden(data []byte) ([]Record, error) {
+	var export bitwardenExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+	if export.Encrypted {
+		return nil, fmt.Errorf("encrypted exports are not supported, export as unencrypted JSON")
+	}
+
+	folders := map[string]string{}
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	records := make([]Record, 0, len(export.Items))
+	for _, item := range export.Items {
+		r := Record{
+			Folder:   folders[item.FolderID],
+			Name:     item.Name,
+			Notes:    item.Notes,
+			Favorite: item.Favorite,
+		}
+		if item.Login != nil {
+			r.Username = item.Login.Username
+			r.Password = item.Login.Password
+			r.OTP = item.Login.TOTP
+			for i, uri := range item.Login.URIs {
+				if i == 0 {
+					r.URL = uri.URI
+				} else {
+					r.Fields = append(r.Fields, service.Field{Name: "url", Value: uri.URI})
+				}
+			}
+		}
+		r.Fields = append(r.Fields, flattenFields(item.Card)...)
+		r.Fields = append(r.Fields, flattenFields(item.Identity)...)
+		for _, f := range item.Fields {
+			r.Fields = append(r.Fields, service.Field{Name: f.Name, Value: f.Value})
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
+
+// flattenFields turns the card and identity objects into fields, in name
+// order, skipping empty values.
+func flattenFields(values map[string]any) []service.Field {
+	var names []string
+	for name, value := range values {
+		if s, ok := value.(string); ok && s != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fields := make([]service.Field, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, service.Field{Name: name, Value: values[name].(string)})
+	}
+	return fields
+}
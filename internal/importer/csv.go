@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// csvColumns maps the header names used by LastPass, Chrome, Firefox,
+// 1Password and Bitwarden CSV exports onto the record fields.
+var csvColumns = map[string][]string{
+	"folder":   {"grouping", "folder", "vault", "group"},
+	"name":     {"name", "title"},
+	"url":      {"url", "website", "login_uri", "location", "urls"},
+	"username": {"username", "login_username", "login", "user", "email"},
+	"password": {"password", "login_password"},
+	"otp":      {"totp", "otpauth", "login_totp", "one-time password"},
+	"notes":    {"extra", "notes", "note", "notesplain"},
+	"tags":     {"tags"},
+	"favorite": {"fav", "favorite"},
+}
+
+// lastPassNoteURL marks LastPass secure notes.
+const lastPassNoteURL = "http://sn"
+
+// parseCSV reads a CSV export with a header row. Columns are matched by
+// name, so the exports of the supported tools share one parser.
+func parseCSV(data []byte) ([]Record, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	header := map[string]int{}
+	for i, name := range rows[0] {
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	column := map[string]int{}
+	for field, aliases := range csvColumns {
+		column[field] = -1
+		for _, alias := range aliases {
+			if i, ok := header[alias]; ok {
+				column[field] = i
+				break
+			}
+		}
+	}
+	if column["name"] < 0 && column["url"] < 0 {
+		return nil, fmt.Errorf("header has neither a name nor a url column")
+	}
+
+	var records []Record
+	for _, row := range rows[1:] {
+		get := func(field string) string {
+			if i := column[field]; i >= 0 && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		r := Record{
+			Folder:   strings.ReplaceAll(get("folder"), "\\", "/"),
+			Name:     get("name"),
+			URL:      get("url"),
+			Username: get("username"),
+			Password: get("password"),
+			OTP:      get("otp"),
+			Notes:    get("notes"),
+		}
+		if r.URL == lastPassNoteURL {
+			r.URL = ""
+		}
+		for _, tag := range strings.FieldsFunc(get("tags"), func(c rune) bool { return c == ',' || c == ';' }) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				r.Tags = append(r.Tags, tag)
+			}
+		}
+		r.Favorite, _ = strconv.ParseBool(get("favorite"))
+		if r.Name == "" && r.URL == "" && r.Username == "" && r.Password == "" && r.Notes == "" {
+			continue
+		}
+		records = append(records, r)
+	}
+
+	return records, nil
+}
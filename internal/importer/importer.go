@@ -0,0 +1,306 @@
+// Package importer converts the exports of other password managers into
+// entries of the password store.
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+// Record is an item read from an export, before it is mapped onto a template.
+type Record struct {
+	Folder   string
+	Name     string
+	URL      string
+	Username string
+	Password string
+	OTP      string
+	Notes    string
+	// Fields are the custom fields of the item, kept in the extra field
+	Fields   []service.Field
+	Tags     []string
+	Favorite bool
+}
+
+// Format is a supported export format.
+type Format struct {
+	Name        string
+	Description string
+	parse       func(data []byte) ([]Record, error)
+}
+
+var Formats = []Format{
+	{Name: "bitwarden", Description: "Bitwarden JSON export (unencrypted)", parse: parseBitwarden},
+	{Name: "1pux", Description: "1Password 1PUX export", parse: parse1PUX},
+	{Name: "1password-csv", Description: "1Password CSV export", parse: parseCSV},
+	{Name: "keepass", Description: "KeePass 2 XML export", parse: parseKeePass},
+	{Name: "lastpass", Description: "LastPass CSV export", parse: parseCSV},
+	{Name: "chrome", Description: "Chrome passwords CSV export", parse: parseCSV},
+	{Name: "firefox", Description: "Firefox logins CSV export", parse: parseCSV},
+}
+
+func GetFormatByName(name string) *Format {
+	for _, f := range Formats {
+		if f.Name == name {
+			return &f
+		}
+	}
+	return nil
+}
+
+// Parse reads the records of an export in the named format.
+func Parse(format string, data []byte) ([]Record, error) {
+	f := GetFormatByName(format)
+	if f == nil {
+		return nil, fmt.Errorf("unknown import format: %s", format)
+	}
+	records, err := f.parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s export: %w", f.Name, err)
+	}
+	return records, nil
+}
+
+// Strategy decides what happens when an imported entry already exists.
+type Strategy string
+
+const (
+	StrategySkip      Strategy = "skip"
+	StrategyOverwrite Strategy = "overwrite"
+	StrategySuffix    Strategy = "suffix"
+)
+
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionSkip      Action = "skip"
+	ActionOverwrite Action = "overwrite"
+	ActionRename    Action = "rename"
+)
+
+// PlanItem is what will happen to a record. Conflict is set when the entry
+// already exists in the store or an earlier record of the export has the
+// same name.
+type PlanItem struct {
+	Record   Record
+	Entry    string
+	Action   Action
+	Conflict bool
+	Err      error
+}
+
+type Plan struct {
+	Items []PlanItem
+}
+
+// Count returns the number of items with the given action.
+func (p Plan) Count(action Action) int {
+	n := 0
+	for _, item := range p.Items {
+		if item.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Conflicts returns the items whose entry name was already taken.
+func (p Plan) Conflicts() []PlanItem {
+	var conflicts []PlanItem
+	for _, item := range p.Items {
+		if item.Conflict {
+			conflicts = append(conflicts, item)
+		}
+	}
+	return conflicts
+}
+
+// NewPlan maps records onto entry names under prefix and resolves name
+// collisions with existing entries using the strategy.
+func NewPlan(records []Record, prefix string, existing []string, strategy Strategy) (Plan, error) {
+	switch strategy {
+	case StrategySkip, StrategyOverwrite, StrategySuffix:
+	case "":
+		strategy = StrategySkip
+	default:
+		return Plan{}, fmt.Errorf("unknown collision strategy: %s", strategy)
+	}
+
+	taken := map[string]bool{}
+	for _, entry := range existing {
+		taken[filepath.ToSlash(entry)] = true
+	}
+
+	plan := Plan{Items: make([]PlanItem, 0, len(records))}
+	for _, r := range records {
+		item := PlanItem{Record: r, Entry: entryName(prefix, r), Action: ActionCreate}
+		if taken[item.Entry] {
+			item.Conflict = true
+			switch strategy {
+			case StrategySkip:
+				item.Action = ActionSkip
+			case StrategyOverwrite:
+				item.Action = ActionOverwrite
+			case StrategySuffix:
+				item.Action = ActionRename
+				base := item.Entry
+				for i := 2; taken[item.Entry]; i++ {
+					item.Entry = fmt.Sprintf("%s-%d", base, i)
+				}
+			}
+		}
+		taken[item.Entry] = true
+		plan.Items = append(plan.Items, item)
+	}
+
+	return plan, nil
+}
+
+// entryName builds a safe entry path from the folder and name of a record.
+func entryName(prefix string, r Record) string {
+	name := r.Name
+	if strings.TrimSpace(name) == "" {
+		name = hostOf(r.URL)
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "untitled"
+	}
+
+	var segments []string
+	for _, part := range []string{prefix, r.Folder} {
+		for _, segment := range strings.FieldsFunc(part, func(c rune) bool { return c == '/' || c == '\\' }) {
+			segments = append(segments, cleanSegment(segment))
+		}
+	}
+	segments = append(segments, cleanSegment(name))
+
+	var kept []string
+	for _, segment := range segments {
+		if segment != "" && segment != "." && segment != ".." {
+			kept = append(kept, segment)
+		}
+	}
+	return path.Join(kept...)
+}
+
+func cleanSegment(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.NewReplacer("/", "-", "\\", "-", "\x00", "").Replace(s)
+	return strings.TrimLeft(s, ".")
+}
+
+func hostOf(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	return rawURL
+}
+
+// AddEditRequest maps the record onto the Email and password template, or
+// Free Form when the record has no credentials, e.g. a secure note.
+func (r Record) AddEditRequest(entry string) service.AddEditRequest {
+	var extra []string
+	if r.OTP != "" {
+		extra = append(extra, "otp: "+r.OTP)
+	}
+	for _, f := range r.Fields {
+		extra = append(extra, f.Name+": "+f.Value)
+	}
+	if r.Notes != "" {
+		extra = append(extra, r.Notes)
+	}
+
+	req := service.AddEditRequest{
+		EntryName: entry,
+		GPGId:     config.GPGId(),
+		Meta:      service.EntryMeta{Tags: r.Tags, Favorite: r.Favorite},
+	}
+	if r.Username == "" && r.Password == "" {
+		content := strings.Join(extra, "\n")
+		if r.URL != "" {
+			content = strings.TrimSpace("url: " + r.URL + "\n" + content)
+		}
+		req.TemplateName = service.TemplateFreeForm
+		req.Fields = map[string]string{"content": content}
+		return req
+	}
+
+	req.TemplateName = service.TemplateEmailAndPassword
+	req.Fields = map[string]string{
+		"domain":   r.URL,
+		"email":    r.Username,
+		"password": r.Password,
+		"extra":    strings.Join(extra, "\n"),
+	}
+	return req
+}
+
+type ImportRequest struct {
+	Format string
+	Data   []byte
+	// Prefix is the folder the entries are imported into, e.g. "imported"
+	Prefix   string
+	Strategy Strategy
+	// DryRun only returns the plan, nothing is written
+	DryRun bool
+}
+
+type ImportResult struct {
+	Plan     Plan
+	Imported int
+	Err      error
+}
+
+// Import parses the export, writes the planned entries and records them in a
+// single git commit. Entries failing to write are reported in their PlanItem
+// and do not stop the import.
+func Import(req ImportRequest) ImportResult {
+	records, err := Parse(req.Format, req.Data)
+	if err != nil {
+		return ImportResult{Err: err}
+	}
+
+	existing, err := service.ListEntries()
+	if err != nil && !os.IsNotExist(err) {
+		return ImportResult{Err: err}
+	}
+	plan, err := NewPlan(records, req.Prefix, existing, req.Strategy)
+	if err != nil {
+		return ImportResult{Err: err}
+	}
+	if req.DryRun {
+		return ImportResult{Plan: plan}
+	}
+
+	result := ImportResult{Plan: plan}
+	for i, item := range plan.Items {
+		if item.Action == ActionSkip {
+			continue
+		}
+		if res := service.AddOrEditEntry(item.Record.AddEditRequest(item.Entry)); res.Err != nil {
+			result.Plan.Items[i].Err = res.Err
+			continue
+		}
+		result.Imported++
+	}
+
+	if result.Imported > 0 {
+		message := fmt.Sprintf("Import %d entries from %s", result.Imported, req.Format)
+		if err := service.CommitStores(message); err != nil {
+			result.Err = fmt.Errorf("entries were imported but not committed: %w", err)
+		}
+	}
+
+	return result
+}
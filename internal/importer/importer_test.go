@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+func TestParseBitwarden(t *testing.T) {
+	data := `{"encrypted": false,
+	"folders": [{"id": "f1", "name": "Work"}],
+	"items": [
+		{"folderId": "f1", "name": "GitHub", "favorite": true, "notes": "2FA on",
+		 "login": {"username": "me", "password": "hunter2", "totp": "JBSW", "uris": [{"uri": "https://github.com"}]},
+		 "fields": [{"name": "pin", "value": "1234"}]},
+		{"name": "Wifi", "notes": "passphrase"}
+	]}`
+	records, err := Parse("bitwarden", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	r := records[0]
+	if r.Folder != "Work" || r.Username != "me" || r.Password != "hunter2" || r.URL != "https://github.com" || r.OTP != "JBSW" || !r.Favorite {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if len(r.Fields) != 1 || r.Fields[0].Name != "pin" {
+		t.Errorf("unexpected fields %v", r.Fields)
+	}
+}
+
+func TestParse1PUX(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, _ := archive.Create(onePUXDataFile)
+	w.Write([]byte(`{"accounts": [{"vaults": [{"attrs": {"name": "Private"}, "items": [
+		{"favIndex": 1, "overview": {"title": "AWS", "url": "https://aws.amazon.com", "tags": ["cloud"]},
+		 "details": {"loginFields": [{"designation": "username", "value": "root"}, {"designation": "password", "value": "s3cret"}],
+		  "sections": [{"fields": [{"title": "one-time password", "value": {"totp": "otpauth://totp/aws"}}]}]}},
+		{"state": "archived", "overview": {"title": "Old"}}
+	]}]}]}`))
+	archive.Close()
+
+	records, err := Parse("1pux", buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected archived items to be skipped, got %d records", len(records))
+	}
+	r := records[0]
+	if r.Folder != "Private" || r.Username != "root" || r.Password != "s3cret" || r.OTP != "otpauth://totp/aws" || !r.Favorite || len(r.Tags) != 1 {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
+
+func TestParseKeePass(t *testing.T) {
+	data := `<KeePassFile><Root><Group><Name>Database</Name>
+		<Group><Name>Email</Name>
+			<Entry><Tags>mail;personal</Tags>
+				<String><Key>Title</Key><Value>Gmail</Value></String>
+				<String><Key>UserName</Key><Value>me@gmail.com</Value></String>
+				<String><Key>Password</Key><Value ProtectInMemory="True">pw</Value></String>
+				<String><Key>Recovery</Key><Value>codes</Value></String>
+			</Entry>
+		</Group>
+		<Group><Name>Recycle Bin</Name><Entry><String><Key>Title</Key><Value>Deleted</Value></String></Entry></Group>
+	</Group></Root></KeePassFile>`
+	records, err := Parse("keepass", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected the recycle bin to be skipped, got %d records", len(records))
+	}
+	r := records[0]
+	if r.Folder != "Email" || r.Name != "Gmail" || r.Password != "pw" || len(r.Tags) != 2 || len(r.Fields) != 1 {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
+
+func TestParseCSVExports(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+		want   Record
+	}{
+		{
+			format: "lastpass",
+			data:   "url,username,password,totp,extra,name,grouping,fav\nhttps://example.com,me,pw,,note,Example,Work\\Web,1\n",
+			want:   Record{Folder: "Work/Web", Name: "Example", URL: "https://example.com", Username: "me", Password: "pw", Notes: "note", Favorite: true},
+		},
+		{
+			format: "chrome",
+			data:   "name,url,username,password,note\nexample.com,https://example.com/login,me,pw,\n",
+			want:   Record{Name: "example.com", URL: "https://example.com/login", Username: "me", Password: "pw"},
+		},
+		{
+			format: "firefox",
+			data:   "\"url\",\"username\",\"password\",\"httpRealm\",\"formActionOrigin\",\"guid\"\n\"https://example.com\",\"me\",\"pw\",,\"\",\"{1}\"\n",
+			want:   Record{URL: "https://example.com", Username: "me", Password: "pw"},
+		},
+		{
+			format: "1password-csv",
+			data:   "Title,Website,Username,Password,Notes,Tags\nBank,https://bank.com,me,pw,,finance;money\n",
+			want:   Record{Name: "Bank", URL: "https://bank.com", Username: "me", Password: "pw", Tags: []string{"finance", "money"}},
+		},
+	}
+
+	for _, tt := range tests {
+		records, err := Parse(tt.format, []byte(tt.data))
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", tt.format, err)
+		}
+		if len(records) != 1 {
+			t.Fatalf("%s: expected 1 record, got %d", tt.format, len(records))
+		}
+		r := records[0]
+		if r.Folder != tt.want.Folder || r.Name != tt.want.Name || r.URL != tt.want.URL || r.Username != tt.want.Username ||
+			r.Password != tt.want.Password || r.Notes != tt.want.Notes || r.Favorite != tt.want.Favorite || len(r.Tags) != len(tt.want.Tags) {
+			t.Errorf("%s: unexpected record %+v", tt.format, r)
+		}
+	}
+}
+
+func TestNewPlanStrategies(t *testing.T) {
+	records := []Record{
+		{Folder: "web", Name: "github"},
+		{Folder: "web", Name: "github"},
+		{URL: "https://example.com/login"},
+		{Folder: "../etc", Name: "passwd"},
+	}
+	existing := []string{"imported/web/github"}
+
+	plan, err := NewPlan(records, "imported", existing, StrategySuffix)
+	if err != nil {
+		t.Fatalf("NewPlan failed: %v", err)
+	}
+	want := []string{"imported/web/github-2", "imported/web/github-3", "imported/example.com", "imported/etc/passwd"}
+	for i, item := range plan.Items {
+		if item.Entry != want[i] {
+			t.Errorf("item %d: expected %s, got %s", i, want[i], item.Entry)
+		}
+	}
+	if len(plan.Conflicts()) != 2 || plan.Count(ActionRename) != 2 {
+		t.Errorf("unexpected conflicts %v", plan.Conflicts())
+	}
+
+	plan, _ = NewPlan(records, "imported", existing, StrategySkip)
+	if plan.Count(ActionSkip) != 2 || plan.Count(ActionCreate) != 2 {
+		t.Errorf("unexpected skip plan %+v", plan.Items)
+	}
+
+	if _, err := NewPlan(records, "", nil, "merge"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestRecordAddEditRequest(t *testing.T) {
+	r := Record{URL: "github.com", Username: "me", Password: "pw", OTP: "JBSW", Notes: "note", Tags: []string{"work"}}
+	req := r.AddEditRequest("web/github")
+	if req.TemplateName != service.TemplateEmailAndPassword || req.Fields["extra"] != "otp: JBSW\nnote" || req.Meta.Tags[0] != "work" {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	req = Record{Notes: "just a note"}.AddEditRequest("note")
+	if req.TemplateName != service.TemplateFreeForm || req.Fields["content"] != "just a note" {
+		t.Errorf("unexpected request %+v", req)
+	}
+}
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+// keePassRecycleBin is the group deleted entries are moved to.
+const keePassRecycleBin = "Recycle Bin"
+
+type keePassFile struct {
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	Tags    string `xml:"Tags"`
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+}
+
+func parseKeePass(data []byte) ([]Record, error) {
+	var file keePassFile
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	// The top level group is the database itself, it is not a folder
+	for _, root := range file.Root.Groups {
+		records = append(records, keePassRecords("", root.Entries)...)
+		for _, group := range root.Groups {
+			records = append(records, keePassGroupRecords("", group)...)
+		}
+	}
+	return records, nil
+}
+
+func keePassGroupRecords(parent string, group keePassGroup) []Record {
+	if group.Name == keePassRecycleBin {
+		return nil
+	}
+	folder := path.Join(parent, strings.ReplaceAll(group.Name, "/", "-"))
+	records := keePassRecords(folder, group.Entries)
+	for _, child := range group.Groups {
+		records = append(records, keePassGroupRecords(folder, child)...)
+	}
+	return records
+}
+
+func keePassRecords(folder string, entries []keePassEntry) []Record {
+	records := make([]Record, 0, len(entries))
+	for _, entry := range entries {
+		r := Record{Folder: folder}
+		for _, s := range entry.Strings {
+			switch s.Key {
+			case "Title":
+				r.Name = s.Value
+			case "UserName":
+				r.Username = s.Value
+			case "Password":
+				r.Password = s.Value
+			case "URL":
+				r.URL = s.Value
+			case "Notes":
+				r.Notes = s.Value
+			case "otp", "TimeOtp-Secret-Base32":
+				r.OTP = s.Value
+			default:
+				if s.Value != "" {
+					r.Fields = append(r.Fields, service.Field{Name: s.Key, Value: s.Value})
+				}
+			}
+		}
+		for _, tag := range strings.FieldsFunc(entry.Tags, func(c rune) bool { return c == ',' || c == ';' }) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				r.Tags = append(r.Tags, tag)
+			}
+		}
+		records = append(records, r)
+	}
+	return records
+}
@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+// onePUXDataFile is the JSON document inside the 1PUX zip archive.
+const onePUXDataFile = "export.data"
+
+type onePUXExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Attrs struct {
+				Name string `json:"name"`
+			} `json:"attrs"`
+			Items []onePUXItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePUXItem struct {
+	FavIndex int    `json:"favIndex"`
+	State    string `json:"state"`
+	Overview struct {
+		Title string   `json:"title"`
+		URL   string   `json:"url"`
+		Tags  []string `json:"tags"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Designation string `json:"designation"`
+			Name        string `json:"name"`
+			Value       string `json:"value"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				Title string                     `json:"title"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+func parse1PUX(data []byte) ([]Record, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	file, err := archive.Open(onePUXDataFile)
+	if err != nil {
+		return nil, fmt.Errorf("missing %s: %w", onePUXDataFile, err)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var export onePUXExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, item := range vault.Items {
+				if item.State == "archived" || item.State == "deleted" {
+					continue
+				}
+				records = append(records, onePUXRecord(vault.Attrs.Name, item))
+			}
+		}
+	}
+
+	return records, nil
+}
+
+func onePUXRecord(vault string, item onePUXItem) Record {
+	r := Record{
+		Folder:   vault,
+		Name:     item.Overview.Title,
+		URL:      item.Overview.URL,
+		Notes:    item.Details.NotesPlain,
+		Password: item.Details.Password,
+		Tags:     item.Overview.Tags,
+		Favorite: item.FavIndex > 0,
+	}
+	for _, f := range item.Details.LoginFields {
+		switch f.Designation {
+		case "username":
+			r.Username = f.Value
+		case "password":
+			r.Password = f.Value
+		}
+	}
+	for _, section := range item.Details.Sections {
+		for _, f := range section.Fields {
+			// Values are keyed by their type, e.g. {"string": "..."} or {"totp": "..."}
+			for kind, raw := range f.Value {
+				var value string
+				if json.Unmarshal(raw, &value) != nil || value == "" {
+					continue
+				}
+				if kind == "totp" && r.OTP == "" {
+					r.OTP = value
+					continue
+				}
+				r.Fields = append(r.Fields, service.Field{Name: f.Title, Value: value})
+			}
+		}
+	}
+	return r
+}
@@ -1,14 +1,19 @@
 package pico
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
 	"github.com/duykhoa/gopass/internal/service"
+	"github.com/duykhoa/gopass/internal/watcher"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -16,39 +21,184 @@ import (
 type MsgType string
 
 const (
-	MsgType_UpdateStatus        = "MsgTypeUpdateStatus"
-	MsgType_EntrySelected       = "MsgTypeEntrySelected"
-	MsgType_PassphraseSubmitted = "MsgTypePassphraseSubmitted"
+	MsgType_UpdateStatus          = "MsgTypeUpdateStatus"
+	MsgType_EntrySelected         = "MsgTypeEntrySelected"
+	MsgType_PassphraseSubmitted   = "MsgTypePassphraseSubmitted"
+	MsgType_StoreChanged          = "MsgTypeStoreChanged"
+	MsgType_FilterChanged         = "MsgTypeFilterChanged"
+	MsgType_EditTags              = "MsgTypeEditTags"
+	MsgType_TagsSubmitted         = "MsgTypeTagsSubmitted"
+	MsgType_ToggleFavorite        = "MsgTypeToggleFavorite"
+	MsgType_HealthReport          = "MsgTypeHealthReport"
+	MsgType_SwitchStore           = "MsgTypeSwitchStore"
+	MsgType_StoreSelected         = "MsgTypeStoreSelected"
+	MsgType_CopyField             = "MsgTypeCopyField"
+	MsgType_Lock                  = "MsgTypeLock"
+	MsgType_Locked                = "MsgTypeLocked"
+	MsgType_Quit                  = "MsgTypeQuit"
+	MsgType_AddEntry              = "MsgTypeAddEntry"
+	MsgType_EditEntry             = "MsgTypeEditEntry"
+	MsgType_EntrySubmitted        = "MsgTypeEntrySubmitted"
+	MsgType_DeleteEntry           = "MsgTypeDeleteEntry"
+	MsgType_DeleteConfirmed       = "MsgTypeDeleteConfirmed"
+	MsgType_Sync                  = "MsgTypeSync"
+	MsgType_DeleteFolder          = "MsgTypeDeleteFolder"
+	MsgType_DeleteFolderConfirmed = "MsgTypeDeleteFolderConfirmed"
+	MsgType_MoveEntry             = "MsgTypeMoveEntry"
+	MsgType_MoveFolder            = "MsgTypeMoveFolder"
+	MsgType_Reencrypt             = "MsgTypeReencrypt"
+	MsgType_EntryHighlighted      = "MsgTypeEntryHighlighted"
+	MsgType_InitStore             = "MsgTypeInitStore"
+	MsgType_StoreInitialized      = "MsgTypeStoreInitialized"
 )
 
+// allStores is the stores page item showing the entries of every store.
+const allStores = "All stores"
+
 type Msg struct {
 	Type    MsgType
 	Content string
+	// Target is the new name of a moved entry or folder
+	Target string
+	// Form is the submitted add or edit page
+	Form *entryForm
+	// Wizard is the submitted first-run wizard
+	Wizard *storeWizard
+	// Clone is the store the wizard cloned
+	Clone *service.CloneResult
 }
 
 type controller struct {
-	View    *view
-	Model   *model
-	quit    chan struct{}
-	msgChan chan Msg
+	View     *view
+	Model    *model
+	quit     chan struct{}
+	quitOnce sync.Once
+	msgChan  chan Msg
+	syncing  atomic.Bool
+	// initializing is set while the wizard creates or clones the store
+	initializing atomic.Bool
+}
+
+// stop makes ListenToEvents stop the app and return, it is safe to call more
+// than once.
+func (c *controller) stop() {
+	c.quitOnce.Do(func() {
+		close(c.quit)
+	})
 }
 
 func (c *controller) CheckAndRender() {
 	_, err := os.Stat(config.PasswordStoreDir())
 
 	if err != nil {
-		slog.Error("TODO: password store dir doesn't exist")
+		slog.Info("Password store directory does not exist, showing the wizard", slog.String("path", config.PasswordStoreDir()))
+		c.showWizard()
+		return
+	}
+
+	c.ShowMainPage()
+	if err := config.Err(); err != nil {
+		c.View.SetStatusText(WrapColor(fmt.Sprintf("Invalid configuration, using the defaults: %v", err), "red"))
+	}
+}
 
+// showWizard offers to create or clone the missing password store, with the
+// usable secret keys of the keyring to encrypt a new one for.
+func (c *controller) showWizard() {
+	var usable []gpg.KeyInfo
+	keys, err := gpg.ListSecretKeys()
+	if err != nil {
+		slog.Error("Failed to list secret keys", slog.Any("error", err))
+	}
+	for _, key := range keys {
+		if key.Usable() {
+			usable = append(usable, key)
+		}
+	}
+
+	w := &storeWizard{dir: config.PasswordStoreDir()}
+	if len(usable) > 0 {
+		w.key = usable[0].KeyID
+	}
+	c.View.SetStatusText(fmt.Sprintf("No password store at %s, create a new one or clone an existing one", config.PasswordStoreDir()))
+	c.View.ShowWizard(w, usable)
+}
+
+// handleInitStore creates or clones the store of the wizard in the background
+// and makes it the root store, the main page shows once it is ready.
+func (c *controller) handleInitStore(w *storeWizard) {
+	dir := strings.TrimSpace(w.dir)
+	key := strings.TrimSpace(w.key)
+	remote := strings.TrimSpace(w.remote)
+	switch {
+	case dir == "":
+		c.View.SetStatusText(WrapColor("Choose the directory of the store", "red"))
+		return
+	case w.clone && remote == "":
+		c.View.SetStatusText(WrapColor("Enter the remote to clone", "red"))
 		return
+	case !w.clone && key == "":
+		c.View.SetStatusText(WrapColor("Choose the GPG key of the store", "red"))
+		return
+	}
+	if !c.initializing.CompareAndSwap(false, true) {
+		c.View.SetStatusText("The store is being set up")
+		return
+	}
+
+	action := "Creating"
+	if w.clone {
+		action = "Cloning"
 	}
+	c.View.app.QueueUpdateDraw(func() {
+		c.View.SetStatusText(fmt.Sprintf("%s the store at %s...", action, dir))
+	})
+
+	go func() {
+		defer c.initializing.Store(false)
+		var err error
+		var clone *service.CloneResult
+		if w.clone {
+			result := service.CloneStore(dir, remote)
+			clone, err = &result, result.Err
+		} else {
+			err = service.InitStore(dir, key, remote)
+		}
+		if err == nil {
+			err = config.SetStorePath(dir)
+		}
+		if err != nil {
+			slog.Error("Failed to set up the password store", slog.String("dir", dir), slog.Any("error", err))
+			c.View.app.QueueUpdateDraw(func() {
+				c.View.SetStatusText(WrapColor(tview.Escape(err.Error()), "red"))
+			})
+			return
+		}
+
+		select {
+		case c.msgChan <- Msg{Type: MsgType_StoreInitialized, Content: fmt.Sprintf("Password store is ready at %s", dir), Clone: clone}:
+		case <-c.quit:
+		}
+	}()
+}
 
+// handleStoreInitialized shows the new store, or how to get access to a
+// cloned one the user cannot read yet.
+func (c *controller) handleStoreInitialized(status string, clone *service.CloneResult) {
+	c.View.SetStatusText(status)
 	c.ShowMainPage()
+	if clone != nil && !clone.CanDecrypt() {
+		c.View.ShowAccessHelp(clone.AccessHelp(), clone.PublicKey)
+	}
 }
 
 func (c *controller) ShowMainPage() {
 	c.View.ShowPage("main")
+	c.loadEntries()
+}
 
-	entries, err := service.ListPasswordEntries(config.PasswordStoreDir())
+func (c *controller) loadEntries() {
+	entries, err := service.ListEntries()
 
 	if err != nil {
 		slog.Error("Failed to load password entries", slog.Any("error", err))
@@ -60,12 +210,54 @@ func (c *controller) ShowMainPage() {
 	slog.Info("Entries list", slog.Any("data", entries))
 }
 
+// WatchClipboard shows the clipboard countdown in the status bar.
+func (c *controller) WatchClipboard() {
+	service.Events.Subscribe(func(ev service.Event) {
+		if ev.Type != service.EventClipboard {
+			return
+		}
+		c.View.app.QueueUpdateDraw(func() {
+			c.View.SetStatusText(ev.Message)
+		})
+	})
+}
+
+// WatchLock forwards the store locking, by this app or another one, to
+// msgChan.
+func (c *controller) WatchLock() {
+	service.Events.Subscribe(func(ev service.Event) {
+		if ev.Type != service.EventLocked {
+			return
+		}
+
+		select {
+		case c.msgChan <- Msg{Type: MsgType_Locked, Content: ev.Message}:
+		case <-c.quit:
+		}
+	})
+}
+
+// WatchStore forwards store changes from the service events to msgChan.
+func (c *controller) WatchStore() {
+	service.Events.Subscribe(func(ev service.Event) {
+		if !ev.IsEntryEvent() {
+			return
+		}
+
+		select {
+		case c.msgChan <- Msg{Type: MsgType_StoreChanged, Content: ev.Message}:
+		case <-c.quit:
+		}
+	})
+}
+
 func (c *controller) ListenToEvents() {
 	for {
 		select {
 		case <-c.quit:
 			slog.Debug("Controller receives quit signal, return nil")
 			time.Sleep(300 * time.Millisecond) // Give some time for UI to show the last status update
+			service.ClearClipboard()
 			c.View.app.Stop()
 			return
 		case msg := <-c.msgChan:
@@ -74,12 +266,65 @@ func (c *controller) ListenToEvents() {
 			switch msg.Type {
 			case MsgType_UpdateStatus:
 				c.View.SetStatusText(msg.Content)
-
-				close(c.quit)
+			case MsgType_Quit:
+				c.View.SetStatusText("Bye!")
+				c.stop()
 			case MsgType_EntrySelected:
 				c.handleEntrySelected(msg.Content)
 			case MsgType_PassphraseSubmitted:
 				c.handlePassphraseSubmitted(msg.Content)
+			case MsgType_StoreChanged:
+				c.loadEntries()
+			case MsgType_FilterChanged:
+				c.Model.SetFilter(msg.Content)
+			case MsgType_EditTags:
+				c.handleEditTags()
+			case MsgType_TagsSubmitted:
+				c.handleTagsSubmitted(msg.Content)
+			case MsgType_ToggleFavorite:
+				c.handleToggleFavorite()
+			case MsgType_HealthReport:
+				c.handleHealthReport()
+			case MsgType_SwitchStore:
+				c.handleSwitchStore()
+			case MsgType_StoreSelected:
+				c.handleStoreSelected(msg.Content)
+			case MsgType_CopyField:
+				c.handleCopyField(msg.Content)
+			case MsgType_Lock:
+				c.handleLock()
+			case MsgType_Locked:
+				c.handleLocked(msg.Content)
+			case MsgType_AddEntry:
+				form := newEntryForm("", "")
+				form.entry = msg.Content
+				c.View.ShowEntryForm(form)
+			case MsgType_EditEntry:
+				c.handleEditEntry()
+			case MsgType_EntrySubmitted:
+				c.handleEntrySubmitted(msg.Form)
+			case MsgType_DeleteEntry:
+				c.View.ShowConfirm(fmt.Sprintf("Delete %s?", msg.Content), "Delete", Msg{Type: MsgType_DeleteConfirmed, Content: msg.Content})
+			case MsgType_DeleteConfirmed:
+				c.handleDeleteConfirmed(msg.Content)
+			case MsgType_Sync:
+				c.handleSync()
+			case MsgType_DeleteFolder:
+				c.handleDeleteFolder(msg.Content)
+			case MsgType_DeleteFolderConfirmed:
+				c.handleDeleteFolderConfirmed(msg.Content)
+			case MsgType_MoveEntry:
+				c.handleMove(msg.Content, msg.Target, service.MoveEntry)
+			case MsgType_MoveFolder:
+				c.handleMove(msg.Content, msg.Target, service.MoveFolder)
+			case MsgType_Reencrypt:
+				c.handleReencrypt(msg.Content)
+			case MsgType_EntryHighlighted:
+				c.handleEntryHighlighted(msg.Content)
+			case MsgType_InitStore:
+				c.handleInitStore(msg.Wizard)
+			case MsgType_StoreInitialized:
+				c.handleStoreInitialized(msg.Content, msg.Clone)
 			}
 		}
 	}
@@ -96,7 +341,7 @@ func (c *controller) handleEntrySelected(entry string) {
 		result := service.DecryptAndCacheIfOk(entry, cachedPassphrase)
 		if result.Err == nil {
 			c.Model.SetDecryptedContent(result.Plaintext)
-			c.View.SetPasswordDetail(result.Plaintext)
+			c.View.SetEntryDetail(result.Plaintext)
 			c.View.SetStatusText("Entry decrypted successfully")
 			return
 		}
@@ -125,95 +370,490 @@ func (c *controller) handlePassphraseSubmitted(passphrase string) {
 	}
 	// Decryption successful
 	c.Model.SetDecryptedContent(result.Plaintext)
-	c.View.SetPasswordDetail(result.Plaintext)
+	c.View.SetEntryDetail(result.Plaintext)
 	c.View.ClearPassphraseInput()
 	c.View.ShowPage("main")
 	c.View.SetStatusText("Entry decrypted successfully")
 }
 
+// selectedMeta returns the metadata of the decrypted entry, tags and favorite
+// can only be edited once the selected entry was decrypted.
+func (c *controller) selectedMeta() (service.EntryMeta, bool) {
+	if c.Model.SelectedEntry == "" || c.Model.DecryptedContent == "" {
+		c.View.SetStatusText("Select and decrypt an entry first")
+		return service.EntryMeta{}, false
+	}
+	return service.ParseEntry(c.Model.DecryptedContent).EntryMeta(), true
+}
+
+func (c *controller) handleEditTags() {
+	meta, ok := c.selectedMeta()
+	if !ok {
+		return
+	}
+	c.View.ShowTagsPage(strings.Join(meta.Tags, ", "))
+	c.View.SetStatusText("Comma separated tags, Enter to save, Esc to cancel")
+}
+
+func (c *controller) handleTagsSubmitted(tags string) {
+	c.View.ShowPage("main")
+	meta, ok := c.selectedMeta()
+	if !ok {
+		return
+	}
+
+	meta.Tags = nil
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			meta.Tags = append(meta.Tags, tag)
+		}
+	}
+	c.updateMeta(meta, "Tags saved")
+}
+
+func (c *controller) handleToggleFavorite() {
+	meta, ok := c.selectedMeta()
+	if !ok {
+		return
+	}
+
+	meta.Favorite = !meta.Favorite
+	status := "Removed from favorites"
+	if meta.Favorite {
+		status = "Added to favorites"
+	}
+	c.updateMeta(meta, status)
+}
+
+func (c *controller) updateMeta(meta service.EntryMeta, status string) {
+	passphrase, valid := service.GetCachedPassphrase()
+	if !valid {
+		c.View.SetStatusText("Passphrase expired, decrypt the entry again")
+		return
+	}
+
+	entry := c.Model.SelectedEntry
+	if err := service.UpdateEntryMeta(entry, passphrase, meta); err != nil {
+		slog.Error("Failed to update entry metadata", slog.String("entry", entry), slog.Any("error", err))
+		c.View.SetStatusText(fmt.Sprintf("Failed to update %s: %v", entry, err))
+		return
+	}
+
+	result := service.DecryptAndCacheIfOk(entry, passphrase)
+	if result.Err == nil {
+		c.Model.SetDecryptedContent(result.Plaintext)
+		c.View.SetEntryDetail(result.Plaintext)
+	}
+	c.View.SetStatusText(status)
+}
+
+// handleHealthReport runs the password health audit in the background and
+// shows the report page when it is done.
+func (c *controller) handleHealthReport() {
+	passphrase, valid := service.GetCachedPassphrase()
+	if !valid {
+		c.View.SetStatusText("Decrypt an entry first to unlock the store")
+		return
+	}
+
+	c.View.SetStatusText("Auditing passwords...")
+	sub := service.Events.Subscribe(func(ev service.Event) {
+		if p, ok := ev.Data.(service.AuditProgress); ok {
+			c.View.app.QueueUpdateDraw(func() {
+				c.View.SetStatusText(fmt.Sprintf("Auditing passwords... %d/%d", p.Done, p.Total))
+			})
+		}
+	})
+
+	go func() {
+		report, err := service.Audit(context.Background(), service.DefaultAuditRequest(passphrase))
+		sub.Unsubscribe()
+		if err != nil {
+			slog.Error("Password health audit failed", slog.Any("error", err))
+			c.View.app.QueueUpdateDraw(func() {
+				c.View.SetStatusText(fmt.Sprintf("Audit failed: %v", err))
+			})
+			return
+		}
+
+		var sb strings.Builder
+		report.Format(&sb)
+		c.View.ShowHealthReport(sb.String())
+	}()
+}
+
+// handleSwitchStore lists the stores to pick the one whose entries are shown.
+func (c *controller) handleSwitchStore() {
+	names := []string{allStores}
+	for _, m := range config.Mounts() {
+		names = append(names, m.Name)
+	}
+	c.View.ShowStoresPage(names, c.Model.Store)
+}
+
+func (c *controller) handleStoreSelected(store string) {
+	c.View.ShowPage("main")
+	if store == allStores {
+		store = ""
+	}
+	c.Model.SetStore(store)
+	if store == "" {
+		c.View.SetStatusText("Showing entries of all stores")
+		return
+	}
+	c.View.SetStatusText(fmt.Sprintf("Showing entries of store %s", store))
+}
+
+// handleCopyField copies a field of the decrypted entry to the terminal
+// clipboard, the password of a Free Form entry is its first line.
+func (c *controller) handleCopyField(field string) {
+	if c.Model.SelectedEntry == "" || c.Model.DecryptedContent == "" {
+		c.View.SetStatusText("Select and decrypt an entry first")
+		return
+	}
+
+	value, err := fieldValue(c.Model.DecryptedContent, field)
+	if err != nil {
+		c.View.SetStatusText(err.Error())
+		return
+	}
+	result := service.CopyToClipboard(service.CopyRequest{
+		Clipboard: terminalClipboard{view: c.View},
+		Value:     value,
+		Entry:     c.Model.SelectedEntry,
+		Field:     field,
+	})
+	if result.Err != nil {
+		c.View.SetStatusText(result.Err.Error())
+	}
+}
+
+// handleEntryHighlighted hides the decrypted entry once another one is
+// highlighted.
+func (c *controller) handleEntryHighlighted(entry string) {
+	if c.Model.DecryptedContent == "" || entry == c.Model.SelectedEntry {
+		return
+	}
+	c.Model.SetDecryptedContent("")
+	c.View.SetEntryDetail("")
+}
+
+func (c *controller) handleEditEntry() {
+	if c.Model.SelectedEntry == "" || c.Model.DecryptedContent == "" {
+		c.View.SetStatusText("Select and decrypt an entry first")
+		return
+	}
+	c.View.ShowEntryForm(newEntryForm(c.Model.SelectedEntry, c.Model.DecryptedContent))
+}
+
+// handleEntrySubmitted saves the add or edit page, it stays open when the
+// entry cannot be saved.
+func (c *controller) handleEntrySubmitted(form *entryForm) {
+	req := form.request()
+	if form.original == "" {
+		if _, err := os.Stat(service.EntryPath(req.EntryName)); err == nil {
+			c.View.SetStatusText(fmt.Sprintf("Entry already exists: %s", req.EntryName))
+			return
+		}
+	}
+	if result := service.AddOrEditEntry(req); result.Err != nil {
+		slog.Error("Failed to save entry", slog.String("entry", req.EntryName), slog.Any("error", result.Err))
+		c.View.SetStatusText(fmt.Sprintf("Failed to save %s: %v", req.EntryName, result.Err))
+		return
+	}
+
+	c.View.ShowPage("main")
+	if form.original != "" && form.original == c.Model.SelectedEntry {
+		if passphrase, valid := service.GetCachedPassphrase(); valid {
+			if result := service.DecryptAndCacheIfOk(form.original, passphrase); result.Err == nil {
+				c.Model.SetDecryptedContent(result.Plaintext)
+				c.View.SetEntryDetail(result.Plaintext)
+			}
+		}
+	}
+	c.View.SetStatusText(fmt.Sprintf("Saved %s", req.EntryName))
+}
+
+func (c *controller) handleDeleteConfirmed(entry string) {
+	if err := service.DeleteEntry(entry); err != nil {
+		slog.Error("Failed to delete entry", slog.String("entry", entry), slog.Any("error", err))
+		c.View.SetStatusText(fmt.Sprintf("Failed to delete %s: %v", entry, err))
+		return
+	}
+	if entry == c.Model.SelectedEntry {
+		c.Model.SetSelectedEntry("")
+		c.Model.SetDecryptedContent("")
+		c.View.SetEntryDetail("")
+	}
+	c.View.SetStatusText(fmt.Sprintf("Deleted %s", entry))
+}
+
+func (c *controller) handleDeleteFolder(folder string) {
+	count := 0
+	if node := service.BuildTree(c.Model.Entries).Folder(folder); node != nil {
+		count = node.Count
+	}
+	c.View.ShowConfirm(fmt.Sprintf("Delete the folder %s and its %d entries?", folder, count), "Delete",
+		Msg{Type: MsgType_DeleteFolderConfirmed, Content: folder})
+}
+
+func (c *controller) handleDeleteFolderConfirmed(folder string) {
+	n, err := service.DeleteFolder(folder)
+	if err != nil {
+		slog.Error("Failed to delete folder", slog.String("folder", folder), slog.Any("error", err))
+		c.View.SetStatusText(fmt.Sprintf("Failed to delete %s after %d entries: %v", folder, n, err))
+		return
+	}
+	if strings.HasPrefix(c.Model.SelectedEntry, folder+"/") {
+		c.Model.SetSelectedEntry("")
+		c.Model.SetDecryptedContent("")
+		c.View.SetEntryDetail("")
+	}
+	c.View.SetStatusText(fmt.Sprintf("Deleted %s and its %d entries", folder, n))
+}
+
+// handleMove moves an entry or a folder with move.
+func (c *controller) handleMove(from, to string, move func(from, to string) error) {
+	to = strings.TrimSpace(to)
+	if to == from {
+		return
+	}
+	if err := move(from, to); err != nil {
+		slog.Error("Failed to move", slog.String("from", from), slog.String("to", to), slog.Any("error", err))
+		c.View.SetStatusText(fmt.Sprintf("Failed to move %s: %v", from, err))
+		return
+	}
+	if c.Model.SelectedEntry == from {
+		c.Model.SetSelectedEntry(to)
+	}
+	c.View.SetStatusText(fmt.Sprintf("Moved %s to %s", from, to))
+}
+
+// handleReencrypt encrypts an entry or a folder again for the keys of its
+// store, in the background.
+func (c *controller) handleReencrypt(path string) {
+	passphrase, valid := service.GetCachedPassphrase()
+	if !valid {
+		c.View.SetStatusText("Decrypt an entry first to unlock the store")
+		return
+	}
+
+	c.View.SetStatusText(fmt.Sprintf("Re-encrypting %s...", path))
+	go func() {
+		n, err := service.ReencryptFolder(path, passphrase)
+		c.View.app.QueueUpdateDraw(func() {
+			if err != nil {
+				slog.Error("Re-encryption failed", slog.String("path", path), slog.Any("error", err))
+				c.View.SetStatusText(WrapColor(fmt.Sprintf("Re-encrypted %d entries, then failed: %v", n, err), "red"))
+				return
+			}
+			c.View.SetStatusText(fmt.Sprintf("Re-encrypted %d entries of %s", n, path))
+		})
+	}()
+}
+
+// handleSync syncs the stores in the background with the progress in the
+// status line.
+func (c *controller) handleSync() {
+	if !c.syncing.CompareAndSwap(false, true) {
+		c.View.SetStatusText("Sync is already running")
+		return
+	}
+
+	c.View.SetStatusText("Syncing...")
+	sub := service.Events.Subscribe(func(ev service.Event) {
+		if p, ok := ev.Data.(service.SyncProgress); ok && !p.Done {
+			c.View.app.QueueUpdateDraw(func() {
+				c.View.SetStatusText(fmt.Sprintf("Syncing... %s", p.Stage))
+			})
+		}
+	})
+
+	go func() {
+		err := service.Sync()
+		sub.Unsubscribe()
+		c.syncing.Store(false)
+		c.View.app.QueueUpdateDraw(func() {
+			if err != nil {
+				slog.Error("Sync failed", slog.Any("error", err))
+				c.View.SetStatusText(WrapColor(fmt.Sprintf("Sync failed: %v", err), "red"))
+				return
+			}
+			c.View.SetStatusText("Sync completed")
+		})
+	}()
+}
+
+func (c *controller) handleLock() {
+	if err := service.Lock(); err != nil {
+		c.View.SetStatusText(fmt.Sprintf("Failed to lock: %v", err))
+	}
+}
+
+// handleLocked forgets the decrypted entry, the next one prompts for the
+// passphrase again.
+func (c *controller) handleLocked(message string) {
+	c.Model.SetDecryptedContent("")
+	c.View.SetEntryDetail("")
+	if page, _ := c.View.pages.GetFrontPage(); page == "tags" {
+		c.View.ShowPage("main")
+	}
+	c.View.SetStatusText(message)
+}
+
 type modelUpdater interface {
 	ModelDidUpdate(state model) error
 }
 
 type passwordEntriesUpdater struct {
-	view            *view
-	passwordEntries *tview.List
+	view *view
 }
 
 func (p *passwordEntriesUpdater) ModelDidUpdate(state model) error {
 	slog.Debug("passwordEntriesupdater is called", slog.Any("state", state))
 
 	p.view.app.QueueUpdateDraw(func() {
-		p.passwordEntries.Clear()
-		for _, entry := range state.Entries {
-			p.passwordEntries.AddItem(entry, "", 0, nil)
+		entries := state.Entries
+		if state.Store != "" {
+			entries = nil
+			for _, entry := range state.Entries {
+				if service.StoreOf(entry) == state.Store {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		results, err := service.Search(service.SearchRequest{Query: state.Filter, Entries: entries})
+		if err != nil {
+			p.view.statusText.SetText(err.Error())
 		}
+		// Search results are ranked, the tree only makes sense unfiltered
+		p.view.fillTree(results, state.Filter)
 	})
 
 	return nil
 }
 
 type view struct {
-	app                    *tview.Application
-	pages                  *tview.Pages
-	msgChan                chan Msg
-	passwordEntries        *tview.List
+	app             *tview.Application
+	pages           *tview.Pages
+	msgChan         chan Msg
+	quit            chan struct{}
+	passwordEntries *tview.TreeView
+	expanded        map[string]bool
+	// treeFilter is the filter the entries tree was last filled with
+	treeFilter             string
 	passwordEntriesUpdater modelUpdater
-	passwordDetail         *tview.TextView
-	statusText             *tview.TextView
-	passphraseInput        *tview.InputField
+	passwordDetail         *tview.Table
+	detailRows             []detailRow
+	// revealed are the secret fields shown in clear
+	revealed map[string]bool
+	// otp is the TOTP secret of the shown entry, otpStop stops its ticker
+	otp             string
+	otpStop         chan struct{}
+	statusText      *tview.TextView
+	passphraseInput *tview.InputField
+	filterInput     *tview.InputField
+	tagsInput       *tview.InputField
+	healthReport    *tview.TextView
+	storesList      *tview.List
+	entryForm       *tview.Form
+	wizardForm      *tview.Form
+	accessHelp      *tview.TextView
+	// accessKey is the public key shown on the access page
+	accessKey   string
+	confirm     *tview.Modal
+	promptInput *tview.InputField
+	// editing is the form shown on the form page
+	editing  *entryForm
+	helpText *tview.TextView
+	// keys are the key bindings of every page
+	keys *keymap
+
+	// screen is the terminal the app draws on, nil when it is not running
+	screenMu sync.Mutex
+	screen   tcell.Screen
+}
+
+// terminalClipboard copies to the clipboard of the terminal with OSC 52, so
+// copying works over SSH too. The terminal clipboard cannot be read back, it
+// is cleared instead of restored.
+type terminalClipboard struct {
+	view *view
+}
+
+func (c terminalClipboard) Content() (string, bool) {
+	return "", false
+}
+
+func (c terminalClipboard) SetContent(text string) {
+	c.view.screenMu.Lock()
+	defer c.view.screenMu.Unlock()
+	if c.view.screen != nil {
+		c.view.screen.SetClipboard([]byte(text))
+	}
+}
+
+// send passes a message to the controller, unless it stopped.
+func (v *view) send(msg Msg) {
+	select {
+	case v.msgChan <- msg:
+	case <-v.quit:
+	}
 }
 
 func (v *view) Render() error {
-	return v.app.Run()
+	v.app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		v.screenMu.Lock()
+		v.screen = screen
+		v.screenMu.Unlock()
+	})
+	err := v.app.Run()
+
+	v.screenMu.Lock()
+	v.screen = nil
+	v.screenMu.Unlock()
+	return err
 }
 
 func (v *view) Init() {
+	keys, keysErr := loadKeymap(config.TUIKeymap(), config.TUIKeys())
+	v.keys = keys
+
 	headerLine := tview.NewTextView().SetTextAlign(tview.AlignRight)
-	addMenu := fmt.Sprintf("%sdd", WrapColor("A", "#ff0000"))
-	syncMenu := fmt.Sprintf("%sync", WrapColor("S", "#ff0000"))
-	quitMenu := fmt.Sprintf("%suit", WrapColor("Q", "#ff0000"))
-	helpMenu := fmt.Sprintf("%selp", WrapColor("H", "#ff0000"))
+	var menu strings.Builder
+	for _, item := range []struct{ action, title string }{
+		{"add", "Add"}, {"edit", "Edit"}, {"delete", "Delete"}, {"sync", "Sync"}, {"quit", "Quit"}, {"help", "Help"},
+	} {
+		if key := keys.first(keysMain, item.action); key != "" {
+			fmt.Fprintf(&menu, "%s %s\t", WrapColor(tview.Escape(key), "#ff0000"), item.title)
+		}
+	}
 
 	statusText := tview.NewTextView().SetText(WrapColor("Password entries are loaded, have a good day!", "blue")).
 		SetTextAlign(tview.AlignLeft).SetDynamicColors(true)
 	v.statusText = statusText
+	if keysErr != nil {
+		statusText.SetText(WrapColor(tview.Escape(fmt.Sprintf("Invalid key bindings: %v", keysErr)), "red"))
+	}
 
-	headerLine.SetText(
-		fmt.Sprintf("%s\t%s\t%s\t%s\t", addMenu, syncMenu, quitMenu, helpMenu),
-	).SetDynamicColors(true)
+	headerLine.SetText(menu.String()).SetDynamicColors(true)
 
-	passEntries := tview.NewList()
-	v.passwordEntries = passEntries
+	passEntries := v.initTree()
 	v.passwordEntriesUpdater = &passwordEntriesUpdater{
-		passwordEntries: passEntries,
-		view:            v,
+		view: v,
 	}
 
-	// Add Enter key handler to decrypt selected entry
-	passEntries.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEnter {
-			entry, _ := passEntries.GetItemText(passEntries.GetCurrentItem())
-			slog.Info("Entry selected", slog.String("entry", entry), slog.Int("current_item", passEntries.GetCurrentItem()))
-
-			if entry != "" {
-				v.msgChan <- Msg{
-					Type:    MsgType_EntrySelected,
-					Content: entry,
-				}
-			}
-			return nil
-		}
-		return event
-	})
+	filterInput := v.initFilter()
 
-	passDetail := tview.NewTextView().SetText("Password Detail")
-	v.passwordDetail = passDetail
+	passDetail := v.initDetail()
 
 	grid := tview.NewGrid().SetColumns(-1, -1).SetRows(2, -1, 1).SetBorders(true).SetGap(0, 0)
 	grid.AddItem(headerLine, 0, 0, 1, 2, 0, 0, false)
-	grid.AddItem(passEntries, 1, 0, 1, 1, 0, 0, true)
-	grid.AddItem(passDetail.SetTextAlign(tview.AlignLeft), 1, 1, 1, 1, 0, 0, false)
+	entriesColumn := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(filterInput, 1, 0, false).
+		AddItem(passEntries, 0, 1, true)
+	grid.AddItem(entriesColumn, 1, 0, 1, 1, 0, 0, true)
+	grid.AddItem(passDetail, 1, 1, 1, 1, 0, 0, false)
 	grid.AddItem(statusText, 2, 0, 1, 2, 0, 0, false)
 
 	v.pages = tview.NewPages()
@@ -231,12 +871,20 @@ func (v *view) Init() {
 		SetMaskCharacter('*').
 		SetFieldBackgroundColor(tcell.ColorBlack).
 		SetFieldTextColor(tcell.ColorWhite).
-		SetLabelColor(tcell.ColorWhite).
-		SetDoneFunc(func(key tcell.Key) {
-			if key == tcell.KeyEnter {
-				submitPassphrase()
-			}
-		})
+		SetLabelColor(tcell.ColorWhite)
+	passphraseInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch keys.action(keysPassphrase, event) {
+		case "submit":
+			submitPassphrase()
+			return nil
+		case "cancel":
+			passphraseInput.SetText("")
+			v.pages.SwitchToPage("main")
+			v.app.SetFocus(v.passwordEntries)
+			return nil
+		}
+		return event
+	})
 	v.passphraseInput = passphraseInput
 
 	submitPassphrase = func() {
@@ -244,10 +892,10 @@ func (v *view) Init() {
 		slog.Info("Passphrase submitted", slog.String("passphrase", passphrase))
 
 		// Send message to controller via msgChan
-		v.msgChan <- Msg{
+		v.send(Msg{
 			Type:    MsgType_PassphraseSubmitted,
 			Content: passphrase,
-		}
+		})
 	}
 
 	passphraseSubmitBtn := tview.NewButton("Submit").SetSelectedFunc(submitPassphrase)
@@ -272,33 +920,132 @@ func (v *view) Init() {
 
 	v.pages.AddPage("passphrase", passphraseOuter, true, false)
 
+	tagsInput := tview.NewInputField().
+		SetLabel("Tags: ").
+		SetFieldWidth(40).
+		SetFieldBackgroundColor(tcell.ColorBlack).
+		SetDoneFunc(func(key tcell.Key) {
+			switch key {
+			case tcell.KeyEnter:
+				v.send(Msg{
+					Type:    MsgType_TagsSubmitted,
+					Content: v.tagsInput.GetText(),
+				})
+			case tcell.KeyEscape:
+				v.pages.SwitchToPage("main")
+			}
+		})
+	v.tagsInput = tagsInput
+
+	tagsOuter := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(
+			tview.NewFlex().SetDirection(tview.FlexRow).
+				AddItem(nil, 0, 1, false).
+				AddItem(tagsInput, 1, 0, true).
+				AddItem(nil, 0, 1, false),
+			0, 1, true,
+		).
+		AddItem(nil, 0, 1, false)
+
+	v.pages.AddPage("tags", tagsOuter, true, false)
+
+	healthReport := tview.NewTextView().SetScrollable(true)
+	healthReport.SetBorder(true).SetTitle(" Password Health (Esc to go back) ")
+	healthReport.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			v.pages.SwitchToPage("main")
+		}
+	})
+	v.healthReport = healthReport
+	v.pages.AddPage("health", healthReport, true, false)
+
+	storesList := tview.NewList().ShowSecondaryText(false)
+	storesList.SetBorder(true).SetTitle(" Stores (Esc to go back) ")
+	storesList.SetSelectedFunc(func(_ int, name string, _ string, _ rune) {
+		v.send(Msg{Type: MsgType_StoreSelected, Content: name})
+	})
+	storesList.SetDoneFunc(func() {
+		v.pages.SwitchToPage("main")
+	})
+	v.storesList = storesList
+	v.pages.AddPage("stores", storesList, true, false)
+
+	v.initFormPages()
+	v.initPromptPage()
+	v.initHelpPage()
+	v.initWizardPage()
+
 	v.app.SetRoot(v.pages, true)
 
 	v.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		service.ReportActivity()
+
 		// Get current page name
 		currentPage, _ := v.pages.GetFrontPage()
 
 		// Only capture global shortcuts on main page
-		// Let passphrase page handle its own input
-		if currentPage == "passphrase" {
+		// Let passphrase and tags pages handle their own input
+		if currentPage != "main" {
 			return event
 		}
 
-		switch event.Key() {
-		case tcell.KeyCtrlA:
-			v.statusText.SetText("user press a")
-		case tcell.KeyCtrlS:
-			v.statusText.SetText("user press s")
-		case tcell.KeyCtrlQ:
-			v.msgChan <- Msg{
-				Type:    MsgType_UpdateStatus,
-				Content: "User presses CtrlQ, exiting!",
-			}
-		case tcell.KeyCtrlH:
-			v.statusText.SetText("user press h")
+		// Keys without a modifier are typed in the filter
+		if _, typing := v.app.GetFocus().(*tview.InputField); typing && event.Key() == tcell.KeyRune && event.Modifiers()&tcell.ModAlt == 0 {
+			return event
 		}
 
-		return event
+		switch v.keys.action(keysMain, event) {
+		case "add":
+			v.send(Msg{Type: MsgType_AddEntry, Content: v.currentPrefix()})
+		case "edit":
+			v.send(Msg{Type: MsgType_EditEntry})
+		case "delete":
+			if entry := v.currentEntry(); entry != "" {
+				v.send(Msg{Type: MsgType_DeleteEntry, Content: entry})
+			} else if folder := v.currentFolder(); folder != "" {
+				v.send(Msg{Type: MsgType_DeleteFolder, Content: folder})
+			}
+		case "move":
+			if entry := v.currentEntry(); entry != "" {
+				v.showPrompt("Move "+entry+" to", entry, func(to string) Msg {
+					return Msg{Type: MsgType_MoveEntry, Content: entry, Target: to}
+				})
+			} else if folder := v.currentFolder(); folder != "" {
+				v.showPrompt("Move folder "+folder+" to", folder, func(to string) Msg {
+					return Msg{Type: MsgType_MoveFolder, Content: folder, Target: to}
+				})
+			}
+		case "reencrypt":
+			if ref := v.currentRef(); ref != nil {
+				v.send(Msg{Type: MsgType_Reencrypt, Content: ref.path})
+			}
+		case "sync":
+			v.send(Msg{Type: MsgType_Sync})
+		case "quit":
+			v.send(Msg{Type: MsgType_Quit})
+		case "help":
+			v.pages.SwitchToPage("help")
+			v.app.SetFocus(v.helpText)
+		case "tags":
+			v.send(Msg{Type: MsgType_EditTags})
+		case "favorite":
+			v.send(Msg{Type: MsgType_ToggleFavorite})
+		case "health":
+			v.send(Msg{Type: MsgType_HealthReport})
+		case "stores":
+			v.send(Msg{Type: MsgType_SwitchStore})
+		case "copy_password":
+			v.send(Msg{Type: MsgType_CopyField, Content: "password"})
+		case "copy_user":
+			v.send(Msg{Type: MsgType_CopyField, Content: "email"})
+		case "lock":
+			v.send(Msg{Type: MsgType_Lock})
+		default:
+			// Moving in the tree is up to the focused tree
+			return event
+		}
+		return nil
 	})
 }
 
@@ -314,16 +1061,44 @@ func (v *view) ShowPage(name string) {
 	v.app.Draw()
 }
 
-func (v *view) SetStatusText(status string) {
-	v.statusText.SetText(status)
+// ShowTagsPage shows the tags input prefilled with the current tags.
+func (v *view) ShowTagsPage(tags string) {
+	v.app.QueueUpdateDraw(func() {
+		v.tagsInput.SetText(tags)
+		v.pages.SwitchToPage("tags")
+		v.app.SetFocus(v.tagsInput)
+	})
 }
 
-func (v *view) SetPasswordDetail(content string) {
+// ShowHealthReport shows the password health report page.
+func (v *view) ShowHealthReport(report string) {
 	v.app.QueueUpdateDraw(func() {
-		v.passwordDetail.SetText(content)
+		v.healthReport.SetText(report).ScrollToBeginning()
+		v.statusText.SetText("Password health audit completed")
+		v.pages.SwitchToPage("health")
+		v.app.SetFocus(v.healthReport)
 	})
 }
 
+// ShowStoresPage lists the stores with the current one selected.
+func (v *view) ShowStoresPage(names []string, current string) {
+	v.app.QueueUpdateDraw(func() {
+		v.storesList.Clear()
+		for i, name := range names {
+			v.storesList.AddItem(name, "", 0, nil)
+			if name == current {
+				v.storesList.SetCurrentItem(i)
+			}
+		}
+		v.pages.SwitchToPage("stores")
+		v.app.SetFocus(v.storesList)
+	})
+}
+
+func (v *view) SetStatusText(status string) {
+	v.statusText.SetText(status)
+}
+
 func (v *view) ClearPassphraseInput() {
 	v.app.QueueUpdateDraw(func() {
 		v.passphraseInput.SetText("")
@@ -338,6 +1113,9 @@ type model struct {
 	returnedPage     string
 	SelectedEntry    string
 	DecryptedContent string
+	Filter           string
+	// Store only shows the entries of one store, all stores when empty
+	Store string
 }
 
 func (m *model) SetEntries(entries []string) {
@@ -350,6 +1128,26 @@ func (m *model) SetEntries(entries []string) {
 	}
 }
 
+func (m *model) SetFilter(filter string) {
+	m.Filter = filter
+
+	if m.Subscriber != nil {
+		for _, sub := range *m.Subscriber {
+			sub.ModelDidUpdate(*m)
+		}
+	}
+}
+
+func (m *model) SetStore(store string) {
+	m.Store = store
+
+	if m.Subscriber != nil {
+		for _, sub := range *m.Subscriber {
+			sub.ModelDidUpdate(*m)
+		}
+	}
+}
+
 func (m *model) SetSelectedEntry(entry string) {
 	m.SelectedEntry = entry
 
@@ -377,6 +1175,25 @@ type app struct {
 func (a *app) Run() error {
 	var err error
 
+	storeWatcher, watchErr := watcher.NewMounts(config.Mounts(), service.Events)
+	if watchErr != nil {
+		slog.Error("Store watcher is disabled", slog.Any("error", watchErr))
+	} else {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer func() {
+			cancel()
+			storeWatcher.Close()
+		}()
+		go storeWatcher.Run(ctx)
+	}
+	a.Controller.WatchStore()
+	a.Controller.WatchClipboard()
+	a.Controller.WatchLock()
+
+	lockCtx, stopAutoLock := context.WithCancel(context.Background())
+	defer stopAutoLock()
+	go service.AutoLock(lockCtx)
+
 	var wg sync.WaitGroup
 	wg.Add(2)
 
@@ -387,6 +1204,8 @@ func (a *app) Run() error {
 		if err != nil {
 			slog.Error("Error in Render UI", slog.Any("error", err))
 		}
+		// The app also stops on Ctrl+C, the controller must not wait for it
+		a.Controller.stop()
 	}()
 
 	go func() {
@@ -402,12 +1221,17 @@ func (a *app) Run() error {
 	return err
 }
 
+// msgBuffer lets the view queue a few key presses while the controller waits
+// for a screen update, instead of both waiting for each other.
+const msgBuffer = 16
+
 func NewPico() *app {
-	msgChan := make(chan Msg)
+	msgChan := make(chan Msg, msgBuffer)
 	quit := make(chan struct{})
 
 	view := &view{
 		msgChan: msgChan,
+		quit:    quit,
 		app:     tview.NewApplication(),
 	}
 
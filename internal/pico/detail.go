@@ -0,0 +1,223 @@
+package pico
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// detailMask hides a secret value, always as long so the length is not shown.
+const detailMask = "••••••••"
+
+// detailRow is a line of the detail pane. A multi-line value takes several
+// rows of the same field, only the first one is labelled.
+type detailRow struct {
+	field  string
+	label  string
+	value  string
+	secret bool
+	otp    bool
+}
+
+// detailRows splits decrypted content into the rows of the detail pane. The
+// first line of a Free Form entry is its password, "key: value" lines are
+// fields and the other lines are notes. An OTP secret is shown as its code.
+func detailRows(content string) []detailRow {
+	if content == "" {
+		return nil
+	}
+	parsed := service.ParseEntry(content)
+	var rows []detailRow
+	add := func(field, value string, secret bool) {
+		for i, line := range strings.Split(value, "\n") {
+			if _, ok := service.OTPLine(line); ok {
+				continue
+			}
+			row := detailRow{field: field, value: line, secret: secret}
+			if i == 0 {
+				row.label = field
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if parsed.Template == service.TemplateFreeForm {
+		lines := strings.Split(parsed.Get("content"), "\n")
+		add("password", lines[0], true)
+		var notes []string
+		for _, line := range lines[1:] {
+			if _, ok := service.OTPLine(line); ok {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if key = strings.TrimSpace(key); ok && key != "" && !strings.ContainsAny(key, " \t") {
+				add(key, strings.TrimSpace(value), isSecretField(key))
+				continue
+			}
+			notes = append(notes, line)
+		}
+		if note := strings.TrimSpace(strings.Join(notes, "\n")); note != "" {
+			add("notes", note, false)
+		}
+	} else {
+		for _, f := range parsed.Fields {
+			add(f.Name, f.Value, isSecretField(f.Name))
+		}
+	}
+
+	if parsed.OTP() != "" {
+		rows = append(rows, detailRow{field: "otp", label: "otp", otp: true})
+	}
+	if tags := parsed.EntryMeta().Tags; len(tags) > 0 {
+		rows = append(rows, detailRow{field: "tags", label: "tags", value: strings.Join(tags, ", ")})
+	}
+	return rows
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "pass") || strings.Contains(name, "secret") || strings.Contains(name, "token") || name == "pin"
+}
+
+// fieldValue returns the value of a field of decrypted content as the detail
+// pane shows it, the current code for "otp".
+func fieldValue(content, field string) (string, error) {
+	parsed := service.ParseEntry(content)
+	switch field {
+	case "password":
+		return parsed.Password(), nil
+	case "otp":
+		code, _, err := service.TOTP(parsed.OTP(), time.Now())
+		return code, err
+	}
+	var lines []string
+	for _, row := range detailRows(content) {
+		if row.field == field {
+			lines = append(lines, row.value)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// SetEntryDetail shows the fields of decrypted content with the secrets
+// masked, empty content clears the pane.
+func (v *view) SetEntryDetail(content string) {
+	v.app.QueueUpdateDraw(func() {
+		v.showDetail(content)
+	})
+}
+
+func (v *view) showDetail(content string) {
+	if v.otpStop != nil {
+		close(v.otpStop)
+		v.otpStop = nil
+	}
+	v.detailRows = detailRows(content)
+	v.otp = service.ParseEntry(content).OTP()
+	v.revealed = map[string]bool{}
+	v.fillDetail()
+	v.passwordDetail.Select(0, 0).ScrollToBeginning()
+
+	if v.otp != "" {
+		v.otpStop = make(chan struct{})
+		go v.tickOTP(v.otpStop)
+	}
+	if len(v.detailRows) == 0 && v.app.GetFocus() == v.passwordDetail {
+		v.app.SetFocus(v.passwordEntries)
+	}
+}
+
+func (v *view) fillDetail() {
+	table := v.passwordDetail
+	table.Clear()
+	if len(v.detailRows) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell("Password Detail").SetSelectable(false))
+		return
+	}
+	for i, row := range v.detailRows {
+		value := tview.Escape(row.value)
+		switch {
+		case row.otp:
+			code, remaining, err := service.TOTP(v.otp, time.Now())
+			if err != nil {
+				value = WrapColor(tview.Escape(err.Error()), "red")
+			} else {
+				value = fmt.Sprintf("%s %s", code, WrapColor(fmt.Sprintf("(%ds)", int(remaining.Seconds())), "gray"))
+			}
+		case row.secret && !v.revealed[row.field]:
+			value = detailMask
+		}
+		table.SetCell(i, 0, tview.NewTableCell(row.label).SetTextColor(tcell.ColorYellow))
+		table.SetCell(i, 1, tview.NewTableCell(value).SetExpansion(1))
+	}
+}
+
+// tickOTP refreshes the OTP code every second until the entry changes.
+func (v *view) tickOTP(stop chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			v.app.QueueUpdateDraw(func() {
+				select {
+				case <-stop:
+				default:
+					v.fillDetail()
+				}
+			})
+		case <-stop:
+			return
+		case <-v.quit:
+			return
+		}
+	}
+}
+
+// selectedDetail returns the highlighted row of the detail pane.
+func (v *view) selectedDetail() (detailRow, bool) {
+	row, _ := v.passwordDetail.GetSelection()
+	if row < 0 || row >= len(v.detailRows) {
+		return detailRow{}, false
+	}
+	return v.detailRows[row], true
+}
+
+// focusDetail moves to the fields of the decrypted entry, if one is shown.
+func (v *view) focusDetail() {
+	if len(v.detailRows) > 0 {
+		v.app.SetFocus(v.passwordDetail)
+	}
+}
+
+// initDetail sets up the detail pane, its keys are the detail bindings:
+// reveal shows or masks a secret field, copy copies the field.
+func (v *view) initDetail() *tview.Table {
+	table := tview.NewTable().SetSelectable(true, false)
+	v.passwordDetail = table
+	v.fillDetail()
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch v.keys.action(keysDetail, event) {
+		case "reveal":
+			if row, ok := v.selectedDetail(); ok && row.secret {
+				v.revealed[row.field] = !v.revealed[row.field]
+				v.fillDetail()
+			}
+		case "copy":
+			if row, ok := v.selectedDetail(); ok {
+				v.send(Msg{Type: MsgType_CopyField, Content: row.field})
+			}
+		case "back":
+			v.app.SetFocus(v.passwordEntries)
+		default:
+			return event
+		}
+		return nil
+	})
+	return table
+}
@@ -0,0 +1,91 @@
+package pico
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// highlightMatch colors the matched characters of an entry, positions are
+// the rune indexes of a SearchResult.
+func highlightMatch(entry string, positions []int) string {
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+
+	var b, run strings.Builder
+	inMatch := false
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if inMatch {
+			b.WriteString(WrapColor(tview.Escape(run.String()), "yellow"))
+		} else {
+			b.WriteString(tview.Escape(run.String()))
+		}
+		run.Reset()
+	}
+	for i, r := range []rune(entry) {
+		if matched[i] != inMatch {
+			flush()
+			inMatch = matched[i]
+		}
+		run.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
+
+// initFilter sets up the filter input. The search key of the entries tree
+// starts filtering, every change filters the entries again, and the search
+// bindings move in the results while typing, open the highlighted entry or
+// clear the filter.
+func (v *view) initFilter() *tview.InputField {
+	filter := tview.NewInputField().
+		SetLabel("Filter: ").
+		SetPlaceholder(v.keys.first(keysMain, "search") + " to search").
+		SetFieldBackgroundColor(tcell.ColorBlack).
+		SetChangedFunc(func(text string) {
+			v.send(Msg{
+				Type:    MsgType_FilterChanged,
+				Content: text,
+			})
+		})
+	filter.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch v.keys.action(keysSearch, event) {
+		case "up":
+			v.passwordEntries.Move(-1)
+		case "down":
+			v.passwordEntries.Move(1)
+		case "page_up":
+			v.passwordEntries.Move(-10)
+		case "page_down":
+			v.passwordEntries.Move(10)
+		case "open":
+			if entry := v.currentEntry(); entry != "" {
+				v.send(Msg{Type: MsgType_EntrySelected, Content: entry})
+			}
+			v.app.SetFocus(v.passwordEntries)
+		case "done":
+			v.app.SetFocus(v.passwordEntries)
+		case "cancel":
+			v.clearFilter()
+			v.app.SetFocus(v.passwordEntries)
+		default:
+			return event
+		}
+		return nil
+	})
+	v.filterInput = filter
+	return filter
+}
+
+// clearFilter shows every entry again, the change is sent like typing.
+func (v *view) clearFilter() {
+	if v.filterInput.GetText() != "" {
+		v.filterInput.SetText("")
+	}
+}
@@ -0,0 +1,196 @@
+package pico
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// entryForm is the content of the add and edit page. Its fields are
+// unexported so that logging a Msg never prints the secrets.
+type entryForm struct {
+	// original is the entry being edited, empty when adding one
+	original string
+	entry    string
+	template string
+	fields   map[string]string
+	tags     string
+	favorite bool
+	// meta is the metadata of the edited entry, kept for the keys the form
+	// does not show
+	meta map[string]string
+}
+
+// newEntryForm returns the form of a new entry, or of the decrypted content
+// of an existing one.
+func newEntryForm(entry, content string) *entryForm {
+	form := &entryForm{template: service.TemplateFreeForm, fields: map[string]string{}}
+	if entry == "" {
+		return form
+	}
+
+	parsed := service.ParseEntry(content)
+	meta := parsed.EntryMeta()
+	form.original, form.entry = entry, entry
+	form.template = parsed.Template
+	if service.GetTemplateByName(form.template) == nil {
+		form.template = service.TemplateFreeForm
+	}
+	for _, field := range parsed.Fields {
+		form.fields[field.Name] = field.Value
+	}
+	form.tags = strings.Join(meta.Tags, ", ")
+	form.favorite = meta.Favorite
+	form.meta = parsed.Meta
+	return form
+}
+
+func (f *entryForm) clone() *entryForm {
+	c := *f
+	c.fields = maps.Clone(f.fields)
+	return &c
+}
+
+// request returns the AddEditRequest saving the form.
+func (f *entryForm) request() service.AddEditRequest {
+	meta := service.EntryMeta{Favorite: f.favorite}
+	for _, tag := range strings.Split(f.tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			meta.Tags = append(meta.Tags, tag)
+		}
+	}
+	return service.AddEditRequest{
+		EntryName:    strings.TrimSpace(f.entry),
+		TemplateName: f.template,
+		Fields:       f.fields,
+		Meta:         meta,
+		ExtraMeta:    f.meta,
+	}
+}
+
+// ShowEntryForm shows the add or edit page with one field per template field.
+func (v *view) ShowEntryForm(form *entryForm) {
+	v.app.QueueUpdateDraw(func() {
+		v.buildEntryForm(form)
+		v.pages.SwitchToPage("form")
+		v.app.SetFocus(v.entryForm)
+	})
+}
+
+// buildEntryForm fills the form page, it runs again when another template
+// is picked.
+func (v *view) buildEntryForm(f *entryForm) {
+	v.editing = f
+	form := v.entryForm
+	form.Clear(true)
+	hint := fmt.Sprintf(" (%s to save, %s to cancel) ", v.keys.first(keysForm, "save"), v.keys.first(keysForm, "cancel"))
+	if f.original == "" {
+		form.SetTitle(" Add Entry" + hint)
+	} else {
+		form.SetTitle(" Edit " + f.original + hint)
+	}
+
+	form.AddInputField("Entry name", f.entry, 40, nil, func(text string) {
+		f.entry = text
+	})
+	if f.original != "" {
+		// Renaming is a move, not an edit
+		form.GetFormItem(0).(*tview.InputField).SetDisabled(true)
+	}
+
+	var names []string
+	current := 0
+	for i, tmpl := range service.Templates {
+		names = append(names, tmpl.Name)
+		if tmpl.Name == f.template {
+			current = i
+		}
+	}
+	form.AddDropDown("Template", names, current, func(name string, _ int) {
+		if name == f.template {
+			return
+		}
+		f.template = name
+		// The form cannot be rebuilt while it handles the selection
+		go v.app.QueueUpdateDraw(func() {
+			v.buildEntryForm(f)
+			v.app.SetFocus(v.entryForm)
+		})
+	})
+
+	tmpl := service.GetTemplateByName(f.template)
+	for _, field := range tmpl.Fields {
+		label := strings.ToUpper(field[:1]) + field[1:]
+		changed := func(text string) {
+			f.fields[field] = text
+		}
+		switch field {
+		case "content", "extra":
+			form.AddTextArea(label, f.fields[field], 0, 4, 0, changed)
+		case "password":
+			form.AddPasswordField(label, f.fields[field], 40, '*', changed)
+		default:
+			form.AddInputField(label, f.fields[field], 40, nil, changed)
+		}
+	}
+
+	form.AddInputField("Tags", f.tags, 40, nil, func(text string) {
+		f.tags = text
+	})
+	form.AddCheckbox("Favorite", f.favorite, func(checked bool) {
+		f.favorite = checked
+	})
+
+	form.AddButton("Save", func() {
+		v.send(Msg{Type: MsgType_EntrySubmitted, Form: f.clone()})
+	})
+	form.AddButton("Cancel", func() {
+		v.pages.SwitchToPage("main")
+	})
+}
+
+// ShowConfirm asks a yes or no question, onYes sends msg.
+func (v *view) ShowConfirm(question, yes string, msg Msg) {
+	v.app.QueueUpdateDraw(func() {
+		v.confirm.ClearButtons().
+			SetText(question).
+			AddButtons([]string{yes, "Cancel"}).
+			SetDoneFunc(func(index int, _ string) {
+				v.pages.SwitchToPage("main")
+				if index == 0 {
+					v.send(msg)
+				}
+			})
+		v.pages.SwitchToPage("confirm")
+		v.app.SetFocus(v.confirm)
+	})
+}
+
+func (v *view) initFormPages() {
+	v.entryForm = tview.NewForm().
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	v.entryForm.SetBorder(true)
+	v.entryForm.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// An open template list closes first
+		if dropDown, ok := v.app.GetFocus().(*tview.DropDown); ok && dropDown.IsOpen() {
+			return event
+		}
+		switch v.keys.action(keysForm, event) {
+		case "save":
+			v.send(Msg{Type: MsgType_EntrySubmitted, Form: v.editing.clone()})
+		case "cancel":
+			v.pages.SwitchToPage("main")
+		default:
+			return event
+		}
+		return nil
+	})
+	v.pages.AddPage("form", v.entryForm, true, false)
+
+	v.confirm = tview.NewModal()
+	v.pages.AddPage("confirm", v.confirm, true, false)
+}
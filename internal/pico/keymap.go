@@ -0,0 +1,329 @@
+package pico
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Pages with their own key bindings. The search bindings apply while typing
+// in the filter of the main page.
+const (
+	keysMain       = "main"
+	keysSearch     = "search"
+	keysPassphrase = "passphrase"
+	keysForm       = "form"
+	keysDetail     = "detail"
+)
+
+// keyAction is an action that can be bound to keys, Keys are the default
+// bindings.
+type keyAction struct {
+	Name string
+	Help string
+	Keys string
+}
+
+// keyPage lists the actions of a page. Text pages type the keys without a
+// modifier, they cannot be bound.
+type keyPage struct {
+	Name    string
+	Title   string
+	Text    bool
+	Actions []keyAction
+}
+
+var keyPages = []keyPage{
+	{Name: keysMain, Title: "Entries", Actions: []keyAction{
+		{"open", "Decrypt the entry, open or close the folder", "Enter"},
+		{"up", "Previous entry", "Up"},
+		{"down", "Next entry", "Down"},
+		{"expand", "Open the folder, or go to the fields of the decrypted entry", "Right"},
+		{"collapse", "Close the folder, go to the parent folder", "Left"},
+		{"search", "Filter the entries", "/ Tab"},
+		{"clear_filter", "Show every entry again", "Esc"},
+		{"add", "Add an entry in the highlighted folder", "Ctrl+A"},
+		{"edit", "Edit the decrypted entry", "Ctrl+E"},
+		{"delete", "Delete the entry or folder", "Ctrl+D"},
+		{"move", "Move the entry or folder", "Ctrl+N"},
+		{"reencrypt", "Re-encrypt the entry or folder", "Ctrl+G"},
+		{"copy_password", "Copy the password", "Ctrl+Y"},
+		{"copy_user", "Copy the email", "Ctrl+U"},
+		{"tags", "Edit the tags", "Ctrl+T"},
+		{"favorite", "Toggle favorite", "Ctrl+F"},
+		{"stores", "Switch store", "Ctrl+O"},
+		{"health", "Password health report", "Ctrl+R"},
+		{"sync", "Sync with the git remote", "Ctrl+S"},
+		{"lock", "Lock the store", "Ctrl+L"},
+		{"help", "Show the key bindings", "F1 ?"},
+		{"quit", "Quit", "Ctrl+Q"},
+	}},
+	{Name: keysSearch, Title: "Filter", Text: true, Actions: []keyAction{
+		{"up", "Previous result", "Up"},
+		{"down", "Next result", "Down"},
+		{"page_up", "Previous page of results", "PgUp"},
+		{"page_down", "Next page of results", "PgDn"},
+		{"open", "Decrypt the highlighted result", "Enter"},
+		{"done", "Back to the entries, keep the filter", "Tab"},
+		{"cancel", "Clear the filter", "Esc"},
+	}},
+	{Name: keysDetail, Title: "Entry fields", Actions: []keyAction{
+		{"reveal", "Show or mask the secret field", "Space r"},
+		{"copy", "Copy the field, the clipboard is cleared after a while", "Enter c"},
+		{"back", "Back to the entries", "Left Esc"},
+	}},
+	{Name: keysPassphrase, Title: "Passphrase", Text: true, Actions: []keyAction{
+		{"submit", "Unlock", "Enter"},
+		{"cancel", "Back to the entries", "Esc"},
+	}},
+	{Name: keysForm, Title: "Add and edit", Text: true, Actions: []keyAction{
+		{"save", "Save the entry", "Ctrl+S"},
+		{"cancel", "Back to the entries", "Esc"},
+	}},
+}
+
+// keyPresets override the default bindings by page then action.
+var keyPresets = map[string]map[string]map[string]string{
+	config.KeymapVim: {
+		keysMain: {
+			"up": "k Up", "down": "j Down", "expand": "l Right", "collapse": "h Left",
+			"search": "/", "add": "a", "edit": "e", "delete": "d", "move": "m", "reencrypt": "R",
+			"copy_password": "y", "copy_user": "Y", "tags": "t", "favorite": "f", "stores": "o",
+			"health": "H", "sync": "s", "lock": "L", "help": "? F1", "quit": "q",
+		},
+		keysSearch: {"up": "Ctrl+K Up", "down": "Ctrl+J Down", "page_up": "Ctrl+B PgUp", "page_down": "Ctrl+F PgDn"},
+		keysDetail: {"back": "h Left Esc"},
+	},
+	config.KeymapEmacs: {
+		keysMain: {
+			"up": "Ctrl+P Up", "down": "Ctrl+N Down", "expand": "Ctrl+F Right", "collapse": "Ctrl+B Left",
+			"search": "Ctrl+S /", "clear_filter": "Ctrl+G Esc", "add": "Alt+a", "edit": "Alt+e",
+			"delete": "Alt+d", "move": "Alt+m", "reencrypt": "Alt+r", "copy_password": "Alt+w",
+			"copy_user": "Alt+u", "tags": "Alt+t", "favorite": "Alt+f", "stores": "Alt+o",
+			"health": "Alt+h", "sync": "Alt+s", "help": "F1",
+		},
+		keysSearch:     {"up": "Ctrl+P Up", "down": "Ctrl+N Down", "page_up": "Alt+v PgUp", "page_down": "Ctrl+V PgDn", "cancel": "Ctrl+G Esc"},
+		keysDetail:     {"back": "Ctrl+B Left Ctrl+G Esc"},
+		keysPassphrase: {"cancel": "Ctrl+G Esc"},
+		keysForm:       {"cancel": "Ctrl+G Esc"},
+	},
+}
+
+// keyBinding is a key with its modifiers, written like "Ctrl+A", "Alt+s",
+// "F1" or "q".
+type keyBinding struct {
+	key  tcell.Key
+	ch   rune
+	alt  bool
+	name string
+}
+
+func (k keyBinding) matches(event *tcell.EventKey) bool {
+	if k.alt != (event.Modifiers()&tcell.ModAlt != 0) || k.key != event.Key() {
+		return false
+	}
+	return k.key != tcell.KeyRune || k.ch == event.Rune()
+}
+
+// parseKey reads a key name. Named keys are the tcell ones, e.g. Enter, Esc,
+// Up, PgDn or F5, and Space is the space bar.
+func parseKey(name string) (keyBinding, error) {
+	k := keyBinding{name: name}
+	rest, ctrl := name, false
+	for {
+		mod, key, ok := strings.Cut(rest, "+")
+		if !ok || key == "" {
+			break
+		}
+		switch strings.ToLower(mod) {
+		case "ctrl":
+			ctrl = true
+		case "alt":
+			k.alt = true
+		default:
+			return k, fmt.Errorf("unknown modifier %q in %q", mod, name)
+		}
+		rest = key
+	}
+
+	switch {
+	case ctrl:
+		r, size := utf8.DecodeRuneInString(strings.ToLower(rest))
+		switch {
+		case size == len(rest) && r >= 'a' && r <= 'z':
+			k.key = tcell.KeyCtrlA + tcell.Key(r-'a')
+		case strings.EqualFold(rest, "space"):
+			k.key = tcell.KeyCtrlSpace
+		default:
+			return k, fmt.Errorf("unsupported key %q, Ctrl goes with a letter", name)
+		}
+	case strings.EqualFold(rest, "space"):
+		k.key, k.ch = tcell.KeyRune, ' '
+	case utf8.RuneCountInString(rest) == 1:
+		k.key, k.ch = tcell.KeyRune, []rune(rest)[0]
+	default:
+		for key, keyName := range tcell.KeyNames {
+			if strings.EqualFold(keyName, rest) && !strings.HasPrefix(keyName, "Ctrl-") {
+				k.key = key
+				return k, nil
+			}
+		}
+		return k, fmt.Errorf("unknown key %q", name)
+	}
+	return k, nil
+}
+
+// keymap holds the bindings of every page by action.
+type keymap struct {
+	pages map[string]map[string][]keyBinding
+}
+
+// loadKeymap applies a preset then the configured bindings over the
+// defaults. Invalid bindings are skipped and reported, the others apply.
+func loadKeymap(preset string, overrides map[string]map[string]string) (*keymap, error) {
+	km := &keymap{pages: map[string]map[string][]keyBinding{}}
+	var errs []error
+	for _, page := range keyPages {
+		actions := map[string][]keyBinding{}
+		for _, action := range page.Actions {
+			keys := action.Keys
+			if v, ok := keyPresets[preset][page.Name][action.Name]; ok {
+				keys = v
+			}
+			actions[action.Name], _ = parseKeys(page, keys)
+		}
+		km.pages[page.Name] = actions
+	}
+
+	for pageName, bindings := range overrides {
+		page, ok := findKeyPage(pageName)
+		if !ok {
+			errs = append(errs, fmt.Errorf("tui.keys.%s: unknown page", pageName))
+			continue
+		}
+		for action, keys := range bindings {
+			if _, ok := km.pages[pageName][action]; !ok {
+				errs = append(errs, fmt.Errorf("tui.keys.%s.%s: unknown action", pageName, action))
+				continue
+			}
+			parsed, err := parseKeys(page, keys)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("tui.keys.%s.%s: %w", pageName, action, err))
+				continue
+			}
+			km.pages[pageName][action] = parsed
+		}
+	}
+
+	// A key runs one action per page, the first one listed keeps it
+	for _, page := range keyPages {
+		seen := map[string]string{}
+		for _, action := range page.Actions {
+			var kept []keyBinding
+			for _, k := range km.pages[page.Name][action.Name] {
+				id := fmt.Sprintf("%d/%d/%t", k.key, k.ch, k.alt)
+				if other, ok := seen[id]; ok {
+					errs = append(errs, fmt.Errorf("tui.keys.%s: %s is bound to both %s and %s", page.Name, k.name, other, action.Name))
+					continue
+				}
+				seen[id] = action.Name
+				kept = append(kept, k)
+			}
+			km.pages[page.Name][action.Name] = kept
+		}
+	}
+
+	return km, errors.Join(errs...)
+}
+
+func findKeyPage(name string) (keyPage, bool) {
+	for _, page := range keyPages {
+		if page.Name == name {
+			return page, true
+		}
+	}
+	return keyPage{}, false
+}
+
+func parseKeys(page keyPage, keys string) ([]keyBinding, error) {
+	var bindings []keyBinding
+	for _, name := range strings.Fields(keys) {
+		k, err := parseKey(name)
+		if err != nil {
+			return nil, err
+		}
+		if page.Text && k.key == tcell.KeyRune && !k.alt {
+			return nil, fmt.Errorf("%q would be typed, use a key with Ctrl or Alt", name)
+		}
+		bindings = append(bindings, k)
+	}
+	return bindings, nil
+}
+
+// action returns the action of a page bound to the key, empty when none is.
+func (km *keymap) action(page string, event *tcell.EventKey) string {
+	for name, bindings := range km.pages[page] {
+		for _, k := range bindings {
+			if k.matches(event) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// keys returns the key names bound to an action, e.g. "Ctrl+A, a".
+func (km *keymap) keys(page, action string) string {
+	var names []string
+	for _, k := range km.pages[page][action] {
+		names = append(names, k.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// first returns the first key name bound to an action, for the header.
+func (km *keymap) first(page, action string) string {
+	if bindings := km.pages[page][action]; len(bindings) > 0 {
+		return bindings[0].name
+	}
+	return ""
+}
+
+// help lists the bindings of every page.
+func (km *keymap) help() string {
+	var b strings.Builder
+	for i, page := range keyPages {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s\n", WrapColor(page.Title, "yellow"))
+		for _, action := range page.Actions {
+			keys := km.keys(page.Name, action.Name)
+			if keys == "" {
+				keys = "-"
+			}
+			fmt.Fprintf(&b, "  %s%s %s\n", tview.Escape(keys), strings.Repeat(" ", max(22-len(keys), 0)), action.Help)
+		}
+	}
+	return b.String()
+}
+
+// initHelpPage lists the key bindings, it closes with Esc, q or the help key.
+func (v *view) initHelpPage() {
+	v.helpText = tview.NewTextView().SetDynamicColors(true).SetScrollable(true).SetText(v.keys.help())
+	v.helpText.SetBorder(true).SetTitle(" Key bindings (Esc to go back) ")
+	v.helpText.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' || v.keys.action(keysMain, event) == "help" {
+			v.pages.SwitchToPage("main")
+			v.app.SetFocus(v.passwordEntries)
+			return nil
+		}
+		return event
+	})
+	v.pages.AddPage("help", v.helpText, true, false)
+}
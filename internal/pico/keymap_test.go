@@ -0,0 +1,132 @@
+package pico
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  tcell.Key
+		ch   rune
+		alt  bool
+	}{
+		{"q", tcell.KeyRune, 'q', false},
+		{"+", tcell.KeyRune, '+', false},
+		{"Space", tcell.KeyRune, ' ', false},
+		{"Enter", tcell.KeyEnter, 0, false},
+		{"esc", tcell.KeyEscape, 0, false},
+		{"PgDn", tcell.KeyPgDn, 0, false},
+		{"F1", tcell.KeyF1, 0, false},
+		{"Ctrl+A", tcell.KeyCtrlA, 0, false},
+		{"ctrl+z", tcell.KeyCtrlZ, 0, false},
+		{"Ctrl+Space", tcell.KeyCtrlSpace, 0, false},
+		{"Alt+s", tcell.KeyRune, 's', true},
+		{"Alt+Up", tcell.KeyUp, 0, true},
+		{"Ctrl+Alt+x", tcell.KeyCtrlX, 0, true},
+	}
+	for _, tt := range tests {
+		k, err := parseKey(tt.name)
+		if err != nil {
+			t.Errorf("parseKey(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if k.key != tt.key || k.ch != tt.ch || k.alt != tt.alt || k.name != tt.name {
+			t.Errorf("parseKey(%q) = %+v, expected key %d, rune %q, alt %t", tt.name, k, tt.key, tt.ch, tt.alt)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"Shift+a": "unknown modifier",
+		"Ctrl+1":  "Ctrl goes with a letter",
+		"Ctrl+F1": "Ctrl goes with a letter",
+		"Nope":    "unknown key",
+	} {
+		if _, err := parseKey(name); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected parseKey(%q) to fail with %q, got %v", name, want, err)
+		}
+	}
+}
+
+func TestKeyBindingMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *tcell.EventKey
+		want  bool
+	}{
+		{"a", tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), true},
+		{"a", tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone), false},
+		{"a", tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModAlt), false},
+		{"Alt+a", tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModAlt), true},
+		{"Ctrl+A", tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModCtrl), true},
+		{"Ctrl+A", tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), false},
+	}
+	for _, tt := range tests {
+		k, err := parseKey(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := k.matches(tt.event); got != tt.want {
+			t.Errorf("%q matches %s = %t, expected %t", tt.name, tt.event.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestLoadKeymap(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		overrides map[string]map[string]string
+		page      string
+		action    string
+		want      string
+		err       string
+	}{
+		{"default", config.KeymapDefault, nil, keysMain, "add", "Ctrl+A", ""},
+		{"vim preset", config.KeymapVim, nil, keysMain, "add", "a", ""},
+		{"vim keeps the defaults it does not set", config.KeymapVim, nil, keysMain, "open", "Enter", ""},
+		{"emacs preset on a text page", config.KeymapEmacs, nil, keysForm, "cancel", "Ctrl+G, Esc", ""},
+		{"override", config.KeymapDefault, map[string]map[string]string{keysMain: {"add": "Alt+n"}}, keysMain, "add", "Alt+n", ""},
+		{"override over the preset", config.KeymapVim, map[string]map[string]string{keysMain: {"quit": "Ctrl+Q"}}, keysMain, "quit", "Ctrl+Q", ""},
+		{"modifier on a text page", config.KeymapDefault, map[string]map[string]string{keysForm: {"save": "Alt+s Ctrl+W"}}, keysForm, "save", "Alt+s, Ctrl+W", ""},
+		{"unmodified key on a text page", config.KeymapDefault, map[string]map[string]string{keysForm: {"save": "s"}}, keysForm, "save", "Ctrl+S", "would be typed"},
+		{"space on a text page", config.KeymapDefault, map[string]map[string]string{keysSearch: {"done": "Space"}}, keysSearch, "done", "Tab", "would be typed"},
+		{"unmodified key on another page", config.KeymapDefault, map[string]map[string]string{keysDetail: {"copy": "y"}}, keysDetail, "copy", "y", ""},
+		{"invalid key", config.KeymapDefault, map[string]map[string]string{keysMain: {"add": "Ctrl+1"}}, keysMain, "add", "Ctrl+A", "tui.keys.main.add"},
+		{"unknown page", config.KeymapDefault, map[string]map[string]string{"nope": {"add": "a"}}, keysMain, "add", "Ctrl+A", "tui.keys.nope: unknown page"},
+		{"unknown action", config.KeymapDefault, map[string]map[string]string{keysMain: {"nope": "a"}}, keysMain, "add", "Ctrl+A", "tui.keys.main.nope: unknown action"},
+		{"duplicate key keeps the first action", config.KeymapDefault, map[string]map[string]string{keysMain: {"edit": "Ctrl+A"}}, keysMain, "add", "Ctrl+A", "Ctrl+A is bound to both add and edit"},
+		{"duplicate key drops the binding", config.KeymapDefault, map[string]map[string]string{keysMain: {"edit": "Ctrl+A"}}, keysMain, "edit", "", "bound to both"},
+		{"duplicate key with the preset", config.KeymapVim, map[string]map[string]string{keysMain: {"lock": "q"}}, keysMain, "quit", "", "q is bound to both lock and quit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, err := loadKeymap(tt.preset, tt.overrides)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("expected an error containing %q, got %v", tt.err, err)
+			}
+			if got := km.keys(tt.page, tt.action); got != tt.want {
+				t.Errorf("%s.%s is bound to %q, expected %q", tt.page, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultKeymapsAreValid(t *testing.T) {
+	for _, preset := range []string{config.KeymapDefault, config.KeymapVim, config.KeymapEmacs} {
+		km, err := loadKeymap(preset, nil)
+		if err != nil {
+			t.Errorf("preset %s is invalid: %v", preset, err)
+		}
+		if got := km.action(keysMain, tcell.NewEventKey(tcell.KeyF1, 0, tcell.ModNone)); got != "help" {
+			t.Errorf("F1 runs %q with preset %s, expected help", got, preset)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package pico
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// treeRef is the reference of an entries tree node.
+type treeRef struct {
+	// path is the entry or folder name, empty for the root
+	path   string
+	folder bool
+}
+
+// fillTree shows the entries in collapsible folders with their entry counts,
+// or the search results in their ranked order with the matched characters
+// highlighted when filtering. The highlighted node and the expanded folders
+// are kept, a new filter highlights its best match.
+func (v *view) fillTree(results []service.SearchResult, filter string) {
+	current := v.currentRef()
+	if filter != v.treeFilter {
+		current = nil
+		v.treeFilter = filter
+	}
+
+	root := tview.NewTreeNode("").SetReference(&treeRef{folder: true})
+	if filter != "" {
+		for _, result := range results {
+			root.AddChild(tview.NewTreeNode(highlightMatch(result.Entry, result.Positions)).SetReference(&treeRef{path: result.Entry}))
+		}
+	} else {
+		entries := make([]string, 0, len(results))
+		for _, result := range results {
+			entries = append(entries, result.Entry)
+		}
+		v.addTreeChildren(root, service.BuildTree(entries))
+	}
+	v.passwordEntries.SetRoot(root).SetTopLevel(1)
+
+	var first, found *tview.TreeNode
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if node == root {
+			return true
+		}
+		ref := node.GetReference().(*treeRef)
+		if first == nil {
+			first = node
+		}
+		if current != nil && ref.path == current.path && ref.folder == current.folder {
+			found = node
+		}
+		return node.IsExpanded()
+	})
+	if found == nil {
+		found = first
+	}
+	v.passwordEntries.SetCurrentNode(found)
+}
+
+func (v *view) addTreeChildren(parent *tview.TreeNode, folder *service.TreeNode) {
+	for _, child := range folder.Children {
+		if child.Entry {
+			parent.AddChild(tview.NewTreeNode(tview.Escape(child.Name)).SetReference(&treeRef{path: child.Path}))
+			continue
+		}
+		node := tview.NewTreeNode(fmt.Sprintf("%s/ (%d)", tview.Escape(child.Name), child.Count)).
+			SetReference(&treeRef{path: child.Path, folder: true}).
+			SetColor(tcell.ColorYellow).
+			SetExpanded(v.expanded[child.Path])
+		v.addTreeChildren(node, child)
+		parent.AddChild(node)
+	}
+}
+
+// setExpanded expands or collapses a folder and remembers it for the next
+// time the tree is filled.
+func (v *view) setExpanded(node *tview.TreeNode, expanded bool) {
+	ref, ok := node.GetReference().(*treeRef)
+	if !ok || !ref.folder {
+		return
+	}
+	node.SetExpanded(expanded)
+	if expanded {
+		v.expanded[ref.path] = true
+	} else {
+		delete(v.expanded, ref.path)
+	}
+}
+
+func (v *view) currentRef() *treeRef {
+	node := v.passwordEntries.GetCurrentNode()
+	if node == nil {
+		return nil
+	}
+	ref, _ := node.GetReference().(*treeRef)
+	return ref
+}
+
+// currentEntry returns the highlighted entry, empty when a folder or nothing
+// is highlighted.
+func (v *view) currentEntry() string {
+	if ref := v.currentRef(); ref != nil && !ref.folder {
+		return ref.path
+	}
+	return ""
+}
+
+// currentFolder returns the highlighted folder, empty when an entry or
+// nothing is highlighted.
+func (v *view) currentFolder() string {
+	if ref := v.currentRef(); ref != nil && ref.folder {
+		return ref.path
+	}
+	return ""
+}
+
+// currentPrefix is where a new entry goes: the highlighted folder, or the
+// folder of the highlighted entry, with a trailing slash.
+func (v *view) currentPrefix() string {
+	dir := v.currentFolder()
+	if entry := v.currentEntry(); entry != "" {
+		if dir = path.Dir(entry); dir == "." {
+			dir = ""
+		}
+	}
+	if dir == "" {
+		return ""
+	}
+	return dir + "/"
+}
+
+// initTree sets up the entries tree, its keys are the main page bindings:
+// open decrypts an entry or toggles a folder, expand and collapse open and
+// close folders.
+func (v *view) initTree() *tview.TreeView {
+	tree := tview.NewTreeView()
+	v.passwordEntries = tree
+	v.expanded = map[string]bool{}
+
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		ref, ok := node.GetReference().(*treeRef)
+		if !ok {
+			return
+		}
+		if ref.folder {
+			v.setExpanded(node, !node.IsExpanded())
+			return
+		}
+		v.send(Msg{Type: MsgType_EntrySelected, Content: ref.path})
+	})
+	// Another highlighted entry hides the decrypted one
+	tree.SetChangedFunc(func(node *tview.TreeNode) {
+		if ref, ok := node.GetReference().(*treeRef); ok {
+			v.send(Msg{Type: MsgType_EntryHighlighted, Content: ref.path})
+		}
+	})
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node := tree.GetCurrentNode()
+		switch v.keys.action(keysMain, event) {
+		case "open":
+			if node != nil {
+				tree.GetSelectedFunc()(node)
+			}
+		case "up":
+			tree.Move(-1)
+		case "down":
+			tree.Move(1)
+		case "search":
+			v.app.SetFocus(v.filterInput)
+		case "clear_filter":
+			v.clearFilter()
+		case "expand":
+			if ref := v.currentRef(); ref != nil && !ref.folder {
+				v.focusDetail()
+			} else if node != nil {
+				v.setExpanded(node, true)
+			}
+		case "collapse":
+			if node == nil {
+				return nil
+			}
+			if ref := node.GetReference().(*treeRef); ref.folder && node.IsExpanded() {
+				v.setExpanded(node, false)
+				return nil
+			}
+			// Go up to the folder
+			if nodes := tree.GetPath(node); len(nodes) > 2 {
+				tree.SetCurrentNode(nodes[len(nodes)-2])
+			}
+		default:
+			return event
+		}
+		return nil
+	})
+	return tree
+}
+
+// showPrompt asks for a line of text on the prompt page, Enter sends the
+// message onDone returns, Esc goes back. It runs on the event loop, e.g.
+// from a key binding.
+func (v *view) showPrompt(label, text string, onDone func(text string) Msg) {
+	v.promptInput.SetLabel(label + ": ").SetText(text)
+	v.promptInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			v.pages.SwitchToPage("main")
+			v.send(onDone(v.promptInput.GetText()))
+		case tcell.KeyEscape:
+			v.pages.SwitchToPage("main")
+		}
+	})
+	v.pages.SwitchToPage("prompt")
+	v.app.SetFocus(v.promptInput)
+}
+
+func (v *view) initPromptPage() {
+	v.promptInput = tview.NewInputField().
+		SetFieldWidth(50).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	outer := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(
+			tview.NewFlex().SetDirection(tview.FlexRow).
+				AddItem(nil, 0, 1, false).
+				AddItem(v.promptInput, 1, 0, true).
+				AddItem(nil, 0, 1, false),
+			0, 2, true,
+		).
+		AddItem(nil, 0, 1, false)
+	v.pages.AddPage("prompt", outer, true, false)
+}
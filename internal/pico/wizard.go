@@ -0,0 +1,148 @@
+package pico
+
+import (
+	"github.com/duykhoa/gopass/internal/gpg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// wizardActions are the choices of the first-run wizard, creating a new store
+// or cloning an existing one.
+var wizardActions = []string{"Create a new store", "Clone an existing store"}
+
+// storeWizard is the content of the first-run wizard page.
+type storeWizard struct {
+	clone  bool
+	dir    string
+	key    string
+	remote string
+}
+
+func (w *storeWizard) copy() *storeWizard {
+	c := *w
+	return &c
+}
+
+// ShowWizard shows the first-run wizard, keys are the secret keys the new
+// store can be encrypted for.
+func (v *view) ShowWizard(w *storeWizard, keys []gpg.KeyInfo) {
+	v.app.QueueUpdateDraw(func() {
+		v.buildWizard(w, keys)
+		v.pages.SwitchToPage("wizard")
+		v.app.SetFocus(v.wizardForm)
+	})
+}
+
+// buildWizard fills the wizard page, it runs again when the action changes
+// as cloning needs no key.
+func (v *view) buildWizard(w *storeWizard, keys []gpg.KeyInfo) {
+	form := v.wizardForm
+	form.Clear(true)
+
+	action := 0
+	if w.clone {
+		action = 1
+	}
+	form.AddDropDown("Action", wizardActions, action, func(_ string, index int) {
+		if clone := index == 1; clone != w.clone {
+			w.clone = clone
+			// The form cannot be rebuilt while it handles the selection
+			go v.app.QueueUpdateDraw(func() {
+				v.buildWizard(w, keys)
+				v.app.SetFocus(v.wizardForm)
+			})
+		}
+	})
+	form.AddInputField("Directory", w.dir, 50, nil, func(text string) {
+		w.dir = text
+	})
+
+	if !w.clone {
+		if len(keys) > 0 {
+			var names []string
+			current := 0
+			for i, key := range keys {
+				names = append(names, key.String())
+				if key.KeyID == w.key {
+					current = i
+				}
+			}
+			form.AddDropDown("GPG key", names, current, func(_ string, index int) {
+				if index >= 0 {
+					w.key = keys[index].KeyID
+				}
+			})
+		} else {
+			form.AddInputField("GPG key ID", w.key, 50, nil, func(text string) {
+				w.key = text
+			})
+			form.AddTextView("", "No secret key found. Create one with 'gpg --full-generate-key', then run 'gpg -K' to find its id.", 50, 2, false, false)
+		}
+	}
+
+	label := "Remote Git URL (optional)"
+	if w.clone {
+		label = "Remote Git URL"
+	}
+	remote := tview.NewInputField().SetLabel(label).SetText(w.remote).SetFieldWidth(50).
+		SetPlaceholder("git@host:path/to/repo.git").
+		SetChangedFunc(func(text string) {
+			w.remote = text
+		})
+	form.AddFormItem(remote)
+
+	button := "Create"
+	if w.clone {
+		button = "Clone"
+	}
+	form.AddButton(button, func() {
+		v.send(Msg{Type: MsgType_InitStore, Wizard: w.copy()})
+	})
+	form.AddButton("Quit", func() {
+		v.send(Msg{Type: MsgType_Quit})
+	})
+}
+
+func (v *view) initWizardPage() {
+	v.wizardForm = tview.NewForm().
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	v.wizardForm.SetBorder(true).SetTitle(" Set up the password store ")
+	// The status line reports the progress and errors here too
+	page := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.wizardForm, 0, 1, true).
+		AddItem(v.statusText, 1, 0, false)
+	v.pages.AddPage("wizard", page, true, false)
+
+	v.accessHelp = tview.NewTextView().SetDynamicColors(true).SetWrap(true).SetScrollable(true)
+	v.accessHelp.SetBorder(true).SetTitle(" No access to the store yet (c to copy your public key, Esc to go on) ")
+	v.accessHelp.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			v.pages.SwitchToPage("main")
+			v.app.SetFocus(v.passwordEntries)
+		case event.Key() == tcell.KeyRune && event.Rune() == 'c':
+			// A public key is no secret, it is not cleared from the clipboard
+			terminalClipboard{view: v}.SetContent(v.accessKey)
+			v.statusText.SetText("Public key copied")
+		default:
+			return event
+		}
+		return nil
+	})
+	v.pages.AddPage("access", v.accessHelp, true, false)
+}
+
+// ShowAccessHelp explains how to get access to a cloned store the user cannot
+// decrypt, with the public key to send to an admin.
+func (v *view) ShowAccessHelp(help, publicKey string) {
+	v.app.QueueUpdateDraw(func() {
+		text := WrapColor(tview.Escape(help), "yellow")
+		if publicKey != "" {
+			text += "\n\n" + tview.Escape(publicKey)
+		}
+		v.accessKey = publicKey
+		v.accessHelp.SetText(text).ScrollToBeginning()
+		v.pages.SwitchToPage("access")
+		v.app.SetFocus(v.accessHelp)
+	})
+}
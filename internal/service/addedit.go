@@ -6,7 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/audit"
 	"github.com/duykhoa/gopass/internal/gpg"
 )
 
@@ -15,6 +15,10 @@ type AddEditRequest struct {
 	TemplateName string
 	Fields       map[string]string
 	GPGId        string
+	Meta         EntryMeta
+	// ExtraMeta is the metadata of the edited entry, the keys gopass does not
+	// know are written back
+	ExtraMeta map[string]string
 }
 
 type AddEditResult struct {
@@ -26,13 +30,24 @@ func AddOrEditEntry(req AddEditRequest) AddEditResult {
 		return AddEditResult{fmt.Errorf("entry name cannot be empty")}
 	}
 
+	content, err := entryContent(req)
+	if err != nil {
+		return AddEditResult{err}
+	}
+
+	return AddEditResult{SaveEntry(req.EntryName, []byte(content))}
+}
+
+// entryContent returns the plaintext of the entry of the request, its fields
+// followed by the metadata block.
+func entryContent(req AddEditRequest) (string, error) {
 	var content strings.Builder
 	if req.TemplateName == "Free Form" {
 		content.WriteString(req.Fields["content"])
 	} else {
 		tmpl := GetTemplateByName(req.TemplateName)
 		if tmpl == nil {
-			return AddEditResult{fmt.Errorf("template not found: %s", req.TemplateName)}
+			return "", fmt.Errorf("template not found: %s", req.TemplateName)
 		}
 		for _, field := range tmpl.Fields {
 			content.WriteString(fmt.Sprintf("%s: %s\n", field, req.Fields[field]))
@@ -46,29 +61,85 @@ func AddOrEditEntry(req AddEditRequest) AddEditResult {
 	if !strings.HasSuffix(s, "\n") {
 		content.WriteString("\n")
 	}
-	content.WriteString("---\n")
-	content.WriteString(fmt.Sprintf("template: %s\n", req.TemplateName))
-
-	// Encrypt and store
-	storeDir := config.PasswordStoreDir()
-	entryPath := filepath.Join(storeDir, req.EntryName+".gpg")
-	var gpgId string
-	if gpgId == "" {
-		gpgId = config.GPGId()
+	formatMeta(&content, req.TemplateName, req.Meta, req.ExtraMeta)
+
+	return content.String(), nil
+}
+
+// SaveEntry encrypts the content for the store recipients and writes it to the
+// entry file, creating or replacing it.
+func SaveEntry(entryName string, content []byte) error {
+	entryPath := EntryPath(entryName)
+	op, eventType := audit.OpAdd, EventEntryCreated
+	if _, err := os.Stat(entryPath); err == nil {
+		op, eventType = audit.OpEdit, EventEntryUpdated
 	}
-	ciphertext, err := gpg.EncryptWithGPGKey([]byte(content.String()), gpgId)
+
+	err := writeEntry(entryPath, entryRecipients(entryName), content)
+	audit.Log(op, entryName, err)
 	if err != nil {
-		return AddEditResult{err}
+		return err
 	}
-	if err := os.WriteFile(entryPath, ciphertext, 0600); err != nil {
-		return AddEditResult{err}
+	indexPut(entryName, string(content))
+	Events.Publish(Event{Type: eventType, Message: entryName, Data: EntryChanged{Entry: entryName}})
+
+	return nil
+}
+
+// writeEntry encrypts the content for the recipients of the folder the file
+// belongs to.
+func writeEntry(entryPath string, recipients gpg.Recipients, content []byte) error {
+	ciphertext, err := gpg.Encrypt(content, recipients)
+	if err != nil {
+		return err
 	}
-	return AddEditResult{nil}
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(entryPath, ciphertext, 0600)
 }
 
 // DeleteEntry removes the .gpg file for the given entry name from the password store.
 func DeleteEntry(entryName string) error {
-	storeDir := config.PasswordStoreDir()
-	entryPath := filepath.Join(storeDir, entryName+".gpg")
-	return os.Remove(entryPath)
+	entryPath := EntryPath(entryName)
+	err := os.Remove(entryPath)
+	audit.Log(audit.OpDelete, entryName, err)
+	if err != nil {
+		return err
+	}
+	indexDelete(entryName)
+	Events.Publish(Event{Type: EventEntryDeleted, Message: entryName, Data: EntryChanged{Entry: entryName}})
+
+	return nil
+}
+
+// MoveEntry renames an entry inside the password store, creating the
+// destination folder if needed. Entries cannot be moved between stores, they
+// would stay encrypted for the keys of the old one.
+func MoveEntry(from, to string) error {
+	if to == "" {
+		return fmt.Errorf("entry name cannot be empty")
+	}
+	if StoreOf(from) != StoreOf(to) {
+		return fmt.Errorf("cannot move %s to another store (%s), copy the entry instead", from, StoreOf(to))
+	}
+	fromPath := EntryPath(from)
+	toPath := EntryPath(to)
+	if _, err := os.Stat(toPath); err == nil {
+		return fmt.Errorf("entry already exists: %s", to)
+	}
+
+	err := os.MkdirAll(filepath.Dir(toPath), 0700)
+	if err == nil {
+		err = os.Rename(fromPath, toPath)
+	}
+	audit.Log(audit.OpMove, from+" -> "+to, err)
+	if err != nil {
+		return err
+	}
+	indexMove(from, to)
+	Events.Publish(Event{Type: EventEntryMoved, Message: to, Data: EntryChanged{Entry: to, OldEntry: from}})
+
+	return nil
 }
@@ -10,3 +10,24 @@ func TestGetTemplateByName(t *testing.T) {
 		t.Errorf("template lookup failed")
 	}
 }
+
+func TestEditKeepsUnknownMeta(t *testing.T) {
+	original := "email: me@example.com\npassword: old\n---\ntemplate: Email and password\ntags: work\nowner: ops\n"
+	parsed := ParseEntry(original)
+
+	content, err := entryContent(AddEditRequest{
+		EntryName:    "work/mail",
+		TemplateName: parsed.Template,
+		Fields:       map[string]string{"email": "me@example.com", "password": "new"},
+		Meta:         EntryMeta{Tags: []string{"work", "mail"}},
+		ExtraMeta:    parsed.Meta,
+	})
+	if err != nil {
+		t.Fatalf("entryContent failed: %v", err)
+	}
+
+	edited := ParseEntry(content)
+	if edited.Password() != "new" || edited.Meta["owner"] != "ops" || edited.Meta["tags"] != "work, mail" {
+		t.Errorf("unexpected edited entry %q", content)
+	}
+}
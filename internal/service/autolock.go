@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+const (
+	// autoLockInterval is how often AutoLock checks the idle time and the
+	// passphrase cache.
+	autoLockInterval = 2 * time.Second
+
+	// activityResolution throttles ReportActivity, input events come in
+	// bursts and only the last one matters.
+	activityResolution = 5 * time.Second
+)
+
+// lastReported is the last activity of this process, in Unix nanoseconds.
+var lastReported atomic.Int64
+
+// activityPath is touched on activity, next to the passphrase cache, so the
+// desktop app and the TUI share their idle time.
+func activityPath() string {
+	return filepath.Join(filepath.Dir(getCachePath()), "activity")
+}
+
+// ReportActivity records user input, it postpones the auto-lock of every app
+// using the store.
+func ReportActivity() {
+	now := time.Now()
+	last := lastReported.Load()
+	if now.Sub(time.Unix(0, last)) < activityResolution || !lastReported.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	path := activityPath()
+	err := os.Chtimes(path, now, now)
+	if errors.Is(err, os.ErrNotExist) {
+		err = os.WriteFile(path, nil, 0600)
+	}
+	if err != nil {
+		slog.Debug("Failed to record activity", slog.Any("error", err))
+	}
+}
+
+// lastActivity returns the latest activity reported by any app.
+func lastActivity() time.Time {
+	last := time.Unix(0, lastReported.Load())
+	if info, err := os.Stat(activityPath()); err == nil && info.ModTime().After(last) {
+		last = info.ModTime()
+	}
+	return last
+}
+
+// AutoLock locks the store once nobody used it for config.AutoLockIdle, or
+// when the screen locks, until ctx is done. It also publishes EventLocked
+// when the passphrase cache expires or another app locks the store, so
+// every UI can hide what it decrypted.
+func AutoLock(ctx context.Context) {
+	lockNow := make(chan struct{}, 1)
+	err := watchScreenLock(ctx, func() {
+		select {
+		case lockNow <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		slog.Info("Screen lock is not watched", slog.Any("error", err))
+	}
+
+	// Activity starts with the app, not with the last run
+	lastReported.Store(time.Now().UnixNano())
+	_, unlocked, _ := gpg.DecryptCachedPassphrase(getCachePath())
+
+	ticker := time.NewTicker(autoLockInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-lockNow:
+			if unlocked {
+				lockWithReason("Store locked with the screen")
+				unlocked = false
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		_, valid, _ := gpg.DecryptCachedPassphrase(getCachePath())
+		switch {
+		case !valid && unlocked:
+			unlocked = false
+			invalidateIndexCache()
+			ClearClipboard()
+			Events.Publish(Event{Type: EventLocked, Message: "Store locked", Data: LockStateChanged{Locked: true}})
+		case valid:
+			unlocked = true
+			if idle := config.AutoLockIdle(); idle > 0 && time.Since(lastActivity()) >= idle {
+				lockWithReason("Store locked after " + idle.String() + " of inactivity")
+				unlocked = false
+			}
+		}
+	}
+}
+
+func lockWithReason(reason string) {
+	slog.Info(reason)
+	if err := lock(reason); err != nil {
+		slog.Error("Failed to lock the store", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+func TestAutoLockAfterIdle(t *testing.T) {
+	t.Cleanup(func() { config.Reload() })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configFile := filepath.Join(home, "config.toml")
+	if err := os.WriteFile(configFile, []byte("[autolock]\nidle = \"1s\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOPASS_CONFIG", configFile)
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gpg.EncryptAndCachePassphrase("secret", getCachePath(), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	setCachedIndex(t)
+
+	locked := make(chan Event, 1)
+	sub := Events.Subscribe(func(ev Event) {
+		if ev.Type == EventLocked {
+			locked <- ev
+		}
+	})
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go AutoLock(ctx)
+
+	select {
+	case ev := <-locked:
+		if _, valid, _ := gpg.DecryptCachedPassphrase(getCachePath()); valid {
+			t.Errorf("passphrase still cached after %q", ev.Message)
+		}
+		if indexCached() {
+			t.Errorf("index still cached after %q", ev.Message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("store was not locked")
+	}
+}
+
+func TestAutoLockOnExpiryDropsIndex(t *testing.T) {
+	t.Cleanup(func() { config.Reload() })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GOPASS_CONFIG", filepath.Join(home, "config.toml"))
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gpg.EncryptAndCachePassphrase("secret", getCachePath(), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	setCachedIndex(t)
+
+	locked := make(chan Event, 1)
+	sub := Events.Subscribe(func(ev Event) {
+		if ev.Type == EventLocked {
+			locked <- ev
+		}
+	})
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go AutoLock(ctx)
+
+	select {
+	case <-locked:
+		if indexCached() {
+			t.Error("index still cached after the passphrase expired")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("store was not locked")
+	}
+}
+
+// setCachedIndex fills the index cache as a query would.
+func setCachedIndex(t *testing.T) {
+	indexCache.mu.Lock()
+	indexCache.idx, indexCache.passphrase = &Index{Entries: map[string]IndexEntry{}}, "secret"
+	indexCache.mu.Unlock()
+	t.Cleanup(invalidateIndexCache)
+}
+
+func indexCached() bool {
+	indexCache.mu.Lock()
+	defer indexCache.mu.Unlock()
+	return indexCache.idx != nil
+}
+
+func TestReportActivityIsShared(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(activityPath()), 0700); err != nil {
+		t.Fatal(err)
+	}
+	lastReported.Store(0)
+
+	ReportActivity()
+	info, err := os.Stat(activityPath())
+	if err != nil {
+		t.Fatalf("activity was not recorded: %v", err)
+	}
+
+	// Another app reporting activity later postpones the lock here too
+	lastReported.Store(time.Now().Add(-time.Hour).UnixNano())
+	later := info.ModTime().Add(time.Minute)
+	if err := os.Chtimes(activityPath(), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastActivity(); !got.Equal(later) {
+		t.Errorf("expected the shared activity %v, got %v", later, got)
+	}
+}
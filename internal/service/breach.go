@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BreachList looks up passwords in an offline copy of the Have I Been Pwned
+// SHA-1 list, either a single file of "HASH:COUNT" lines sorted by hash, or
+// a directory of range files named after the first 5 hex characters of the
+// hash and holding "SUFFIX:COUNT" lines.
+type BreachList struct {
+	path  string
+	isDir bool
+}
+
+func OpenBreachList(path string) (*BreachList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	return &BreachList{path: path, isDir: info.IsDir()}, nil
+}
+
+// Count returns how many times the password appears in breaches, 0 when it
+// was never seen.
+func (b *BreachList) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if b.isDir {
+		return b.rangeCount(hash)
+	}
+	f, err := os.Open(b.path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return sortedFileCount(f, info.Size(), hash)
+}
+
+func (b *BreachList) rangeCount(hash string) (int, error) {
+	prefix, suffix := hash[:5], hash[5:]
+	f, err := os.Open(filepath.Join(b.path, prefix))
+	if os.IsNotExist(err) {
+		if f, err = os.Open(filepath.Join(b.path, prefix+".txt")); os.IsNotExist(err) {
+			return 0, nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if key, count, ok := breachLine(scanner.Text()); ok && key == suffix {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// sortedFileCount binary searches the lines of a file sorted by hash. It
+// seeks instead of reading the file, which is tens of gigabytes.
+func sortedFileCount(r io.ReaderAt, size int64, hash string) (int, error) {
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := lineAt(r, size, mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			// No line starts in [mid, hi), search the lower half
+			hi = mid
+			continue
+		}
+		key, count, ok := breachLine(line)
+		if !ok {
+			return 0, fmt.Errorf("malformed breached password list at offset %d", start)
+		}
+		switch {
+		case key == hash:
+			return count, nil
+		case key < hash:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = start
+		}
+	}
+	return 0, nil
+}
+
+// lineAt returns the first line starting at or after offset.
+func lineAt(r io.ReaderAt, size, offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		// offset may fall in the middle of a line, skip to the next one
+		rest, err := readLine(r, size, offset-1)
+		if err != nil {
+			return 0, "", err
+		}
+		start = offset + int64(len(rest))
+	}
+	if start >= size {
+		return size, "", nil
+	}
+	line, err := readLine(r, size, start)
+	return start, line, err
+}
+
+func readLine(r io.ReaderAt, size, offset int64) (string, error) {
+	var line []byte
+	buf := make([]byte, 128)
+	for pos := offset; pos < size; {
+		n, err := r.ReadAt(buf, pos)
+		if idx := bytes.IndexByte(buf[:n], '\n'); idx >= 0 {
+			return string(append(line, buf[:idx]...)), nil
+		}
+		line = append(line, buf[:n]...)
+		pos += int64(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return string(line), nil
+}
+
+// breachLine parses "HASH:COUNT", the line ending may be CRLF.
+func breachLine(line string) (string, int, bool) {
+	key, value, ok := strings.Cut(strings.TrimRight(line, "\r"), ":")
+	if !ok {
+		return "", 0, false
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.ToUpper(strings.TrimSpace(key)), count, true
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+)
+
+// Clipboard is a clipboard secrets are copied to, provided by each UI.
+type Clipboard interface {
+	// Content returns the clipboard text, ok is false when the clipboard
+	// cannot be read, e.g. a terminal clipboard written with OSC 52.
+	Content() (text string, ok bool)
+	SetContent(text string)
+}
+
+// CopyRequest copies a field of an entry to the clipboard. Entry and Field
+// only name what was copied, for the audit log and the status messages.
+type CopyRequest struct {
+	Clipboard Clipboard
+	Value     string
+	Entry     string
+	Field     string
+	// Timeout defaults to config.ClipboardTimeout, the clipboard is never
+	// cleared when both are 0
+	Timeout time.Duration
+}
+
+type CopyResult struct {
+	// ClearAt is when the clipboard is cleared, zero when it is not
+	ClearAt time.Time
+	Err     error
+}
+
+// pendingCopy is the value waiting to be cleared from the clipboard.
+type pendingCopy struct {
+	board    Clipboard
+	value    string
+	previous string
+	label    string
+	done     chan struct{}
+}
+
+var (
+	clipMu  sync.Mutex
+	pending *pendingCopy
+)
+
+// CopyToClipboard copies a value and, after the timeout, restores what the
+// clipboard held before or clears it. The clipboard is left alone when it no
+// longer holds the copied value. A countdown is published as EventClipboard
+// every second. Copying again before the timeout restarts the countdown and
+// keeps the contents from before the first copy.
+func CopyToClipboard(req CopyRequest) CopyResult {
+	var result CopyResult
+	if req.Clipboard == nil {
+		result.Err = errors.New("no clipboard available")
+		return result
+	}
+	if req.Value == "" {
+		result.Err = fmt.Errorf("%s has no %s to copy", req.Entry, req.Field)
+		return result
+	}
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = config.ClipboardTimeout()
+	}
+
+	p := &pendingCopy{board: req.Clipboard, value: req.Value, label: copyLabel(req), done: make(chan struct{})}
+
+	clipMu.Lock()
+	if pending != nil && pending.board == req.Clipboard {
+		p.previous = pending.previous
+	} else if prev, ok := req.Clipboard.Content(); ok {
+		p.previous = prev
+	}
+	if pending != nil {
+		close(pending.done)
+	}
+	pending = nil
+	req.Clipboard.SetContent(req.Value)
+	if timeout > 0 {
+		pending = p
+		result.ClearAt = time.Now().Add(timeout)
+	}
+	clipMu.Unlock()
+
+	audit.Log(audit.OpCopy, req.Entry, nil)
+	if timeout <= 0 {
+		Events.Publish(Event{Type: EventClipboard, Message: fmt.Sprintf("Copied %s", p.label), Data: ClipboardCountdown{}})
+		return result
+	}
+	go p.countdown(result.ClearAt)
+	return result
+}
+
+// ClearClipboard restores or clears the clipboard now if it still holds a
+// copied value, e.g. when the store locks or the app quits.
+func ClearClipboard() {
+	clipMu.Lock()
+	p := pending
+	clipMu.Unlock()
+	if p != nil {
+		p.clear()
+	}
+}
+
+func (p *pendingCopy) countdown(clearAt time.Time) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	timer := time.NewTimer(time.Until(clearAt))
+	defer timer.Stop()
+
+	for {
+		remaining := time.Until(clearAt).Round(time.Second)
+		Events.Publish(Event{
+			Type:    EventClipboard,
+			Message: fmt.Sprintf("Copied %s, clearing in %s", p.label, remaining),
+			Data:    ClipboardCountdown{Remaining: remaining},
+		})
+		select {
+		case <-p.done:
+			return
+		case <-timer.C:
+			p.clear()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// clear puts back the previous contents unless the clipboard changed since
+// the copy. A clipboard that cannot be read is cleared.
+func (p *pendingCopy) clear() {
+	clipMu.Lock()
+	if pending != p {
+		clipMu.Unlock()
+		return
+	}
+	pending = nil
+	close(p.done)
+
+	msg := "Clipboard cleared"
+	if current, ok := p.board.Content(); ok && current != p.value {
+		msg = "Clipboard changed since the copy, left as is"
+	} else {
+		p.board.SetContent(p.previous)
+		if p.previous != "" {
+			msg = "Clipboard restored"
+		}
+	}
+	clipMu.Unlock()
+
+	Events.Publish(Event{Type: EventClipboard, Message: msg, Data: ClipboardCountdown{Cleared: true}})
+}
+
+func copyLabel(req CopyRequest) string {
+	switch {
+	case req.Entry == "":
+		return req.Field
+	case req.Field == "":
+		return req.Entry
+	}
+	return req.Field + " of " + req.Entry
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClipboard struct {
+	mu        sync.Mutex
+	text      string
+	writeOnly bool
+}
+
+func (c *fakeClipboard) Content() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.text, !c.writeOnly
+}
+
+func (c *fakeClipboard) SetContent(text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.text = text
+}
+
+func (c *fakeClipboard) get() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.text
+}
+
+// waitCleared waits for the clipboard of the pending copy to be cleared.
+func waitCleared(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		clipMu.Lock()
+		p := pending
+		clipMu.Unlock()
+		if p == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("clipboard was not cleared")
+}
+
+func TestCopyToClipboardRestoresPrevious(t *testing.T) {
+	board := &fakeClipboard{text: "previous"}
+	result := CopyToClipboard(CopyRequest{Clipboard: board, Value: "s3cret", Entry: "github", Field: "password", Timeout: 50 * time.Millisecond})
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if got := board.get(); got != "s3cret" {
+		t.Fatalf("expected the secret in the clipboard, got %q", got)
+	}
+
+	// A second copy keeps what was there before the first one
+	CopyToClipboard(CopyRequest{Clipboard: board, Value: "other", Timeout: 50 * time.Millisecond})
+	waitCleared(t)
+	if got := board.get(); got != "previous" {
+		t.Errorf("expected the previous contents back, got %q", got)
+	}
+}
+
+func TestCopyToClipboardLeavesChangedClipboard(t *testing.T) {
+	board := &fakeClipboard{text: "previous"}
+	CopyToClipboard(CopyRequest{Clipboard: board, Value: "s3cret", Timeout: 50 * time.Millisecond})
+	board.SetContent("copied by the user")
+	waitCleared(t)
+	if got := board.get(); got != "copied by the user" {
+		t.Errorf("expected the clipboard to be left alone, got %q", got)
+	}
+}
+
+func TestCopyToClipboardClearsWriteOnly(t *testing.T) {
+	board := &fakeClipboard{text: "previous", writeOnly: true}
+	CopyToClipboard(CopyRequest{Clipboard: board, Value: "s3cret", Timeout: time.Hour})
+	ClearClipboard()
+	if got := board.get(); got != "" {
+		t.Errorf("expected a cleared clipboard, got %q", got)
+	}
+}
+
+func TestCopyToClipboardErrors(t *testing.T) {
+	if result := CopyToClipboard(CopyRequest{Value: "s3cret"}); result.Err == nil {
+		t.Error("expected an error without a clipboard")
+	}
+	if result := CopyToClipboard(CopyRequest{Clipboard: &fakeClipboard{}, Entry: "github", Field: "password"}); result.Err == nil {
+		t.Error("expected an error for an empty value")
+	}
+}
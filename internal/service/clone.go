@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/gpg"
+	"github.com/duykhoa/gopass/internal/store"
+)
+
+// CloneResult is a cloned store and whether the user can read it.
+type CloneResult struct {
+	// Recipients are the keys of the .gpg-id of the store
+	Recipients []string
+	// DecryptKey is the recipient the user has the secret key of, empty when
+	// the entries are not encrypted for the user yet
+	DecryptKey string
+	// Key is the user's key to send to an admin of the store when DecryptKey
+	// is empty, PublicKey is its armored public key. Both are empty when the
+	// user has no usable secret key.
+	Key       gpg.KeyInfo
+	PublicKey string
+	Err       error
+}
+
+// CanDecrypt reports whether the user can read the entries of the store.
+func (r CloneResult) CanDecrypt() bool {
+	return r.DecryptKey != ""
+}
+
+// AccessHelp explains how to get access to a store the user cannot read yet,
+// it is empty when the user can.
+func (r CloneResult) AccessHelp() string {
+	switch {
+	case r.Err != nil || r.CanDecrypt():
+		return ""
+	case r.Key.KeyID == "":
+		return fmt.Sprintf("None of your keys can decrypt this store, it is encrypted for %s. "+
+			"Create a key with 'gpg --full-generate-key' and send its public key to an admin of the store.",
+			strings.Join(r.Recipients, ", "))
+	}
+	return fmt.Sprintf("None of your keys can decrypt this store, it is encrypted for %s. "+
+		"Send your public key %s (fingerprint %s) to an admin of the store, "+
+		"who adds it to .gpg-id and re-encrypts the entries. Sync once it is done.",
+		strings.Join(r.Recipients, ", "), r.Key, r.Key.Fingerprint)
+}
+
+// CloneStore clones an existing password store into baseDir, see store.Clone,
+// and checks whether one of the user's secret keys can decrypt it.
+func CloneStore(baseDir, remoteURL string) CloneResult {
+	err := store.Clone(baseDir, remoteURL)
+	audit.Log(audit.OpClone, baseDir, err)
+	if err != nil {
+		return CloneResult{Err: err}
+	}
+	reloadConfig()
+
+	var result CloneResult
+	result.Recipients, result.Err = store.Recipients(baseDir)
+	if result.Err != nil {
+		return result
+	}
+	for _, recipient := range result.Recipients {
+		if gpg.HasSecretKey(recipient) {
+			result.DecryptKey = recipient
+			return result
+		}
+	}
+
+	keys, err := gpg.ListSecretKeys()
+	if err != nil {
+		slog.Error("Failed to list secret keys", slog.Any("error", err))
+	}
+	for _, key := range keys {
+		if !key.Usable() {
+			continue
+		}
+		result.Key = key
+		if result.PublicKey, err = gpg.ArmoredPublicKey(key.Fingerprint); err != nil {
+			slog.Error("Failed to export public key", slog.String("key", key.KeyID), slog.Any("error", err))
+		}
+		break
+	}
+	return result
+}
@@ -4,10 +4,13 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/duykhoa/gopass/internal/audit"
 	"github.com/duykhoa/gopass/internal/gpg"
 )
 
 type DecryptRequest struct {
+	// StoreDir is the store the entry is read from, when empty the entry is
+	// read from the store mounted at its prefix
 	StoreDir   string
 	Entry      string
 	Passphrase string
@@ -22,7 +25,20 @@ type DecryptResult struct {
 // Decrypt handles decryption.
 func Decrypt(req DecryptRequest) DecryptResult {
 	gpgFile := filepath.Join(req.StoreDir, req.Entry+".gpg")
+	if req.StoreDir == "" {
+		gpgFile = EntryPath(req.Entry)
+	}
 	plaintext, err := gpg.DecryptGPGFileWithKey(gpgFile, req.Passphrase)
+	audit.Log(audit.OpDecrypt, req.Entry, err)
+
+	return DecryptResult{plaintext, err}
+}
+
+// decryptWith decrypts an entry with the keys unlocked by d, e.g. shared by
+// the workers of Grep.
+func decryptWith(d *gpg.Decrypter, entry string) DecryptResult {
+	plaintext, err := d.DecryptFile(EntryPath(entry))
+	audit.Log(audit.OpDecrypt, entry, err)
 
 	return DecryptResult{plaintext, err}
 }
@@ -2,14 +2,13 @@ package service
 
 import (
 	"log/slog"
-	"time"
+	"os"
 
+	"github.com/duykhoa/gopass/internal/audit"
 	"github.com/duykhoa/gopass/internal/config"
 	"github.com/duykhoa/gopass/internal/gpg"
 )
 
-const cacheDuration = 30 * time.Minute
-
 // GetCachedPassphrase returns the cached passphrase and whether it is valid.
 func GetCachedPassphrase() (string, bool) {
 	pass, valid, err := gpg.DecryptCachedPassphrase(getCachePath())
@@ -20,7 +19,6 @@ func GetCachedPassphrase() (string, bool) {
 // DecryptAndCacheIfOk attempts decryption, and if successful, caches the passphrase.
 func DecryptAndCacheIfOk(entry, passphrase string) DecryptResult {
 	req := DecryptRequest{
-		StoreDir:   config.PasswordStoreDir(),
 		Entry:      entry,
 		Passphrase: passphrase,
 		CachePath:  getCachePath(),
@@ -34,7 +32,38 @@ func DecryptAndCacheIfOk(entry, passphrase string) DecryptResult {
 
 // CachePassphrase stores the passphrase in the cache file.
 func CachePassphrase(passphrase string) error {
-	return gpg.EncryptAndCachePassphrase(passphrase, getCachePath(), cacheDuration)
+	_, wasUnlocked, _ := gpg.DecryptCachedPassphrase(getCachePath())
+	if err := gpg.EncryptAndCachePassphrase(passphrase, getCachePath(), config.CacheTTL()); err != nil {
+		return err
+	}
+	if !wasUnlocked {
+		Events.Publish(Event{Type: EventUnlocked, Message: "Store unlocked", Data: LockStateChanged{Locked: false}})
+	}
+
+	return nil
+}
+
+// Lock removes the cached passphrase and clears a copied secret from the
+// clipboard, the next decryption prompts again.
+func Lock() error {
+	return lock("Store locked")
+}
+
+func lock(message string) error {
+	// The decrypted index must not outlive the passphrase
+	invalidateIndexCache()
+	err := os.Remove(getCachePath())
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	audit.Log(audit.OpLock, "", err)
+	if err != nil {
+		return err
+	}
+	ClearClipboard()
+	Events.Publish(Event{Type: EventLocked, Message: message, Data: LockStateChanged{Locked: true}})
+
+	return nil
 }
 
 func getCachePath() string {
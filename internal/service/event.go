@@ -1,35 +1,177 @@
 package service
 
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
 type EventType string
 
 const (
 	EventError   EventType = "error"
 	EventSuccess EventType = "success"
 	EventInfo    EventType = "info"
+
+	EventEntryCreated  EventType = "entry_created"
+	EventEntryUpdated  EventType = "entry_updated"
+	EventEntryDeleted  EventType = "entry_deleted"
+	EventEntryMoved    EventType = "entry_moved"
+	EventSyncProgress  EventType = "sync_progress"
+	EventSyncCompleted EventType = "sync_completed"
+	EventLocked        EventType = "locked"
+	EventUnlocked      EventType = "unlocked"
+	EventAuditProgress EventType = "audit_progress"
+	EventClipboard     EventType = "clipboard"
 )
 
+// Payload is the typed data attached to an event, one of EntryChanged,
+// SyncProgress, LockStateChanged, AuditProgress, ClipboardCountdown or Error.
+type Payload interface {
+	eventPayload()
+}
+
+// EntryChanged is the payload of the entry events. OldEntry is only set when
+// an entry was moved.
+type EntryChanged struct {
+	Entry    string `json:"entry"`
+	OldEntry string `json:"old_entry,omitempty"`
+}
+
+// SyncProgress is the payload of the sync events.
+type SyncProgress struct {
+	Stage string `json:"stage"`
+	Done  bool   `json:"done"`
+}
+
+// LockStateChanged is the payload of EventLocked and EventUnlocked.
+type LockStateChanged struct {
+	Locked bool `json:"locked"`
+}
+
+// AuditProgress is the payload of EventAuditProgress.
+type AuditProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// ClipboardCountdown is the payload of EventClipboard, Remaining is 0 when
+// the clipboard is not cleared.
+type ClipboardCountdown struct {
+	Remaining time.Duration `json:"remaining"`
+	Cleared   bool          `json:"cleared"`
+}
+
+// Error is the payload of EventError.
+type Error struct {
+	Err error `json:"-"`
+}
+
+func (EntryChanged) eventPayload()       {}
+func (SyncProgress) eventPayload()       {}
+func (LockStateChanged) eventPayload()   {}
+func (AuditProgress) eventPayload()      {}
+func (ClipboardCountdown) eventPayload() {}
+func (Error) eventPayload()              {}
+
 type Event struct {
 	Type    EventType
 	Message string
-	Data    interface{}
+	Data    Payload
+}
+
+// IsEntryEvent reports whether the event means the list of entries may have
+// changed.
+func (e Event) IsEntryEvent() bool {
+	switch e.Type {
+	case EventEntryCreated, EventEntryUpdated, EventEntryDeleted, EventEntryMoved, EventSyncCompleted:
+		return true
+	}
+	return false
 }
 
 type Subscriber func(Event)
 
+// subscriberBuffer is how many events may queue up for a slow subscriber
+// before new events are dropped for it.
+const subscriberBuffer = 64
+
+// Subscription is returned by Subscribe, it delivers events to its
+// Subscriber on a dedicated goroutine.
+type Subscription struct {
+	ps     *PubSub
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Unsubscribe stops the delivery of events. Events already queued are
+// discarded. It is safe to call more than once.
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		s.ps.mu.Lock()
+		delete(s.ps.subscriptions, s)
+		s.ps.mu.Unlock()
+		close(s.done)
+	})
+}
+
+func (s *Subscription) run(sub Subscriber) {
+	for {
+		select {
+		case <-s.done:
+			return
+		case ev := <-s.events:
+			select {
+			case <-s.done:
+				return
+			default:
+				sub(ev)
+			}
+		}
+	}
+}
+
+// PubSub is a concurrency-safe event bus. Publish never blocks: every
+// subscriber has its own buffered queue, and events are dropped for a
+// subscriber whose queue is full.
 type PubSub struct {
-	subscribers []Subscriber
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
 }
 
 func NewPubSub() *PubSub {
-	return &PubSub{}
+	return &PubSub{subscriptions: map[*Subscription]struct{}{}}
 }
 
-func (ps *PubSub) Subscribe(sub Subscriber) {
-	ps.subscribers = append(ps.subscribers, sub)
+func (ps *PubSub) Subscribe(sub Subscriber) *Subscription {
+	s := &Subscription{
+		ps:     ps,
+		events: make(chan Event, subscriberBuffer),
+		done:   make(chan struct{}),
+	}
+
+	ps.mu.Lock()
+	ps.subscriptions[s] = struct{}{}
+	ps.mu.Unlock()
+
+	go s.run(sub)
+
+	return s
 }
 
 func (ps *PubSub) Publish(event Event) {
-	for _, sub := range ps.subscribers {
-		sub(event)
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	for s := range ps.subscriptions {
+		select {
+		case s.events <- event:
+		default:
+			slog.Warn("Dropping event for slow subscriber", slog.String("type", string(event.Type)))
+		}
 	}
 }
+
+// Events is the process-wide bus service operations publish store changes to.
+var Events = NewPubSub()
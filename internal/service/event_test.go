@@ -0,0 +1,93 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPubSubDeliversInOrder(t *testing.T) {
+	ps := NewPubSub()
+	got := make(chan Event, 10)
+	sub := ps.Subscribe(func(ev Event) { got <- ev })
+	defer sub.Unsubscribe()
+
+	for _, entry := range []string{"a", "b", "c"} {
+		ps.Publish(Event{Type: EventEntryCreated, Message: entry, Data: EntryChanged{Entry: entry}})
+	}
+
+	for _, want := range []string{"a", "b", "c"} {
+		select {
+		case ev := <-got:
+			changed, ok := ev.Data.(EntryChanged)
+			if !ok || changed.Entry != want {
+				t.Errorf("expected EntryChanged for %s, got %#v", want, ev.Data)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func TestPubSubSlowSubscriberDoesNotBlock(t *testing.T) {
+	ps := NewPubSub()
+	block := make(chan struct{})
+	slow := ps.Subscribe(func(Event) { <-block })
+	defer func() {
+		close(block)
+		slow.Unsubscribe()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < subscriberBuffer*4; i++ {
+			ps.Publish(Event{Type: EventSyncProgress, Data: SyncProgress{Stage: "pulling"}})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+}
+
+func TestPubSubUnsubscribe(t *testing.T) {
+	ps := NewPubSub()
+	got := make(chan Event, 10)
+	sub := ps.Subscribe(func(ev Event) { got <- ev })
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+
+	ps.Publish(Event{Type: EventLocked, Data: LockStateChanged{Locked: true}})
+
+	select {
+	case ev := <-got:
+		t.Errorf("received event after unsubscribe: %#v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPubSubConcurrentUse(t *testing.T) {
+	ps := NewPubSub()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			sub := ps.Subscribe(func(Event) {})
+			for j := 0; j < 50; j++ {
+				ps.Publish(Event{Type: EventInfo})
+			}
+			sub.Unsubscribe()
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				ps.Publish(Event{Type: EventError, Data: Error{}})
+			}
+		}()
+	}
+	wg.Wait()
+}
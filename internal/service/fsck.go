@@ -0,0 +1,353 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+type ProblemKind string
+
+const (
+	ProblemMissingGPGId    ProblemKind = "missing_gpg_id"
+	ProblemUnreadable      ProblemKind = "unreadable"
+	ProblemWrongRecipients ProblemKind = "wrong_recipients"
+	ProblemPermissions     ProblemKind = "permissions"
+	ProblemPlaintext       ProblemKind = "plaintext"
+	ProblemUnknownFile     ProblemKind = "unknown_file"
+	ProblemMissingStore    ProblemKind = "missing_store"
+	ProblemUnsignedGPGId   ProblemKind = "unsigned_gpg_id"
+)
+
+const (
+	entryFileMode           = 0600
+	storeDirMode            = 0700
+	plaintextEntryExtension = ".txt"
+)
+
+// Problem is an issue found by Fsck. Path is in the entry namespace, files
+// of mounted stores are prefixed with the mount point.
+type Problem struct {
+	Path     string
+	Kind     ProblemKind
+	Severity Severity
+	Message  string
+	Fixed    bool
+	FixErr   error
+}
+
+type FsckRequest struct {
+	// Passphrase, when set, decrypts every entry to find unreadable ones and
+	// is needed to re-encrypt entries
+	Passphrase string
+	// Fix re-encrypts entries for the store key and fixes permissions
+	Fix bool
+	// RemovePlaintext encrypts plaintext entries and deletes the plaintext
+	// files, callers must have the user confirm it
+	RemovePlaintext bool
+}
+
+type FsckResult struct {
+	Problems []Problem
+	Checked  int
+	Err      error
+}
+
+// Count returns the number of problems of the given severity.
+func (r FsckResult) Count(severity Severity) int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// knownStoreFiles are the non entry files pass and gopass keep in a store.
+var knownStoreFiles = map[string]bool{
+	".gpg-id":        true,
+	".gitattributes": true,
+	".gitignore":     true,
+}
+
+// Fsck scans every store for broken state: a missing .gpg-id, entries that
+// are not OpenPGP messages or are encrypted to other keys than the store key,
+// loose permissions, stray plaintext files and .gpg-id files without a
+// trusted signature. Problems are fixed as requested.
+func Fsck(req FsckRequest) FsckResult {
+	var result FsckResult
+
+	if _, err := os.Stat(config.PasswordStoreDir()); err != nil {
+		return FsckResult{Err: fmt.Errorf("password store not found: %w", err)}
+	}
+	for _, m := range config.Mounts() {
+		if err := result.checkStore(req, m); err != nil {
+			result.Err = err
+			break
+		}
+	}
+
+	sort.SliceStable(result.Problems, func(i, j int) bool { return result.Problems[i].Path < result.Problems[j].Path })
+	return result
+}
+
+func (r *FsckResult) checkStore(req FsckRequest, m config.Mount) error {
+	if _, err := os.Stat(m.Path); err != nil {
+		r.Problems = append(r.Problems, Problem{
+			Path:     m.Prefix,
+			Kind:     ProblemMissingStore,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("the store %s is mounted from %s, which does not exist", m.Name, m.Path),
+		})
+		return nil
+	}
+
+	hasGPGId := true
+	if _, err := os.Stat(filepath.Join(m.Path, ".gpg-id")); err != nil || m.GPGId == "" {
+		hasGPGId = false
+		r.Problems = append(r.Problems, Problem{
+			Path:     m.Entry(".gpg-id"),
+			Kind:     ProblemMissingGPGId,
+			Severity: SeverityError,
+			Message:  "the store has no .gpg-id, entries cannot be encrypted; run init again",
+		})
+	}
+	// Folders can have their own .gpg-id, the keys are loaded once per file
+	keysOf := map[string][]map[uint64]bool{}
+	folderKeys := func(gpgId string) ([]map[uint64]bool, error) {
+		if !hasGPGId {
+			return nil, nil
+		}
+		if keys, ok := keysOf[gpgId]; ok {
+			return keys, nil
+		}
+		var keys []map[uint64]bool
+		for _, recipient := range gpg.ParseRecipients(gpgId) {
+			ids, err := gpg.PublicKeyIDs(recipient)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the key %s of store %s: %w", recipient, m.Name, err)
+			}
+			recipientKeys := map[uint64]bool{}
+			for _, id := range ids {
+				recipientKeys[id] = true
+			}
+			keys = append(keys, recipientKeys)
+		}
+		keysOf[gpgId] = keys
+		return keys, nil
+	}
+
+	// Stores mounted inside this one are checked on their own
+	nested := nestedMounts(m)
+
+	return filepath.WalkDir(m.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(m.Path, path)
+		rel = filepath.Join(m.Prefix, rel)
+		name := d.Name()
+		if d.IsDir() {
+			if path != m.Path && (strings.HasPrefix(name, ".") || nested[filepath.Clean(path)]) {
+				return filepath.SkipDir
+			}
+			r.checkMode(req, path, rel, storeDirMode)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		switch {
+		case strings.HasSuffix(name, ".gpg"):
+			r.Checked++
+			r.checkMode(req, path, rel, entryFileMode)
+			gpgId := entryGPGId(strings.TrimSuffix(filepath.ToSlash(rel), ".gpg"))
+			keys, err := folderKeys(gpgId)
+			if err != nil {
+				return err
+			}
+			r.checkEntry(req, path, rel, gpgId, keys)
+		case strings.HasSuffix(name, plaintextEntryExtension):
+			r.checkPlaintext(req, path, rel)
+		case name == ".gpg-id":
+			// Signing it is left to the user, who has to check its keys first
+			if err := gpg.VerifyGPGId(path); err != nil {
+				r.Problems = append(r.Problems, Problem{Path: rel, Kind: ProblemUnsignedGPGId, Severity: SeverityError, Message: err.Error()})
+			}
+		case knownStoreFiles[name] || strings.HasPrefix(name, "."):
+		default:
+			r.Problems = append(r.Problems, Problem{
+				Path:     rel,
+				Kind:     ProblemUnknownFile,
+				Severity: SeverityInfo,
+				Message:  "not a password entry",
+			})
+		}
+		return nil
+	})
+}
+
+// checkMode reports files and folders readable by other users. Windows has
+// no permission bits.
+func (r *FsckResult) checkMode(req FsckRequest, path, rel string, want fs.FileMode) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0077 == 0 {
+		return
+	}
+
+	p := Problem{
+		Path:     rel,
+		Kind:     ProblemPermissions,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("permissions are %04o, expected %04o", info.Mode().Perm(), want),
+	}
+	if req.Fix {
+		p.FixErr = os.Chmod(path, want)
+		p.Fixed = p.FixErr == nil
+	}
+	r.Problems = append(r.Problems, p)
+}
+
+// checkEntry checks that an entry is encrypted for every recipient of gpgId,
+// keys are the key ids of each recipient.
+func (r *FsckResult) checkEntry(req FsckRequest, path, rel, gpgId string, keys []map[uint64]bool) {
+	ciphertext, err := os.ReadFile(path)
+	if err != nil {
+		r.Problems = append(r.Problems, Problem{Path: rel, Kind: ProblemUnreadable, Severity: SeverityError, Message: err.Error()})
+		return
+	}
+	recipients, err := gpg.MessageRecipients(ciphertext)
+	if err != nil {
+		r.Problems = append(r.Problems, Problem{
+			Path:     rel,
+			Kind:     ProblemUnreadable,
+			Severity: SeverityError,
+			Message:  "not an encrypted entry: " + err.Error(),
+		})
+		return
+	}
+
+	if keys == nil || encryptedToAll(recipients, keys) {
+		if req.Passphrase == "" {
+			return
+		}
+		if _, err := gpg.DecryptGPGFileWithKey(path, req.Passphrase); err != nil {
+			r.Problems = append(r.Problems, Problem{
+				Path:     rel,
+				Kind:     ProblemUnreadable,
+				Severity: SeverityError,
+				Message:  "cannot be decrypted: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	ids := make([]string, 0, len(recipients))
+	for _, id := range recipients {
+		ids = append(ids, fmt.Sprintf("%016X", id))
+	}
+	p := Problem{
+		Path:     rel,
+		Kind:     ProblemWrongRecipients,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("encrypted to %s, not to the store keys %s", strings.Join(ids, ", "), strings.Join(gpg.ParseRecipients(gpgId), ", ")),
+	}
+	if req.Fix {
+		p.FixErr = reencryptEntry(path, rel, req.Passphrase)
+		p.Fixed = p.FixErr == nil
+	}
+	r.Problems = append(r.Problems, p)
+}
+
+func encryptedToAll(recipients []uint64, keys []map[uint64]bool) bool {
+	for _, recipientKeys := range keys {
+		if !encryptedTo(recipients, recipientKeys) {
+			return false
+		}
+	}
+	return true
+}
+
+func encryptedTo(recipients []uint64, keys map[uint64]bool) bool {
+	for _, id := range recipients {
+		if keys[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FsckResult) checkPlaintext(req FsckRequest, path, rel string) {
+	p := Problem{
+		Path:     rel,
+		Kind:     ProblemPlaintext,
+		Severity: SeverityError,
+		Message:  "plaintext entry, anyone with access to the store can read it",
+	}
+	if req.RemovePlaintext {
+		p.FixErr = encryptPlaintextEntry(path, rel, entryRecipients(strings.TrimSuffix(filepath.ToSlash(rel), plaintextEntryExtension)))
+		p.Fixed = p.FixErr == nil
+	}
+	r.Problems = append(r.Problems, p)
+}
+
+// reencryptEntry decrypts an entry and writes it again for the store key.
+func reencryptEntry(path, rel, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("the passphrase is needed to re-encrypt the entry")
+	}
+	entry := strings.TrimSuffix(filepath.ToSlash(rel), ".gpg")
+	plaintext, err := gpg.DecryptGPGFileWithKey(path, passphrase)
+	if err == nil {
+		err = writeEntry(path, entryRecipients(entry), []byte(plaintext))
+	}
+	audit.Log(audit.OpFsck, entry, err)
+	return err
+}
+
+// encryptPlaintextEntry encrypts a plaintext file into the entry of the same
+// name and deletes the plaintext. It refuses to replace an existing entry.
+func encryptPlaintextEntry(path, rel string, recipients gpg.Recipients) error {
+	entryPath := strings.TrimSuffix(path, plaintextEntryExtension) + ".gpg"
+	if _, err := os.Stat(entryPath); err == nil {
+		return fmt.Errorf("%s already exists, merge the plaintext by hand", filepath.Base(entryPath))
+	}
+	content, err := os.ReadFile(path)
+	if err == nil {
+		err = writeEntry(entryPath, recipients, content)
+	}
+	if err == nil {
+		err = os.Remove(path)
+	}
+	entry := strings.TrimSuffix(filepath.ToSlash(rel), plaintextEntryExtension)
+	audit.Log(audit.OpFsck, entry, err)
+	if err != nil {
+		return err
+	}
+	indexPut(entry, string(content))
+	Events.Publish(Event{Type: EventEntryCreated, Message: entry, Data: EntryChanged{Entry: entry}})
+
+	return nil
+}
@@ -5,9 +5,19 @@ import (
 	"github.com/go-git/go-git/v5"
 )
 
-// GetGitStatus returns a string describing if there are uncommitted changes or if local branch is ahead.
+// GetGitStatus returns a string describing if there are uncommitted changes or if local branch is ahead,
+// in any of the stores.
 func GetGitStatus() string {
-	repo, err := git.PlainOpen(config.PasswordStoreDir())
+	for _, m := range config.Mounts() {
+		if status := storeGitStatus(m.Path); status != "" {
+			return storeLabel(m, status)
+		}
+	}
+	return ""
+}
+
+func storeGitStatus(storeDir string) string {
+	repo, err := git.PlainOpen(storeDir)
 	if err != nil {
 		return ""
 	}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+type GrepRequest struct {
+	Pattern    string
+	IgnoreCase bool
+	Passphrase string
+	// Entries limits the search, all entries are searched when empty
+	Entries []string
+	Workers int
+}
+
+// GrepMatch is a line of a decrypted entry matching the pattern. Err is set
+// instead when the entry could not be decrypted.
+type GrepMatch struct {
+	Entry string
+	Field string
+	Line  string
+	Err   error
+}
+
+// Grep decrypts entries in parallel and streams the lines matching the
+// pattern. The channel is closed when every entry was searched or ctx is
+// cancelled.
+func Grep(ctx context.Context, req GrepRequest) (<-chan GrepMatch, error) {
+	pattern := req.Pattern
+	if req.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	entries := req.Entries
+	if len(entries) == 0 {
+		entries, err = ListEntries()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	workers := req.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	d, err := unlockEntryKeys(entries, req.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make(chan string)
+	matches := make(chan GrepMatch)
+
+	send := func(m GrepMatch) bool {
+		select {
+		case matches <- m:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				result := decryptWith(d, entry)
+				if result.Err != nil {
+					if !send(GrepMatch{Entry: entry, Err: result.Err}) {
+						return
+					}
+					continue
+				}
+				for _, m := range grepContent(entry, result.Plaintext, re) {
+					if !send(m) {
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, entry := range entries {
+			select {
+			case jobs <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		d.Close()
+		close(matches)
+	}()
+
+	return matches, nil
+}
+
+// unlockEntryKeys unlocks the secret keys of the folders of entries once, so
+// that the workers share them instead of each exporting and unlocking the
+// keys. It fails when no key can be unlocked, e.g. for a wrong passphrase.
+func unlockEntryKeys(entries []string, passphrase string) (*gpg.Decrypter, error) {
+	d := gpg.NewDecrypter(passphrase)
+	var firstErr error
+	unlocked := false
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		gpgId := entryGPGId(entry)
+		if seen[gpgId] {
+			continue
+		}
+		seen[gpgId] = true
+		if err := d.Unlock(gpgId); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		unlocked = true
+	}
+	if !unlocked && firstErr != nil {
+		d.Close()
+		return nil, firstErr
+	}
+	return d, nil
+}
+
+func grepContent(entry, content string, re *regexp.Regexp) []GrepMatch {
+	var matches []GrepMatch
+	for _, field := range ParseEntry(content).Fields {
+		for _, line := range strings.Split(field.Value, "\n") {
+			if re.MatchString(line) {
+				matches = append(matches, GrepMatch{Entry: entry, Field: field.Name, Line: line})
+			}
+		}
+	}
+	return matches
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/git"
+)
+
+type AuditRequest struct {
+	Passphrase string
+	// Entries limits the audit, all entries are audited when empty
+	Entries []string
+	Workers int
+	// MaxAge reports passwords not changed for longer, 0 disables the check
+	MaxAge time.Duration
+	// BreachFile is an offline HIBP SHA-1 list, see OpenBreachList. The
+	// breach check is skipped when empty.
+	BreachFile string
+}
+
+type WeakPassword struct {
+	Entry   string
+	Score   int
+	Entropy float64
+}
+
+type OldPassword struct {
+	Entry       string
+	LastChanged time.Time
+}
+
+type BreachedPassword struct {
+	Entry string
+	Count int
+}
+
+// AuditReport is the result of Audit. It only holds entry names, never the
+// passwords.
+type AuditReport struct {
+	Total    int
+	Weak     []WeakPassword
+	Reused   [][]string
+	Old      []OldPassword
+	Breached []BreachedPassword
+	// Errors are the entries that could not be decrypted or checked
+	Errors map[string]error
+}
+
+// Issues returns the number of problems found.
+func (r AuditReport) Issues() int {
+	n := len(r.Weak) + len(r.Old) + len(r.Breached)
+	for _, group := range r.Reused {
+		n += len(group)
+	}
+	return n
+}
+
+// passwordOf returns the password of decrypted content: the password field
+// of a template, or the first line like pass does.
+func passwordOf(content string) string {
+	parsed := ParseEntry(content)
+	if tmpl := GetTemplateByName(parsed.Template); tmpl != nil && isTemplateField(tmpl, "password") {
+		return parsed.Get("password")
+	}
+	first, _, _ := strings.Cut(parsed.Get("content"), "\n")
+	return strings.TrimSpace(first)
+}
+
+// Audit decrypts entries in parallel and reports weak, reused, old and
+// breached passwords, publishing EventAuditProgress as entries are checked.
+// Reuse is detected by comparing HMACs under a random key that only lives
+// for the duration of the audit, passwords are not kept in memory.
+func Audit(ctx context.Context, req AuditRequest) (AuditReport, error) {
+	entries := req.Entries
+	if len(entries) == 0 {
+		var err error
+		if entries, err = ListEntries(); err != nil {
+			return AuditReport{}, err
+		}
+	}
+
+	var breaches *BreachList
+	if req.BreachFile != "" {
+		var err error
+		if breaches, err = OpenBreachList(req.BreachFile); err != nil {
+			return AuditReport{}, err
+		}
+	}
+
+	hashKey := make([]byte, 32)
+	if _, err := rand.Read(hashKey); err != nil {
+		return AuditReport{}, err
+	}
+
+	workers := req.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	report := AuditReport{Total: len(entries), Errors: map[string]error{}}
+	hashes := map[string][]string{}
+	var mu sync.Mutex
+	done := 0
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				result := Decrypt(DecryptRequest{Entry: entry, Passphrase: req.Passphrase})
+				var password string
+				if result.Err == nil {
+					password = passwordOf(result.Plaintext)
+				}
+
+				var weak *WeakPassword
+				var hash string
+				var breached int
+				err := result.Err
+				if password != "" {
+					if score, entropy := PasswordStrength(password); score <= StrengthWeak {
+						weak = &WeakPassword{Entry: entry, Score: score, Entropy: entropy}
+					}
+					mac := hmac.New(sha256.New, hashKey)
+					mac.Write([]byte(password))
+					hash = string(mac.Sum(nil))
+					if breaches != nil {
+						breached, err = breaches.Count(password)
+					}
+				}
+
+				mu.Lock()
+				if err != nil {
+					report.Errors[entry] = err
+				}
+				if weak != nil {
+					report.Weak = append(report.Weak, *weak)
+				}
+				if hash != "" {
+					hashes[hash] = append(hashes[hash], entry)
+				}
+				if breached > 0 {
+					report.Breached = append(report.Breached, BreachedPassword{Entry: entry, Count: breached})
+				}
+				done++
+				progress := AuditProgress{Done: done, Total: len(entries)}
+				mu.Unlock()
+
+				Events.Publish(Event{Type: EventAuditProgress, Message: entry, Data: progress})
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, entry := range entries {
+			select {
+			case jobs <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return AuditReport{}, err
+	}
+
+	for _, group := range hashes {
+		if len(group) > 1 {
+			sort.Strings(group)
+			report.Reused = append(report.Reused, group)
+		}
+	}
+	sort.Slice(report.Reused, func(i, j int) bool { return report.Reused[i][0] < report.Reused[j][0] })
+	sort.Slice(report.Weak, func(i, j int) bool { return report.Weak[i].Entry < report.Weak[j].Entry })
+	sort.Slice(report.Breached, func(i, j int) bool { return report.Breached[i].Count > report.Breached[j].Count })
+
+	if req.MaxAge > 0 {
+		old, err := oldPasswords(entries, req.MaxAge)
+		if err != nil {
+			return report, err
+		}
+		report.Old = old
+	}
+
+	return report, nil
+}
+
+// oldPasswords uses the last commit touching an entry file as the time its
+// password changed. Entries not committed yet were changed just now, and so
+// are the entries of stores that are not git repositories.
+func oldPasswords(entries []string, maxAge time.Duration) ([]OldPassword, error) {
+	// Paths are relative to the repository of each store
+	paths := map[string]map[string]string{}
+	mounts := map[string]config.Mount{}
+	for _, entry := range entries {
+		m, rel := config.MountFor(entry)
+		if paths[m.Name] == nil {
+			paths[m.Name] = map[string]string{}
+			mounts[m.Name] = m
+		}
+		paths[m.Name][rel+".gpg"] = entry
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var old []OldPassword
+	for name, byPath := range paths {
+		if !IsGitStore(mounts[name]) {
+			continue
+		}
+		storePaths := make([]string, 0, len(byPath))
+		for path := range byPath {
+			storePaths = append(storePaths, path)
+		}
+		modified, err := git.LastModified(mounts[name].Path, storePaths)
+		if err != nil {
+			return nil, err
+		}
+		for path, changed := range modified {
+			if changed.Before(cutoff) {
+				old = append(old, OldPassword{Entry: byPath[path], LastChanged: changed})
+			}
+		}
+	}
+	sort.Slice(old, func(i, j int) bool { return old[i].LastChanged.Before(old[j].LastChanged) })
+
+	return old, nil
+}
+
+// Format writes the report as text, for the CLI and the terminal UI.
+func (r AuditReport) Format(w io.Writer) {
+	fmt.Fprintf(w, "Audited %d entries, %d issues\n", r.Total, r.Issues())
+
+	fmt.Fprintf(w, "\nWeak passwords (%d)\n", len(r.Weak))
+	for _, weak := range r.Weak {
+		fmt.Fprintf(w, "  %s (%.0f bits)\n", weak.Entry, weak.Entropy)
+	}
+	fmt.Fprintf(w, "\nReused passwords (%d groups)\n", len(r.Reused))
+	for _, group := range r.Reused {
+		fmt.Fprintf(w, "  %s\n", strings.Join(group, ", "))
+	}
+	fmt.Fprintf(w, "\nOld passwords (%d)\n", len(r.Old))
+	for _, old := range r.Old {
+		fmt.Fprintf(w, "  %s (changed %s)\n", old.Entry, old.LastChanged.Local().Format(time.DateOnly))
+	}
+	fmt.Fprintf(w, "\nBreached passwords (%d)\n", len(r.Breached))
+	for _, breached := range r.Breached {
+		fmt.Fprintf(w, "  %s (seen %d times)\n", breached.Entry, breached.Count)
+	}
+	if len(r.Errors) > 0 {
+		fmt.Fprintf(w, "\nNot checked (%d)\n", len(r.Errors))
+		entries := make([]string, 0, len(r.Errors))
+		for entry := range r.Errors {
+			entries = append(entries, entry)
+		}
+		sort.Strings(entries)
+		for _, entry := range entries {
+			fmt.Fprintf(w, "  %s: %v\n", entry, r.Errors[entry])
+		}
+	}
+}
+
+// DefaultAuditRequest fills the age and breach list settings from the
+// configuration.
+func DefaultAuditRequest(passphrase string) AuditRequest {
+	req := AuditRequest{Passphrase: passphrase, MaxAge: config.PasswordMaxAge()}
+	if path := config.BreachFile(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			req.BreachFile = path
+		}
+	}
+	return req
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestPasswordStrength(t *testing.T) {
+	tests := []struct {
+		password string
+		weak     bool
+	}{
+		{"password", true},
+		{"qwertyuiop", true},
+		{"aaaaaaaaaaaa", true},
+		{"abcdef123456", true},
+		{"Tr0ub4dor&3", false},
+		{"correct horse battery staple", false},
+		{"x7#Kq!9vLm@2Wp", false},
+	}
+	for _, tt := range tests {
+		score, entropy := PasswordStrength(tt.password)
+		if weak := score <= StrengthWeak; weak != tt.weak {
+			t.Errorf("%q: score %d (%.0f bits), expected weak=%v", tt.password, score, entropy, tt.weak)
+		}
+	}
+}
+
+func TestPasswordOf(t *testing.T) {
+	if got := passwordOf("domain: a.com\nemail: me\npassword: hunter2\nextra: \n---\ntemplate: Email and password\n"); got != "hunter2" {
+		t.Errorf("unexpected template password %q", got)
+	}
+	if got := passwordOf("s3cret\nuser: me\n"); got != "s3cret" {
+		t.Errorf("unexpected free form password %q", got)
+	}
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestBreachListSortedFile(t *testing.T) {
+	var lines []string
+	for i, password := range []string{"password", "123456", "hunter2", "letmein", "qwerty", "dragon"} {
+		lines = append(lines, sha1Hex(password)+":"+strings.Repeat("1", i+1))
+	}
+	sort.Strings(lines)
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0600)
+
+	list, err := OpenBreachList(path)
+	if err != nil {
+		t.Fatalf("OpenBreachList failed: %v", err)
+	}
+	for password, want := range map[string]int{"password": 1, "dragon": 111111, "hunter2": 111, "not breached": 0} {
+		if got, err := list.Count(password); err != nil || got != want {
+			t.Errorf("%q: expected %d, got %d, %v", password, want, got, err)
+		}
+	}
+}
+
+func TestBreachListRangeDir(t *testing.T) {
+	dir := t.TempDir()
+	hash := sha1Hex("hunter2")
+	os.WriteFile(filepath.Join(dir, hash[:5]), []byte("0000000000000000000000000000000000A:2\n"+hash[5:]+":17\n"), 0600)
+
+	list, err := OpenBreachList(dir)
+	if err != nil {
+		t.Fatalf("OpenBreachList failed: %v", err)
+	}
+	if got, err := list.Count("hunter2"); err != nil || got != 17 {
+		t.Errorf("expected 17, got %d, %v", got, err)
+	}
+	if got, err := list.Count("not breached"); err != nil || got != 0 {
+		t.Errorf("expected 0, got %d, %v", got, err)
+	}
+}
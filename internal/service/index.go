@@ -0,0 +1,352 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+const (
+	indexDirName     = ".gopass"
+	indexFileName    = "index.gpg"
+	indexJournalName = "index.journal"
+)
+
+// ErrIndexUnavailable is returned when a query needs the index but it was
+// never built or cannot be decrypted without a passphrase.
+var ErrIndexUnavailable = errors.New("search index is not available, unlock the store or rebuild the index")
+
+// IndexEntry holds the non-sensitive metadata of an entry. Values of fields
+// other than the URL and username are never stored.
+type IndexEntry struct {
+	Template  string    `json:"template"`
+	Fields    []string  `json:"fields"`
+	URL       string    `json:"url,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Favorite  bool      `json:"favorite,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Index struct {
+	Entries map[string]IndexEntry `json:"entries"`
+}
+
+// indexChange is a journal record. Mutations append one encrypted record,
+// which only needs the public key, and the records are folded into the index
+// the next time it is decrypted.
+type indexChange struct {
+	Op       string      `json:"op"`
+	Entry    string      `json:"entry"`
+	OldEntry string      `json:"old_entry,omitempty"`
+	Data     *IndexEntry `json:"data,omitempty"`
+}
+
+var urlFields = []string{"url", "domain", "website"}
+var usernameFields = []string{"username", "email", "user", "login"}
+
+func indexPath(storeDir string) string {
+	return filepath.Join(storeDir, indexDirName, indexFileName)
+}
+
+func indexJournalDir(storeDir string) string {
+	return filepath.Join(storeDir, indexDirName, indexJournalName)
+}
+
+// IndexEnabled reports whether the index was built for the store.
+func IndexEnabled() bool {
+	_, err := os.Stat(indexPath(config.PasswordStoreDir()))
+	return err == nil
+}
+
+// NewIndexEntry extracts the metadata of decrypted content.
+func NewIndexEntry(content string) IndexEntry {
+	parsed := ParseEntry(content)
+	ie := IndexEntry{Template: parsed.Template, UpdatedAt: time.Now().UTC()}
+	for _, f := range parsed.Fields {
+		ie.Fields = append(ie.Fields, f.Name)
+	}
+	for _, name := range urlFields {
+		if v := parsed.Get(name); v != "" {
+			ie.URL = v
+			break
+		}
+	}
+	for _, name := range usernameFields {
+		if v := parsed.Get(name); v != "" {
+			ie.Username = v
+			break
+		}
+	}
+	meta := parsed.EntryMeta()
+	ie.Tags = meta.Tags
+	ie.Favorite = meta.Favorite
+	return ie
+}
+
+// Tags returns every tag used in the index, sorted.
+func (idx *Index) Tags() []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, ie := range idx.Entries {
+		for _, tag := range ie.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// ListTags returns the tags of the store from the index, see Search for how
+// the passphrase is used.
+func ListTags(passphrase string) ([]string, error) {
+	idx, err := cachedIndex(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Tags(), nil
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (idx *Index) apply(c indexChange) {
+	switch c.Op {
+	case "put":
+		if c.Data != nil {
+			idx.Entries[c.Entry] = *c.Data
+		}
+	case "delete":
+		delete(idx.Entries, c.Entry)
+	case "move":
+		if ie, ok := idx.Entries[c.OldEntry]; ok {
+			delete(idx.Entries, c.OldEntry)
+			idx.Entries[c.Entry] = ie
+		}
+	}
+}
+
+// journalIndexChange appends a change to the journal if the index is enabled.
+// Errors are only logged, the index can always be rebuilt.
+func journalIndexChange(c indexChange) {
+	storeDir := config.PasswordStoreDir()
+	if _, err := os.Stat(indexPath(storeDir)); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(c)
+	if err == nil {
+		var ciphertext []byte
+		ciphertext, err = gpg.Encrypt(data, rootRecipients())
+		if err == nil {
+			suffix := make([]byte, 4)
+			rand.Read(suffix)
+			name := fmt.Sprintf("%020d-%s.gpg", time.Now().UnixNano(), hex.EncodeToString(suffix))
+			dir := indexJournalDir(storeDir)
+			if err = os.MkdirAll(dir, 0700); err == nil {
+				err = os.WriteFile(filepath.Join(dir, name), ciphertext, 0600)
+			}
+		}
+	}
+	if err != nil {
+		slog.Error("Failed to update search index", slog.String("entry", c.Entry), slog.Any("error", err))
+	}
+	invalidateIndexCache()
+}
+
+func indexPut(entry, content string) {
+	ie := NewIndexEntry(content)
+	journalIndexChange(indexChange{Op: "put", Entry: filepath.ToSlash(entry), Data: &ie})
+}
+
+func indexDelete(entry string) {
+	journalIndexChange(indexChange{Op: "delete", Entry: filepath.ToSlash(entry)})
+}
+
+func indexMove(from, to string) {
+	journalIndexChange(indexChange{Op: "move", Entry: filepath.ToSlash(to), OldEntry: filepath.ToSlash(from)})
+}
+
+// LoadIndex decrypts the index and folds pending journal records into it.
+func LoadIndex(passphrase string) (*Index, error) {
+	storeDir := config.PasswordStoreDir()
+	if _, err := os.Stat(indexPath(storeDir)); err != nil {
+		return nil, ErrIndexUnavailable
+	}
+
+	plaintext, err := gpg.DecryptGPGFileWithKey(indexPath(storeDir), passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt search index: %w", err)
+	}
+	idx := &Index{}
+	if err := json.Unmarshal([]byte(plaintext), idx); err != nil {
+		return nil, fmt.Errorf("malformed search index: %w", err)
+	}
+	if idx.Entries == nil {
+		idx.Entries = map[string]IndexEntry{}
+	}
+
+	journal, err := filepath.Glob(filepath.Join(indexJournalDir(storeDir), "*.gpg"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(journal)
+	for _, path := range journal {
+		plaintext, err := gpg.DecryptGPGFileWithKey(path, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt index journal: %w", err)
+		}
+		var c indexChange
+		if err := json.Unmarshal([]byte(plaintext), &c); err != nil {
+			return nil, fmt.Errorf("malformed index journal: %w", err)
+		}
+		idx.apply(c)
+	}
+
+	if len(journal) > 0 {
+		if err := saveIndex(storeDir, idx); err != nil {
+			return nil, err
+		}
+		for _, path := range journal {
+			os.Remove(path)
+		}
+	}
+
+	return idx, nil
+}
+
+// RebuildIndex decrypts every entry and writes a fresh index, enabling the
+// index if it did not exist yet. The index is kept in the root store and
+// covers the mounted stores too.
+func RebuildIndex(passphrase string) (*Index, error) {
+	storeDir := config.PasswordStoreDir()
+	entries, err := ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{Entries: map[string]IndexEntry{}}
+	for _, entry := range entries {
+		plaintext, err := gpg.DecryptGPGFileWithKey(EntryPath(entry), passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", entry, err)
+		}
+		idx.Entries[filepath.ToSlash(entry)] = NewIndexEntry(plaintext)
+	}
+
+	if err := saveIndex(storeDir, idx); err != nil {
+		return nil, err
+	}
+	os.RemoveAll(indexJournalDir(storeDir))
+	invalidateIndexCache()
+
+	return idx, nil
+}
+
+func saveIndex(storeDir string, idx *Index) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := gpg.Encrypt(data, rootRecipients())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt search index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(storeDir, indexDirName), 0700); err != nil {
+		return err
+	}
+	if err := ensureIndexIgnored(storeDir); err != nil {
+		return err
+	}
+
+	tmp := indexPath(storeDir) + ".tmp"
+	if err := os.WriteFile(tmp, ciphertext, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, indexPath(storeDir))
+}
+
+// ensureIndexIgnored keeps the index out of git, every clone builds its own.
+func ensureIndexIgnored(storeDir string) error {
+	path := filepath.Join(storeDir, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == indexDirName+"/" {
+			return nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	return os.WriteFile(path, append(data, []byte(indexDirName+"/\n")...), 0600)
+}
+
+var indexCache struct {
+	mu  sync.Mutex
+	idx *Index
+	// passphrase decrypted idx, a query must bring the same one
+	passphrase string
+}
+
+func invalidateIndexCache() {
+	indexCache.mu.Lock()
+	indexCache.idx = nil
+	indexCache.passphrase = ""
+	indexCache.mu.Unlock()
+}
+
+// cachedIndex returns the decrypted index, keeping it in memory until the
+// next mutation or lock so queries typed in the UIs stay instant. The cached
+// index is only returned for the passphrase that decrypted it, an empty
+// passphrase falls back to the cached passphrase of the local UIs.
+func cachedIndex(passphrase string) (*Index, error) {
+	if passphrase == "" {
+		pass, valid := GetCachedPassphrase()
+		if !valid {
+			return nil, ErrIndexUnavailable
+		}
+		passphrase = pass
+	}
+
+	indexCache.mu.Lock()
+	defer indexCache.mu.Unlock()
+
+	if indexCache.idx != nil && subtle.ConstantTimeCompare([]byte(passphrase), []byte(indexCache.passphrase)) == 1 {
+		return indexCache.idx, nil
+	}
+
+	idx, err := LoadIndex(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	indexCache.idx = idx
+	indexCache.passphrase = passphrase
+
+	return idx, nil
+}
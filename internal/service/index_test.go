@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewIndexEntry(t *testing.T) {
+	content := "domain: github.com\nemail: me@example.com\npassword: hunter2\nextra: \n---\ntemplate: Email and password\ntags: work, dev\n"
+	ie := NewIndexEntry(content)
+
+	if ie.Template != TemplateEmailAndPassword || ie.URL != "github.com" || ie.Username != "me@example.com" {
+		t.Errorf("unexpected index entry %+v", ie)
+	}
+	if !reflect.DeepEqual(ie.Fields, []string{"domain", "email", "password", "extra"}) {
+		t.Errorf("unexpected fields %v", ie.Fields)
+	}
+	if !reflect.DeepEqual(ie.Tags, []string{"work", "dev"}) {
+		t.Errorf("unexpected tags %v", ie.Tags)
+	}
+}
+
+func TestIndexApply(t *testing.T) {
+	idx := &Index{Entries: map[string]IndexEntry{"a": {Template: TemplateFreeForm}}}
+
+	idx.apply(indexChange{Op: "put", Entry: "b", Data: &IndexEntry{URL: "example.com"}})
+	idx.apply(indexChange{Op: "move", Entry: "c", OldEntry: "a"})
+	idx.apply(indexChange{Op: "delete", Entry: "b"})
+
+	if len(idx.Entries) != 1 {
+		t.Fatalf("expected one entry, got %v", idx.Entries)
+	}
+	if _, ok := idx.Entries["c"]; !ok {
+		t.Errorf("expected a to be moved to c, got %v", idx.Entries)
+	}
+}
+
+func TestSearchWithIndexFilters(t *testing.T) {
+	indexCache.idx = &Index{Entries: map[string]IndexEntry{
+		"work/github":     {URL: "github.com", Tags: []string{"work"}},
+		"personal/github": {URL: "github.com"},
+		"work/aws":        {URL: "console.aws.amazon.com", Tags: []string{"work"}},
+	}}
+	indexCache.passphrase = "test"
+	defer invalidateIndexCache()
+
+	entries := []string{"work/github", "personal/github", "work/aws"}
+	results, err := Search(SearchRequest{Query: "url:GitHub tag:work", Entries: entries, Passphrase: "test"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Entry != "work/github" {
+		t.Errorf("unexpected results %v", results)
+	}
+
+	results, err = Search(SearchRequest{Query: "aws url:amazon", Entries: entries, Passphrase: "test"})
+	if err != nil || len(results) != 1 || results[0].Entry != "work/aws" {
+		t.Errorf("unexpected results %v, %v", results, err)
+	}
+}
+
+func TestCachedIndexNeedsItsPassphrase(t *testing.T) {
+	setupFolderStore(t, "work/github")
+	indexCache.idx = &Index{Entries: map[string]IndexEntry{"work/github": {URL: "github.com"}}}
+	indexCache.passphrase = "test"
+	defer invalidateIndexCache()
+
+	for _, passphrase := range []string{"wrong", ""} {
+		_, err := Search(SearchRequest{Query: "url:github", Entries: []string{"work/github"}, Passphrase: passphrase})
+		if !errors.Is(err, ErrIndexUnavailable) {
+			t.Errorf("expected the cached index to be refused for %q, got %v", passphrase, err)
+		}
+	}
+	if !QueryUsesIndex("aws url:amazon") || QueryUsesIndex("aws") {
+		t.Error("expected only key:value terms to use the index")
+	}
+}
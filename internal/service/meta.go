@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+const (
+	metaTemplate = "template"
+	metaTags     = "tags"
+	metaFavorite = "favorite"
+)
+
+// EntryMeta is the user editable metadata kept below the "---" separator.
+// It is stored as plain "key: value" lines so `pass show` displays it as is.
+type EntryMeta struct {
+	Tags     []string
+	Favorite bool
+}
+
+// EntryMeta returns the tags and favorite flag of the parsed entry.
+func (p ParsedEntry) EntryMeta() EntryMeta {
+	favorite, _ := strconv.ParseBool(p.Meta[metaFavorite])
+	return EntryMeta{Tags: splitTags(p.Meta[metaTags]), Favorite: favorite}
+}
+
+// formatMeta writes the metadata block, the template first, then tags and
+// favorite, then any other key found in extra, so unknown metadata written
+// by other tools survives an edit.
+func formatMeta(sb *strings.Builder, template string, meta EntryMeta, extra map[string]string) {
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("%s: %s\n", metaTemplate, template))
+	if len(meta.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", metaTags, strings.Join(meta.Tags, ", ")))
+	}
+	if meta.Favorite {
+		sb.WriteString(fmt.Sprintf("%s: true\n", metaFavorite))
+	}
+
+	var keys []string
+	for key := range extra {
+		if key != metaTemplate && key != metaTags && key != metaFavorite {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", key, extra[key]))
+	}
+}
+
+// UpdateEntryMeta decrypts the entry and rewrites its metadata, leaving the
+// fields untouched.
+func UpdateEntryMeta(entryName, passphrase string, meta EntryMeta) error {
+	plaintext, err := gpg.DecryptGPGFileWithKey(EntryPath(entryName), passphrase)
+	if err != nil {
+		return err
+	}
+	parsed := ParseEntry(plaintext)
+
+	var sb strings.Builder
+	sb.WriteString(entryBody(plaintext))
+	formatMeta(&sb, parsed.Template, meta, parsed.Meta)
+
+	return SaveEntry(entryName, []byte(sb.String()))
+}
+
+// entryBody returns the content above the metadata separator, ending with a
+// newline.
+func entryBody(content string) string {
+	body := content
+	if idx := strings.LastIndex(content, "\n---\n"); idx >= 0 {
+		body = content[:idx+1]
+	} else if strings.HasPrefix(content, "---\n") {
+		body = ""
+	}
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/git"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+// EntryPath returns the file of an entry, in the store mounted at its prefix.
+func EntryPath(entry string) string {
+	m, rel := config.MountFor(entry)
+	return filepath.Join(m.Path, filepath.FromSlash(rel)+".gpg")
+}
+
+// StoreOf returns the name of the store holding an entry.
+func StoreOf(entry string) string {
+	m, _ := config.MountFor(entry)
+	return m.Name
+}
+
+// entryGPGId returns the keys an entry is encrypted for, from the .gpg-id of
+// its folder or the nearest parent folder like pass, the store keys at last.
+func entryGPGId(entry string) string {
+	m, rel := config.MountFor(entry)
+	return folderGPGId(m, path.Dir(rel))
+}
+
+// folderGPGId returns the keys of a folder of a store, relative to its root.
+func folderGPGId(m config.Mount, folder string) string {
+	_, gpgId := gpgIdFolder(m, folder)
+	return gpgId
+}
+
+// gpgIdFolder returns the folder whose .gpg-id applies to folder, empty for
+// the root of the store, and its keys.
+func gpgIdFolder(m config.Mount, folder string) (string, string) {
+	for folder != "." && folder != "" && folder != "/" {
+		if data, err := os.ReadFile(filepath.Join(m.Path, filepath.FromSlash(folder), ".gpg-id")); err == nil {
+			if gpgId := strings.TrimSpace(string(data)); gpgId != "" {
+				return folder, gpgId
+			}
+		}
+		folder = path.Dir(folder)
+	}
+	return "", m.GPGId
+}
+
+// entryRecipients returns the recipients an entry is encrypted for, see
+// entryGPGId.
+func entryRecipients(entry string) gpg.Recipients {
+	m, rel := config.MountFor(entry)
+	return folderRecipientsOf(m, path.Dir(rel))
+}
+
+// rootRecipients returns the recipients of the root of the root store.
+func rootRecipients() gpg.Recipients {
+	m, _ := config.MountByName(config.RootStoreName)
+	return folderRecipientsOf(m, "")
+}
+
+// folderRecipientsOf returns the recipients of a folder of a store: its
+// .gpg-id, whose signature is checked before encrypting, or the key of the
+// configuration that overrides the .gpg-id of the root store.
+func folderRecipientsOf(m config.Mount, folder string) gpg.Recipients {
+	dir, gpgId := gpgIdFolder(m, folder)
+	if dir == "" && (gpgId == "" || (m.Name == config.RootStoreName && config.Current().Store.Key != "")) {
+		return gpg.Recipients{Keys: gpgId}
+	}
+	return gpg.Recipients{GPGId: filepath.Join(m.Path, filepath.FromSlash(dir), ".gpg-id")}
+}
+
+// nestedMounts returns the folders of the stores mounted inside m, they are
+// left out when walking m.
+func nestedMounts(m config.Mount) map[string]bool {
+	nested := map[string]bool{}
+	for _, other := range config.Mounts() {
+		if other.Path != m.Path {
+			nested[filepath.Clean(other.Path)] = true
+		}
+	}
+	return nested
+}
+
+// ListEntries returns the entries of every store, those of mounted stores
+// prefixed with their mount point. Entries of a store hidden by a store
+// mounted at a longer prefix are left out, and a store whose folder does not
+// exist is skipped.
+func ListEntries() ([]string, error) {
+	var all []string
+	for _, m := range config.Mounts() {
+		entries, err := ListPasswordEntries(m.Path)
+		if errors.Is(err, os.ErrNotExist) && m.Prefix != "" {
+			slog.Warn("Mounted store not found", slog.String("store", m.Name), slog.String("path", m.Path))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list store %s: %w", m.Name, err)
+		}
+		for _, rel := range entries {
+			entry := filepath.Join(m.Prefix, rel)
+			if owner, _ := config.MountFor(entry); owner.Name == m.Name {
+				all = append(all, entry)
+			}
+		}
+	}
+	sort.Strings(all)
+	return all, nil
+}
+
+// IsGitStore reports whether the store folder is a git repository.
+func IsGitStore(m config.Mount) bool {
+	_, err := os.Stat(filepath.Join(m.Path, ".git"))
+	return err == nil
+}
+
+// CommitStores commits the pending changes of every store that is a git
+// repository, for operations writing many entries at once.
+func CommitStores(message string) error {
+	var errs []error
+	for _, m := range config.Mounts() {
+		if !IsGitStore(m) {
+			continue
+		}
+		if err := git.CommitAll(m.Path, message); err != nil {
+			errs = append(errs, fmt.Errorf("store %s: %w", m.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// storeLabel prefixes a message with the store name, except for the root
+// store so single store setups read as before.
+func storeLabel(m config.Mount, msg string) string {
+	if m.Prefix == "" {
+		return msg
+	}
+	return m.Name + ": " + msg
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"strings"
+)
+
+// Field is a named value of an entry, in the order it appears in the file.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// ParsedEntry is the decrypted content of an entry split into the fields
+// above the "---" separator and the metadata below it.
+type ParsedEntry struct {
+	Template string
+	Fields   []Field
+	Meta     map[string]string
+}
+
+// Get returns the value of the named field.
+func (p ParsedEntry) Get(name string) string {
+	for _, f := range p.Fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Password returns the password field, or the first line of a Free Form
+// entry like pass does.
+func (p ParsedEntry) Password() string {
+	if password := p.Get("password"); password != "" {
+		return password
+	}
+	if p.Template == TemplateFreeForm {
+		first, _, _ := strings.Cut(p.Get("content"), "\n")
+		return first
+	}
+	return ""
+}
+
+// ParseEntry parses decrypted content written by AddOrEditEntry. Content
+// without metadata, e.g. written by `pass insert`, is a Free Form entry.
+func ParseEntry(content string) ParsedEntry {
+	parsed := ParsedEntry{Template: TemplateFreeForm, Meta: map[string]string{}}
+
+	body := entryBody(content)
+	if meta, ok := strings.CutPrefix(content, body+"---\n"); ok {
+		for _, line := range strings.Split(meta, "\n") {
+			if key, value, ok := splitField(line); ok {
+				parsed.Meta[key] = value
+			}
+		}
+	}
+	if tmpl, ok := parsed.Meta["template"]; ok && tmpl != "" {
+		parsed.Template = tmpl
+	}
+
+	tmpl := GetTemplateByName(parsed.Template)
+	if parsed.Template == TemplateFreeForm || tmpl == nil {
+		parsed.Fields = []Field{{Name: "content", Value: strings.TrimSuffix(body, "\n")}}
+		return parsed
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+		if key, value, ok := splitField(line); ok && isTemplateField(tmpl, key) {
+			parsed.Fields = append(parsed.Fields, Field{Name: key, Value: value})
+			continue
+		}
+		// Continuation of a multi-line value
+		if n := len(parsed.Fields); n > 0 {
+			parsed.Fields[n-1].Value += "\n" + line
+		} else if line != "" {
+			parsed.Fields = append(parsed.Fields, Field{Name: "content", Value: line})
+		}
+	}
+
+	return parsed
+}
+
+func isTemplateField(tmpl *Template, name string) bool {
+	for _, f := range tmpl.Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func splitField(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEntryTemplate(t *testing.T) {
+	content := "domain: github.com\nemail: me@example.com\npassword: p@ss: word\nextra: first\nsecond\n---\ntemplate: Email and password\n"
+	parsed := ParseEntry(content)
+
+	if parsed.Template != TemplateEmailAndPassword {
+		t.Errorf("unexpected template %q", parsed.Template)
+	}
+	if got := parsed.Get("password"); got != "p@ss: word" {
+		t.Errorf("unexpected password %q", got)
+	}
+	if got := parsed.Get("extra"); got != "first\nsecond" {
+		t.Errorf("unexpected extra %q", got)
+	}
+	if got := parsed.Password(); got != "p@ss: word" {
+		t.Errorf("unexpected Password() %q", got)
+	}
+}
+
+func TestParseEntryFreeForm(t *testing.T) {
+	parsed := ParseEntry("s3cret\nuser: me\n")
+
+	if parsed.Template != TemplateFreeForm {
+		t.Errorf("unexpected template %q", parsed.Template)
+	}
+	if got := parsed.Get("content"); got != "s3cret\nuser: me" {
+		t.Errorf("unexpected content %q", got)
+	}
+	if got := parsed.Password(); got != "s3cret" {
+		t.Errorf("unexpected Password() %q", got)
+	}
+}
+
+func TestEntryMetaRoundTrip(t *testing.T) {
+	content := "s3cret\n---\ntemplate: Free Form\ntags: work, aws\nowner: ops\n"
+	parsed := ParseEntry(content)
+
+	meta := parsed.EntryMeta()
+	if len(meta.Tags) != 2 || meta.Tags[0] != "work" || meta.Tags[1] != "aws" || meta.Favorite {
+		t.Fatalf("unexpected meta %+v", meta)
+	}
+
+	meta.Favorite = true
+	var sb strings.Builder
+	sb.WriteString(entryBody(content))
+	formatMeta(&sb, parsed.Template, meta, parsed.Meta)
+
+	want := "s3cret\n---\ntemplate: Free Form\ntags: work, aws\nfavorite: true\nowner: ops\n"
+	if sb.String() != want {
+		t.Errorf("unexpected content %q", sb.String())
+	}
+	if got := ParseEntry(sb.String()).Get("content"); got != "s3cret" {
+		t.Errorf("unexpected content field %q", got)
+	}
+}
@@ -2,13 +2,15 @@ package service
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
 // AddOrEditPassEntry creates or updates a password entry with the given template and values.
-// The entry is saved as a file under the password store directory, with template metadata appended.
+// The entry is encrypted for the store key and saved under the password store directory, with
+// template metadata appended. It used to write plaintext .txt files, Fsck finds and encrypts them.
+//
+// Deprecated: use AddOrEditEntry, which also updates the index and publishes events.
 func AddOrEditPassEntry(storeDir, entryName, templateName string, values map[string]string) error {
 	tmpl := GetTemplateByName(templateName)
 	if tmpl == nil {
@@ -20,6 +22,6 @@ func AddOrEditPassEntry(storeDir, entryName, templateName string, values map[str
 	}
 	sb.WriteString("---\n")
 	sb.WriteString(fmt.Sprintf("template: %s\n", templateName))
-	entryPath := filepath.Join(storeDir, entryName+".txt")
-	return os.WriteFile(entryPath, []byte(sb.String()), 0600)
+	entryPath := filepath.Join(storeDir, entryName+".gpg")
+	return writeEntry(entryPath, rootRecipients(), []byte(sb.String()))
 }
@@ -6,12 +6,17 @@ import (
 	"strings"
 )
 
+// ListPasswordEntries returns the entry names under dir. Hidden folders such
+// as .git and .gopass are skipped.
 func ListPasswordEntries(dir string) ([]string, error) {
 	var entries []string
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if info.IsDir() && path != dir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
 		if !info.IsDir() && strings.HasSuffix(info.Name(), ".gpg") {
 			rel, _ := filepath.Rel(dir, path)
 			entries = append(entries, strings.TrimSuffix(rel, ".gpg"))
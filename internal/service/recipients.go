@@ -0,0 +1,393 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/git"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+// publicKeysDir is the folder of a store keeping the public keys of its
+// recipients like gopass, teammates import them from there.
+const publicKeysDir = ".public-keys"
+
+// Recipient is a key of a .gpg-id.
+type Recipient struct {
+	// ID is the key as written in the .gpg-id
+	ID  string
+	Key gpg.KeyInfo
+	// Missing is set when neither the keyring nor the store has the key
+	Missing bool
+}
+
+// Warning tells why entries cannot be encrypted for the recipient, it is
+// empty when they can.
+func (r Recipient) Warning() string {
+	switch {
+	case r.Missing:
+		return fmt.Sprintf("no public key for %s, import it", r.ID)
+	case r.Key.Validity == "r":
+		return fmt.Sprintf("the key %s is revoked", r.ID)
+	case !r.Key.Usable():
+		return fmt.Sprintf("the key %s expired on %s", r.ID, r.Key.Expires.Format(time.DateOnly))
+	}
+	return ""
+}
+
+// FolderRecipients are the recipients of a store or of a folder with its own
+// .gpg-id.
+type FolderRecipients struct {
+	Store string
+	// Folder is the folder in the namespace of the entries, the mount point
+	// for the root of a store
+	Folder     string
+	Recipients []Recipient
+	// Signature is why the .gpg-id cannot be trusted when signing keys are
+	// configured, nil when it is signed by one of them
+	Signature error
+}
+
+// Warnings returns the signature problem and the warning of every recipient
+// that has one.
+func (f FolderRecipients) Warnings() []string {
+	var warnings []string
+	if f.Signature != nil {
+		warnings = append(warnings, f.Signature.Error())
+	}
+	for _, r := range f.Recipients {
+		if w := r.Warning(); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// ListRecipients returns the recipients of every store and of the folders
+// with their own .gpg-id, with the details of their keys.
+func ListRecipients() ([]FolderRecipients, error) {
+	var all []FolderRecipients
+	for _, m := range config.Mounts() {
+		if _, err := os.Stat(m.Path); err != nil {
+			continue
+		}
+		if m.GPGId != "" {
+			all = append(all, folderRecipients(m, "", m.GPGId))
+		}
+
+		nested := nestedMounts(m)
+		err := filepath.WalkDir(m.Path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != m.Path && (strings.HasPrefix(d.Name(), ".") || nested[filepath.Clean(p)]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			dir := filepath.Dir(p)
+			if d.Name() != ".gpg-id" || dir == filepath.Clean(m.Path) {
+				return nil
+			}
+			rel, _ := filepath.Rel(m.Path, dir)
+			rel = filepath.ToSlash(rel)
+			if gpgId := folderGPGId(m, rel); gpgId != "" {
+				all = append(all, folderRecipients(m, rel, gpgId))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read store %s: %w", m.Name, err)
+		}
+	}
+	return all, nil
+}
+
+func folderRecipients(m config.Mount, rel, gpgId string) FolderRecipients {
+	f := FolderRecipients{Store: m.Name, Folder: m.Prefix}
+	if rel != "" {
+		f.Folder = m.Entry(rel)
+	}
+	for _, id := range gpg.ParseRecipients(gpgId) {
+		f.Recipients = append(f.Recipients, lookupRecipient(m, id))
+	}
+	if r := folderRecipientsOf(m, rel); r.GPGId != "" {
+		f.Signature = gpg.VerifyGPGId(r.GPGId)
+	}
+	return f
+}
+
+// lookupRecipient returns the key of a recipient from the keyring, imported
+// from the public keys of the store when the keyring does not have it yet.
+func lookupRecipient(m config.Mount, id string) Recipient {
+	if key, ok := gpg.PublicKey(id); ok {
+		return Recipient{ID: id, Key: key}
+	}
+	data, err := os.ReadFile(filepath.Join(m.Path, publicKeysDir, id))
+	if err != nil {
+		return Recipient{ID: id, Missing: true}
+	}
+	key, _, err := gpg.ImportPublicKey(data)
+	if err != nil {
+		return Recipient{ID: id, Missing: true}
+	}
+	return Recipient{ID: id, Key: key}
+}
+
+// ImportPublicKey adds the public key of a teammate, armored or binary, to
+// the keyring and to the public keys of a store, the root store when store is
+// empty. The key can then be added as a recipient.
+func ImportPublicKey(store string, data []byte) (gpg.KeyInfo, error) {
+	if store == "" {
+		store = config.RootStoreName
+	}
+	m, ok := config.MountByName(store)
+	if !ok {
+		return gpg.KeyInfo{}, fmt.Errorf("unknown store: %s", store)
+	}
+
+	key, armored, err := gpg.ImportPublicKey(data)
+	if err == nil {
+		dir := filepath.Join(m.Path, publicKeysDir)
+		if err = os.MkdirAll(dir, 0700); err == nil {
+			err = os.WriteFile(filepath.Join(dir, key.Fingerprint), []byte(armored), 0600)
+		}
+	}
+	audit.Log(audit.OpRecipient, m.Entry(path.Join(publicKeysDir, key.Fingerprint)), err)
+	return key, err
+}
+
+// AddRecipient adds a key to the recipients of a folder, in the namespace of
+// the entries, and encrypts its entries again so the key can read them. A
+// folder without its own .gpg-id gets one with the keys it inherited. It
+// returns how many entries were re-encrypted.
+func AddRecipient(folder, id, passphrase string) (int, error) {
+	id = strings.TrimSpace(id)
+	return changeRecipients(folder, passphrase, "Add recipient "+id, func(m config.Mount, current []string, _ bool) ([]string, error) {
+		if id == "" {
+			return nil, errors.New("the recipient cannot be empty")
+		}
+		if slices.Contains(current, id) {
+			return nil, fmt.Errorf("%s is already a recipient", id)
+		}
+		r := lookupRecipient(m, id)
+		if r.Missing {
+			return nil, fmt.Errorf("no public key for %s, import it first", id)
+		}
+		if w := r.Warning(); w != "" {
+			return nil, errors.New(w)
+		}
+		return append(current, id), nil
+	})
+}
+
+// RemoveRecipient removes a key from the recipients of a folder and encrypts
+// its entries again without it. It returns how many entries were
+// re-encrypted; the removed key can still read the older versions in the git
+// history.
+func RemoveRecipient(folder, id, passphrase string) (int, error) {
+	return changeRecipients(folder, passphrase, "Remove recipient "+id, func(_ config.Mount, current []string, own bool) ([]string, error) {
+		if !slices.Contains(current, id) {
+			return nil, fmt.Errorf("%s is not a recipient", id)
+		}
+		if !own {
+			return nil, errors.New("the recipients are inherited from a parent folder, remove it there")
+		}
+		if len(current) == 1 {
+			return nil, errors.New("cannot remove the last recipient")
+		}
+		return slices.DeleteFunc(current, func(r string) bool { return r == id }), nil
+	})
+}
+
+// changeRecipients writes the .gpg-id of a folder with the recipients change
+// returns and re-encrypts the entries it applies to. own is set when the
+// folder has its own .gpg-id. Every entry is decrypted before the .gpg-id is
+// written, and a failed re-encryption puts back the previous .gpg-id and
+// entries, the folder is never left encrypted for two sets of keys.
+func changeRecipients(folder, passphrase, message string, change func(m config.Mount, current []string, own bool) ([]string, error)) (int, error) {
+	if passphrase == "" {
+		return 0, errors.New("the passphrase is needed to re-encrypt entries")
+	}
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
+	m, rel := config.MountFor(folder)
+	dir := filepath.Join(m.Path, filepath.FromSlash(rel))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return 0, fmt.Errorf("no folder %s", folder)
+	}
+
+	// The keys are only changed from keys that can be trusted
+	if r := folderRecipientsOf(m, rel); r.GPGId != "" {
+		if err := gpg.VerifyGPGId(r.GPGId); err != nil {
+			return 0, err
+		}
+	}
+	gpgIdDir, gpgId := gpgIdFolder(m, rel)
+	next, err := change(m, gpg.ParseRecipients(gpgId), gpgIdDir == rel)
+	// The passphrase is checked before anything changes, the folder may have
+	// no entry to decrypt
+	d := gpg.NewDecrypter(passphrase)
+	defer d.Close()
+	if err == nil {
+		err = d.Unlock(gpgId)
+	}
+	var entries []string
+	var plaintexts [][]byte
+	if err == nil {
+		entries, plaintexts, err = decryptRecipientEntries(m, rel, d)
+	}
+	file := filepath.Join(dir, ".gpg-id")
+	backup := backupFiles(file, file+gpg.SignatureSuffix)
+	if err == nil {
+		err = writeGPGId(file, strings.Join(next, "\n")+"\n", passphrase)
+	}
+	audit.Log(audit.OpRecipient, m.Entry(path.Join(rel, ".gpg-id")), err)
+	if err != nil {
+		return 0, err
+	}
+	if rel == "" {
+		// The keys of the root of a store are read with the configuration
+		reloadConfig()
+	}
+
+	for i, entry := range entries {
+		entryPath := EntryPath(entry)
+		backup.add(entryPath)
+		err := writeEntry(entryPath, entryRecipients(entry), plaintexts[i])
+		audit.Log(audit.OpReencrypt, entry, err)
+		if err != nil {
+			backup.restore()
+			if rel == "" {
+				reloadConfig()
+			}
+			audit.Log(audit.OpRecipient, m.Entry(path.Join(rel, ".gpg-id")), err)
+			return 0, fmt.Errorf("%s: %w, the previous recipients were put back", entry, err)
+		}
+	}
+	for _, entry := range entries {
+		Events.Publish(Event{Type: EventEntryUpdated, Message: entry, Data: EntryChanged{Entry: entry}})
+	}
+
+	if IsGitStore(m) {
+		if err := git.CommitAll(m.Path, message); err != nil {
+			return len(entries), err
+		}
+	}
+	return len(entries), nil
+}
+
+// decryptRecipientEntries decrypts the entries of a store the .gpg-id of
+// folder applies to once the folder has one, those without a .gpg-id closer
+// to them.
+func decryptRecipientEntries(m config.Mount, folder string, d *gpg.Decrypter) ([]string, [][]byte, error) {
+	all, err := ListEntries()
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries []string
+	var plaintexts [][]byte
+	for _, entry := range all {
+		owner, entryRel := config.MountFor(entry)
+		if owner.Name != m.Name || (folder != "" && !strings.HasPrefix(entryRel, folder+"/")) {
+			continue
+		}
+		entryDir, _ := gpgIdFolder(m, path.Dir(entryRel))
+		if entryDir != folder && (folder == "" || strings.HasPrefix(entryDir, folder+"/")) {
+			continue
+		}
+		plaintext, err := d.DecryptFile(EntryPath(entry))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", entry, err)
+		}
+		entries = append(entries, entry)
+		plaintexts = append(plaintexts, []byte(plaintext))
+	}
+	return entries, plaintexts, nil
+}
+
+// fileBackup keeps the content of files to put them back after a failed
+// change, a file that did not exist is removed.
+type fileBackup map[string][]byte
+
+func backupFiles(paths ...string) fileBackup {
+	b := fileBackup{}
+	for _, file := range paths {
+		b.add(file)
+	}
+	return b
+}
+
+func (b fileBackup) add(file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		data = nil
+	} else if data == nil {
+		data = []byte{}
+	}
+	b[file] = data
+}
+
+func (b fileBackup) restore() {
+	for file, data := range b {
+		if data == nil {
+			os.Remove(file)
+		} else if err := os.WriteFile(file, data, 0600); err != nil {
+			slog.Error("Failed to restore file", slog.String("path", file), slog.Any("error", err))
+		}
+	}
+}
+
+// writeGPGId writes a .gpg-id and signs it when signing keys are configured.
+// When it cannot be signed the previous keys are put back, nothing could be
+// encrypted for an unsigned .gpg-id.
+func writeGPGId(file, content, passphrase string) error {
+	previous, readErr := os.ReadFile(file)
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		return err
+	}
+	err := gpg.SignGPGId(file, passphrase)
+	if err != nil {
+		if readErr == nil {
+			os.WriteFile(file, previous, 0600)
+		} else {
+			os.Remove(file)
+		}
+	}
+	return err
+}
+
+// SignRecipients signs the .gpg-id of a folder, in the namespace of the
+// entries, with the signing keys. It is how the .gpg-id files of a store are
+// trusted once signing is turned on, after checking their keys.
+func SignRecipients(folder, passphrase string) error {
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
+	m, rel := config.MountFor(folder)
+	if len(config.SigningKeys()) == 0 {
+		return errors.New("no signing key is configured, set store.signing_keys or PASSWORD_STORE_SIGNING_KEY")
+	}
+	r := folderRecipientsOf(m, rel)
+	if gpgIdDir, _ := gpgIdFolder(m, rel); r.GPGId == "" || gpgIdDir != rel {
+		return fmt.Errorf("%s has no .gpg-id of its own", folder)
+	}
+
+	err := gpg.SignGPGId(r.GPGId, passphrase)
+	audit.Log(audit.OpRecipient, m.Entry(path.Join(rel, ".gpg-id")), err)
+	if err != nil {
+		return err
+	}
+	if IsGitStore(m) {
+		return git.CommitAll(m.Path, "Sign recipients of "+m.Entry(rel))
+	}
+	return nil
+}
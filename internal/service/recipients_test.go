@@ -0,0 +1,124 @@
+package service
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+func TestRecipients(t *testing.T) {
+	dir := setupFolderStore(t, "work/aws", "team/db")
+	t.Setenv("GNUPGHOME", t.TempDir())
+	if err := os.WriteFile(filepath.Join(dir, ".gpg-id"), []byte("AAAA1111\nBBBB2222\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "team", ".gpg-id"), []byte("CCCC3333\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	folders, err := ListRecipients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folders) != 2 || folders[0].Folder != "" || folders[1].Folder != "team" {
+		t.Fatalf("expected the root and team folders, got %+v", folders)
+	}
+	if len(folders[0].Recipients) != 2 || folders[0].Recipients[1].ID != "BBBB2222" {
+		t.Errorf("unexpected root recipients %+v", folders[0].Recipients)
+	}
+	if r := folders[1].Recipients; len(r) != 1 || !r[0].Missing || len(folders[1].Warnings()) != 1 {
+		t.Errorf("expected a missing key warning, got %+v", r)
+	}
+
+	if _, err := AddRecipient("team", "DDDD4444", ""); err == nil {
+		t.Error("expected an error without a passphrase")
+	}
+	if _, err := AddRecipient("team", "DDDD4444", "pass"); err == nil {
+		t.Error("expected an error for a key that is not imported")
+	}
+	if _, err := AddRecipient("team", "CCCC3333", "pass"); err == nil {
+		t.Error("expected an error for a key that already is a recipient")
+	}
+	if _, err := RemoveRecipient("work", "AAAA1111", "pass"); err == nil {
+		t.Error("expected an error removing an inherited recipient")
+	}
+	if _, err := RemoveRecipient("team", "CCCC3333", "pass"); err == nil {
+		t.Error("expected an error removing the last recipient")
+	}
+	if _, err := RemoveRecipient("", "EEEE5555", "pass"); err == nil {
+		t.Error("expected an error removing a key that is not a recipient")
+	}
+	if _, err := AddRecipient("missing", "DDDD4444", "pass"); err == nil {
+		t.Error("expected an error for a missing folder")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "work", ".gpg-id")); !os.IsNotExist(err) {
+		t.Error("expected no .gpg-id written when the change is refused")
+	}
+}
+
+func TestAddRecipientChecksThePassphrase(t *testing.T) {
+	if !gpg.CheckGPGAvailable() {
+		t.Skip("gpg is not installed")
+	}
+	dir := setupFolderStore(t)
+	t.Setenv("GNUPGHOME", t.TempDir())
+	own := importTestKey(t, "owner@example.com", "right")
+	teammate := importTestKey(t, "teammate@example.com", "")
+	gpgId := filepath.Join(dir, "team", ".gpg-id")
+	if err := os.MkdirAll(filepath.Dir(gpgId), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(gpgId, []byte(own+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The folder has no entry to decrypt, the passphrase is still checked
+	if _, err := AddRecipient("team", teammate, "wrong"); err == nil {
+		t.Error("expected an error for a wrong passphrase")
+	}
+	if data, _ := os.ReadFile(gpgId); string(data) != own+"\n" {
+		t.Errorf("expected the recipients unchanged, got %q", data)
+	}
+
+	if _, err := AddRecipient("team", teammate, "right"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(gpgId); string(data) != own+"\n"+teammate+"\n" {
+		t.Errorf("expected the teammate added, got %q", data)
+	}
+}
+
+// importTestKey generates a key protected by passphrase, none when empty,
+// imports it into the keyring and returns its fingerprint.
+func importTestKey(t *testing.T, email, passphrase string) string {
+	t.Helper()
+	key, err := crypto.GenerateKey(email, email, "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passphrase != "" {
+		if key, err = key.Lock([]byte(passphrase)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret, err := key.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("gpg", "--batch", "--import")
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gpg import failed: %v: %s", err, out)
+	}
+	return strings.ToUpper(key.GetFingerprint())
+}
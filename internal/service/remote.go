@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/git"
+)
+
+// gitMount returns the store named store, the root store when empty, which
+// has to be a git repository to have remotes.
+func gitMount(store string) (config.Mount, error) {
+	if store == "" {
+		store = config.RootStoreName
+	}
+	m, ok := config.MountByName(store)
+	if !ok {
+		return config.Mount{}, fmt.Errorf("unknown store: %s", store)
+	}
+	if !IsGitStore(m) {
+		return config.Mount{}, fmt.Errorf("store %s is not a git repository", m.Name)
+	}
+	return m, nil
+}
+
+// Remotes lists the git remotes of a store, the one synced with is marked. A
+// store without remotes is local only.
+func Remotes(store string) ([]git.Remote, error) {
+	m, err := gitMount(store)
+	if err != nil {
+		return nil, err
+	}
+	return git.ListRemotes(m.Path)
+}
+
+// AddRemote adds a git remote to a store. The first remote of a local store,
+// or one named origin, is the one synced with.
+func AddRemote(store, name, url string) error {
+	return updateRemote(store, name, func(m config.Mount) error {
+		return git.AddRemote(m.Path, name, url)
+	})
+}
+
+// SetRemoteURL changes the URL of a git remote of a store.
+func SetRemoteURL(store, name, url string) error {
+	return updateRemote(store, name, func(m config.Mount) error {
+		return git.SetRemoteURL(m.Path, name, url)
+	})
+}
+
+// RemoveRemote removes a git remote of a store.
+func RemoveRemote(store, name string) error {
+	return updateRemote(store, name, func(m config.Mount) error {
+		return git.RemoveRemote(m.Path, name)
+	})
+}
+
+// SetSyncRemote makes Sync pull from and push to the remote name of a store.
+func SetSyncRemote(store, name string) error {
+	return updateRemote(store, name, func(m config.Mount) error {
+		return git.SetSyncRemote(m.Path, name)
+	})
+}
+
+// PushToRemote publishes a store to its remote name, e.g. a local store to a
+// new empty repository.
+func PushToRemote(store, name string) error {
+	m, err := gitMount(store)
+	if err == nil {
+		err = git.PushToRemote(m.Path, name, "gopass push")
+	}
+	audit.Log(audit.OpSync, storeLabel(m, name), err)
+	return err
+}
+
+func updateRemote(store, name string, update func(m config.Mount) error) error {
+	m, err := gitMount(store)
+	if err == nil {
+		err = update(m)
+	}
+	audit.Log(audit.OpRemote, storeLabel(m, name), err)
+	return err
+}
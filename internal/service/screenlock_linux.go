@@ -0,0 +1,50 @@
+//go:build linux
+
+package service
+
+import (
+	"context"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// screenSaverInterfaces announce the screen lock with an ActiveChanged signal,
+// the freedesktop one is used by KDE and most desktops, GNOME has its own.
+var screenSaverInterfaces = []string{"org.freedesktop.ScreenSaver", "org.gnome.ScreenSaver"}
+
+// watchScreenLock calls onLock whenever the screen saver of the desktop
+// session activates, until ctx is done.
+func watchScreenLock(ctx context.Context, onLock func()) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	for _, iface := range screenSaverInterfaces {
+		if err := conn.AddMatchSignal(dbus.WithMatchInterface(iface), dbus.WithMatchMember("ActiveChanged")); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+	go func() {
+		defer conn.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-signals:
+				if !ok {
+					return
+				}
+				if len(sig.Body) > 0 {
+					if active, ok := sig.Body[0].(bool); ok && active {
+						onLock()
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// watchScreenLock is only available on Linux desktops for now.
+func watchScreenLock(ctx context.Context, onLock func()) error {
+	return errors.New("screen lock notifications are not supported on this platform")
+}
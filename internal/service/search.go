@@ -0,0 +1,244 @@
+package service
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchResult is an entry matching a fuzzy query. Positions holds the rune
+// indexes of the matched characters, for highlighting.
+type SearchResult struct {
+	Entry     string
+	Score     int
+	Positions []int
+}
+
+const (
+	scoreMatch          = 1
+	scoreConsecutive    = 4
+	scoreSegmentStart   = 8
+	scoreExactSegment   = 16
+	penaltyUnmatchedRun = 1
+)
+
+// FuzzySearch returns the entries containing every character of query in
+// order, best matches first. Matches at the start of a path segment
+// ("wap" for work/aws/prod), consecutive runs and whole segments rank higher.
+// An empty query returns every entry in its original order.
+func FuzzySearch(query string, entries []string) []SearchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := make([]SearchResult, 0, len(entries))
+
+	if query == "" {
+		for _, entry := range entries {
+			results = append(results, SearchResult{Entry: entry})
+		}
+		return results
+	}
+
+	for _, entry := range entries {
+		if score, positions, ok := fuzzyMatch(query, entry); ok {
+			results = append(results, SearchResult{Entry: entry, Score: score, Positions: positions})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if len(results[i].Entry) != len(results[j].Entry) {
+			return len(results[i].Entry) < len(results[j].Entry)
+		}
+		return results[i].Entry < results[j].Entry
+	})
+
+	return results
+}
+
+func isSegmentStart(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := runes[i-1]
+	return prev == '/' || prev == '\\' || prev == '-' || prev == '_' || prev == '.' || unicode.IsSpace(prev)
+}
+
+// fuzzyMatch greedily matches query against entry, preferring segment starts
+// so initials are picked up before letters in the middle of a word. When that
+// preference makes the match fail, the leftmost match is used instead.
+func fuzzyMatch(query, entry string) (int, []int, bool) {
+	q := []rune(query)
+	e := []rune(strings.ToLower(entry))
+
+	positions, ok := matchPositions(q, e, true)
+	if !ok {
+		positions, ok = matchPositions(q, e, false)
+	}
+	if !ok {
+		return 0, nil, false
+	}
+
+	score := 0
+	for i, idx := range positions {
+		score += scoreMatch
+		if isSegmentStart(e, idx) {
+			score += scoreSegmentStart
+		}
+		if i > 0 && idx == positions[i-1]+1 {
+			score += scoreConsecutive
+		} else if i > 0 {
+			score -= penaltyUnmatchedRun
+		}
+	}
+
+	for _, segment := range strings.Split(strings.ToLower(entry), "/") {
+		if segment == query {
+			score += scoreExactSegment
+		}
+	}
+
+	return score, positions, true
+}
+
+func matchPositions(q, e []rune, preferSegments bool) ([]int, bool) {
+	positions := make([]int, 0, len(q))
+	last := -1
+	for _, c := range q {
+		idx := -1
+		// A consecutive character beats jumping ahead to a segment start
+		if last >= 0 && last+1 < len(e) && e[last+1] == c {
+			idx = last + 1
+		}
+		if idx < 0 && preferSegments {
+			for i := last + 1; i < len(e); i++ {
+				if e[i] == c && isSegmentStart(e, i) {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx < 0 {
+			for i := last + 1; i < len(e); i++ {
+				if e[i] == c {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx < 0 {
+			return nil, false
+		}
+
+		positions = append(positions, idx)
+		last = idx
+	}
+
+	return positions, true
+}
+
+type SearchRequest struct {
+	Query   string
+	Entries []string
+	// Passphrase decrypts the index for key:value terms, the cached
+	// passphrase is used when empty
+	Passphrase string
+}
+
+// indexFilters are the key:value query terms answered from the index.
+var indexFilters = map[string]func(ie IndexEntry, value string) bool{
+	"url": func(ie IndexEntry, value string) bool {
+		return containsFold(ie.URL, value)
+	},
+	"user": func(ie IndexEntry, value string) bool {
+		return containsFold(ie.Username, value)
+	},
+	"template": func(ie IndexEntry, value string) bool {
+		return containsFold(ie.Template, value)
+	},
+	"field": func(ie IndexEntry, value string) bool {
+		return containsAnyFold(ie.Fields, value)
+	},
+	"tag": func(ie IndexEntry, value string) bool {
+		return containsAnyFold(ie.Tags, value)
+	},
+	"is": func(ie IndexEntry, value string) bool {
+		return strings.EqualFold(value, "favorite") && ie.Favorite
+	},
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func containsAnyFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryFilter is a key:value term of a search query.
+type queryFilter struct {
+	match func(IndexEntry, string) bool
+	value string
+}
+
+// parseQuery splits a search query into the terms matched against entry names
+// and the key:value terms answered from the index.
+func parseQuery(query string) (names []string, filters []queryFilter) {
+	for _, term := range strings.Fields(query) {
+		key, value, ok := strings.Cut(term, ":")
+		if match, known := indexFilters[strings.ToLower(key)]; ok && known && value != "" {
+			filters = append(filters, queryFilter{match, value})
+			continue
+		}
+		names = append(names, term)
+	}
+	return names, filters
+}
+
+// QueryUsesIndex reports whether the query has key:value terms, which need
+// the passphrase of the index.
+func QueryUsesIndex(query string) bool {
+	_, filters := parseQuery(query)
+	return len(filters) > 0
+}
+
+// Search returns the entries matching the query. Plain terms are fuzzy
+// matched against entry names, key:value terms (url:, user:, template:,
+// field:, tag:, is:favorite) are answered from the encrypted index, e.g.
+// "aws url:console.aws.amazon.com".
+func Search(req SearchRequest) ([]SearchResult, error) {
+	names, filters := parseQuery(req.Query)
+
+	entries := req.Entries
+	if len(filters) > 0 {
+		idx, err := cachedIndex(req.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		entries = nil
+		for _, entry := range req.Entries {
+			ie, ok := idx.Entries[filepath.ToSlash(entry)]
+			if !ok {
+				continue
+			}
+			matched := true
+			for _, f := range filters {
+				if !f.match(ie, f.value) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return FuzzySearch(strings.Join(names, ""), entries), nil
+}
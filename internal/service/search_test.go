@@ -0,0 +1,52 @@
+package service
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestFuzzySearchRanking(t *testing.T) {
+	entries := []string{"personal/wordpress", "work/aws/prod", "work/aws/staging", "github"}
+
+	results := FuzzySearch("wap", entries)
+	if len(results) == 0 || results[0].Entry != "work/aws/prod" {
+		t.Fatalf("expected work/aws/prod first for initials, got %v", results)
+	}
+	if !reflect.DeepEqual(results[0].Positions, []int{0, 5, 9}) {
+		t.Errorf("unexpected positions %v", results[0].Positions)
+	}
+
+	results = FuzzySearch("aws", entries)
+	if len(results) < 2 || results[0].Entry != "work/aws/prod" || results[1].Entry != "work/aws/staging" {
+		t.Errorf("expected both aws entries first, shortest first, got %v", results)
+	}
+
+	if results := FuzzySearch("xyz", entries); len(results) != 0 {
+		t.Errorf("expected no results, got %v", results)
+	}
+
+	if results := FuzzySearch("", entries); len(results) != len(entries) {
+		t.Errorf("empty query should return every entry, got %v", results)
+	}
+}
+
+func TestFuzzySearchFallsBackToLeftmostMatch(t *testing.T) {
+	results := FuzzySearch("ab", []string{"xaxb/a"})
+	if len(results) != 1 {
+		t.Fatalf("expected a match, got %v", results)
+	}
+}
+
+func TestGrepContent(t *testing.T) {
+	content := "domain: github.com\nemail: me@example.com\npassword: hunter2\nextra: line one\nline two\n---\ntemplate: Email and password\n"
+	matches := grepContent("github", content, regexp.MustCompile("(?i)LINE TWO|example"))
+
+	want := []GrepMatch{
+		{Entry: "github", Field: "email", Line: "me@example.com"},
+		{Entry: "github", Field: "extra", Line: "line two"},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("got %v, want %v", matches, want)
+	}
+}
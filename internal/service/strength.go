@@ -0,0 +1,108 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Strength scores follow zxcvbn: 0 is too guessable, 4 very unguessable.
+const (
+	StrengthVeryWeak = iota
+	StrengthWeak
+	StrengthFair
+	StrengthStrong
+	StrengthVeryStrong
+)
+
+// commonPasswords are rejected whatever their length, they are the first
+// guesses of any attacker.
+var commonPasswords = map[string]bool{
+	"123456": true, "123456789": true, "12345678": true, "password": true, "qwerty": true,
+	"qwerty123": true, "1q2w3e4r": true, "111111": true, "12345": true, "1234567890": true,
+	"1234567": true, "abc123": true, "password1": true, "iloveyou": true, "000000": true,
+	"123123": true, "admin": true, "letmein": true, "welcome": true, "monkey": true,
+	"dragon": true, "football": true, "baseball": true, "sunshine": true, "princess": true,
+	"master": true, "shadow": true, "superman": true, "trustno1": true, "passw0rd": true,
+	"p@ssw0rd": true, "p@ssword": true, "changeme": true, "secret": true, "login": true,
+	"starwars": true, "whatever": true, "hello123": true, "freedom": true, "qazwsx": true,
+}
+
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// PasswordStrength estimates the entropy of a password in bits and maps it
+// to a score. Like zxcvbn it discounts what attackers guess first: common
+// passwords, repeated characters, sequences such as "abc" or "321" and runs
+// of adjacent keyboard keys.
+func PasswordStrength(password string) (int, float64) {
+	if password == "" || commonPasswords[strings.ToLower(password)] {
+		return StrengthVeryWeak, 0
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if symbol {
+		pool += 33
+	}
+	bitsPerChar := math.Log2(float64(pool))
+
+	runes := []rune(strings.ToLower(password))
+	entropy := bitsPerChar
+	for i := 1; i < len(runes); i++ {
+		if isPredictable(runes[i-1], runes[i]) {
+			// A predictable character is roughly a choice of direction
+			entropy += 1
+			continue
+		}
+		entropy += bitsPerChar
+	}
+
+	switch {
+	case entropy < 28:
+		return StrengthVeryWeak, entropy
+	case entropy < 36:
+		return StrengthWeak, entropy
+	case entropy < 60:
+		return StrengthFair, entropy
+	case entropy < 80:
+		return StrengthStrong, entropy
+	}
+	return StrengthVeryStrong, entropy
+}
+
+// isPredictable reports whether next repeats prev, continues a sequence
+// from it, or is next to it on the keyboard.
+func isPredictable(prev, next rune) bool {
+	if prev == next || next == prev+1 || next == prev-1 {
+		return true
+	}
+	for _, row := range keyboardRows {
+		i := strings.IndexRune(row, prev)
+		j := strings.IndexRune(row, next)
+		if i >= 0 && j >= 0 && (j == i+1 || j == i-1) {
+			return true
+		}
+	}
+	return false
+}
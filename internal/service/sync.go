@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/git"
+	"github.com/duykhoa/gopass/internal/store"
+)
+
+// Sync pulls and pushes every store with its git remote. Mounted stores
+// that are not git repositories are skipped.
+func Sync() error {
+	var errs []error
+	for _, m := range config.Mounts() {
+		if m.Prefix != "" && !IsGitStore(m) {
+			continue
+		}
+		err := git.SyncWithRemote(m.Path, func(stage string) {
+			stage = storeLabel(m, stage)
+			Events.Publish(Event{Type: EventSyncProgress, Message: stage, Data: SyncProgress{Stage: stage}})
+		})
+		audit.Log(audit.OpSync, m.Prefix, err)
+		if err != nil && m.Prefix != "" {
+			err = fmt.Errorf("%s: %w", m.Name, err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		Events.Publish(Event{Type: EventError, Message: fmt.Sprintf("sync failed: %v", err), Data: Error{Err: err}})
+		return err
+	}
+	// Pulled entries are not in the index yet
+	if IndexEnabled() {
+		if pass, valid := GetCachedPassphrase(); valid {
+			Events.Publish(Event{Type: EventSyncProgress, Message: "indexing", Data: SyncProgress{Stage: "indexing"}})
+			if _, err := RebuildIndex(pass); err != nil {
+				slog.Error("Failed to rebuild search index after sync", slog.Any("error", err))
+			}
+		}
+	}
+	Events.Publish(Event{Type: EventSyncCompleted, Message: "Sync completed", Data: SyncProgress{Stage: "done", Done: true}})
+
+	return nil
+}
+
+// InitStore creates a new password store at baseDir, see store.InitPasswordStore.
+func InitStore(baseDir, keyID, remoteURL string) error {
+	err := store.InitPasswordStore(baseDir, keyID, remoteURL)
+	audit.Log(audit.OpReinit, baseDir, err)
+	if err == nil {
+		reloadConfig()
+	}
+
+	return err
+}
+
+// reloadConfig picks up the .gpg-id of a new store.
+func reloadConfig() {
+	if err := config.Reload(); err != nil {
+		slog.Error("Failed to reload configuration", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTPLine returns the TOTP secret or otpauth:// URI of a line, from an
+// "otp:" or "totp:" field as the importers write them, or an otpauth:// line
+// like pass-otp.
+func OTPLine(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "otpauth://") {
+		return line, true
+	}
+	key, value, ok := splitField(line)
+	if !ok || value == "" {
+		return "", false
+	}
+	switch strings.ToLower(key) {
+	case "otp", "totp":
+		return value, true
+	}
+	return "", false
+}
+
+// OTP returns the TOTP secret or otpauth:// URI of the entry, empty when it
+// has none.
+func (p ParsedEntry) OTP() string {
+	if otp := p.Get("otp"); otp != "" {
+		return otp
+	}
+	for _, f := range p.Fields {
+		for _, line := range strings.Split(f.Value, "\n") {
+			if otp, ok := OTPLine(line); ok {
+				return otp
+			}
+		}
+	}
+	return ""
+}
+
+// TOTP returns the RFC 6238 code of a base32 secret or an otpauth:// URI at
+// the given time, and how long the code stays valid.
+func TOTP(otp string, now time.Time) (string, time.Duration, error) {
+	secret, digits, period, algorithm := otp, 6, 30, "SHA1"
+	if strings.HasPrefix(otp, "otpauth://") {
+		u, err := url.Parse(otp)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid otpauth URI: %w", err)
+		}
+		if u.Host != "totp" {
+			return "", 0, fmt.Errorf("unsupported OTP type %q, only totp is", u.Host)
+		}
+		q := u.Query()
+		secret = q.Get("secret")
+		if v := q.Get("digits"); v != "" {
+			if digits, err = strconv.Atoi(v); err != nil || digits < 6 || digits > 10 {
+				return "", 0, fmt.Errorf("invalid digits %q", v)
+			}
+		}
+		if v := q.Get("period"); v != "" {
+			if period, err = strconv.Atoi(v); err != nil || period <= 0 {
+				return "", 0, fmt.Errorf("invalid period %q", v)
+			}
+		}
+		if v := q.Get("algorithm"); v != "" {
+			algorithm = strings.ToUpper(v)
+		}
+	}
+
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "SHA1":
+		newHash = sha1.New
+	case "SHA256":
+		newHash = sha256.New
+	case "SHA512":
+		newHash = sha512.New
+	default:
+		return "", 0, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	secret = strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return "", 0, fmt.Errorf("invalid TOTP secret")
+	}
+
+	counter := now.Unix() / int64(period)
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(newHash, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint64(1)
+	for range digits {
+		mod *= 10
+	}
+	code := fmt.Sprintf("%0*d", digits, uint64(value)%mod)
+	remaining := time.Duration((counter+1)*int64(period)-now.Unix()) * time.Second
+	return code, remaining, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 test vectors
+	secret := func(key string) string {
+		return base32.StdEncoding.EncodeToString([]byte(key))
+	}
+	sha1Key := secret("12345678901234567890")
+	sha256Key := secret("12345678901234567890123456789012")
+	sha512Key := secret("1234567890123456789012345678901234567890123456789012345678901234")
+	tests := []struct {
+		otp  string
+		at   int64
+		want string
+	}{
+		{"otpauth://totp/test?digits=8&secret=" + sha1Key, 59, "94287082"},
+		{"otpauth://totp/test?digits=8&secret=" + sha1Key, 1111111109, "07081804"},
+		{"otpauth://totp/test?digits=8&algorithm=SHA256&secret=" + sha256Key, 59, "46119246"},
+		{"otpauth://totp/test?digits=8&algorithm=SHA512&secret=" + sha512Key, 20000000000, "47863826"},
+		{sha1Key, 59, "287082"},
+	}
+	for _, tt := range tests {
+		code, remaining, err := TOTP(tt.otp, time.Unix(tt.at, 0))
+		if err != nil {
+			t.Fatalf("TOTP(%s) failed: %v", tt.otp, err)
+		}
+		if code != tt.want {
+			t.Errorf("TOTP(%s) at %d = %s, expected %s", tt.otp, tt.at, code, tt.want)
+		}
+		if remaining <= 0 || remaining > 30*time.Second {
+			t.Errorf("unexpected remaining time %v", remaining)
+		}
+	}
+
+	if _, _, err := TOTP("not base32!", time.Now()); err == nil {
+		t.Error("expected an invalid secret to fail")
+	}
+	if _, _, err := TOTP("otpauth://hotp/test?secret="+sha1Key, time.Now()); err == nil {
+		t.Error("expected HOTP to be rejected")
+	}
+}
+
+func TestParsedEntryOTP(t *testing.T) {
+	parsed := ParseEntry("s3cret\nuser: me\notpauth://totp/me?secret=JBSWY3DPEHPK3PXP\n")
+	if got := parsed.OTP(); got != "otpauth://totp/me?secret=JBSWY3DPEHPK3PXP" {
+		t.Errorf("unexpected OTP %q", got)
+	}
+
+	parsed = ParseEntry("domain: example.com\npassword: pw\nextra: notes\notp: JBSWY3DPEHPK3PXP\n---\ntemplate: Email and password\n")
+	if got := parsed.OTP(); got != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("unexpected OTP from extra %q", got)
+	}
+
+	if got := ParseEntry("s3cret\n").OTP(); got != "" {
+		t.Errorf("expected no OTP, got %q", got)
+	}
+}
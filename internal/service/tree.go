@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/duykhoa/gopass/internal/audit"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
+)
+
+// TreeNode is a folder or an entry of the entry tree. The root is a folder
+// with an empty Path.
+type TreeNode struct {
+	// Name is the last element of Path
+	Name string
+	// Path is the entry name, or the folder name without a trailing slash
+	Path  string
+	Entry bool
+	// Count is the number of entries in a folder and its subfolders
+	Count int
+	// Children are the subfolders then the entries, both sorted by name
+	Children []*TreeNode
+}
+
+// BuildTree arranges entry names in folders. An entry may have the name of a
+// folder, "work" and "work/aws" are both shown.
+func BuildTree(entries []string) *TreeNode {
+	root := &TreeNode{}
+	for _, entry := range entries {
+		entry = filepath.ToSlash(entry)
+		parts := strings.Split(entry, "/")
+		node := root
+		for i, part := range parts[:len(parts)-1] {
+			node.Count++
+			node = node.folder(part, strings.Join(parts[:i+1], "/"))
+		}
+		node.Count++
+		node.Children = append(node.Children, &TreeNode{Name: parts[len(parts)-1], Path: entry, Entry: true, Count: 1})
+	}
+	root.sort()
+	return root
+}
+
+func (n *TreeNode) folder(name, path string) *TreeNode {
+	for _, child := range n.Children {
+		if !child.Entry && child.Name == name {
+			return child
+		}
+	}
+	child := &TreeNode{Name: name, Path: path}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+func (n *TreeNode) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Entry != b.Entry {
+			return !a.Entry
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
+
+// Folder returns the folder at path, the root for an empty path.
+func (n *TreeNode) Folder(path string) *TreeNode {
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	if path == "" {
+		return n
+	}
+	node := n
+	for _, part := range strings.Split(path, "/") {
+		var next *TreeNode
+		for _, child := range node.Children {
+			if !child.Entry && child.Name == part {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// Entries returns the entries of a folder and its subfolders.
+func (n *TreeNode) Entries() []string {
+	if n.Entry {
+		return []string{n.Path}
+	}
+	var entries []string
+	for _, child := range n.Children {
+		entries = append(entries, child.Entries()...)
+	}
+	return entries
+}
+
+// folderEntries returns the entries under a folder of every store.
+func folderEntries(folder string) ([]string, error) {
+	all, err := ListEntries()
+	if err != nil {
+		return nil, err
+	}
+	node := BuildTree(all).Folder(folder)
+	if node == nil || node.Count == 0 {
+		return nil, fmt.Errorf("no entries in folder %s", folder)
+	}
+	return node.Entries(), nil
+}
+
+// MoveFolder moves every entry of a folder under a new folder name. Like
+// MoveEntry it refuses to move entries to another store, nothing is moved
+// then.
+func MoveFolder(from, to string) error {
+	from, to = strings.Trim(filepath.ToSlash(from), "/"), strings.Trim(filepath.ToSlash(to), "/")
+	if from == "" || to == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+	if to == from || strings.HasPrefix(to, from+"/") {
+		return fmt.Errorf("cannot move %s into itself", from)
+	}
+	entries, err := folderEntries(from)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]string, len(entries))
+	for i, entry := range entries {
+		targets[i] = to + strings.TrimPrefix(entry, from)
+		if StoreOf(entry) != StoreOf(targets[i]) {
+			return fmt.Errorf("cannot move %s to another store (%s)", entry, StoreOf(targets[i]))
+		}
+		if _, err := os.Stat(EntryPath(targets[i])); err == nil {
+			return fmt.Errorf("entry already exists: %s", targets[i])
+		}
+	}
+	for i, entry := range entries {
+		if err := MoveEntry(entry, targets[i]); err != nil {
+			return err
+		}
+		pruneEmptyDirs(entry)
+	}
+
+	return nil
+}
+
+// DeleteFolder deletes a folder with all its entries and subfolders, and
+// returns how many entries were deleted.
+func DeleteFolder(folder string) (int, error) {
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
+	if folder == "" {
+		return 0, fmt.Errorf("folder name cannot be empty")
+	}
+	entries, err := folderEntries(folder)
+	if err != nil {
+		return 0, err
+	}
+	for i, entry := range entries {
+		if err := DeleteEntry(entry); err != nil {
+			return i, err
+		}
+		pruneEmptyDirs(entry)
+	}
+
+	return len(entries), nil
+}
+
+// ReencryptFolder encrypts every entry of a folder again for the current key
+// of its store, e.g. after the .gpg-id changed. An entry can be given
+// instead of a folder. It returns how many entries were re-encrypted.
+func ReencryptFolder(folder, passphrase string) (int, error) {
+	folder = strings.Trim(filepath.ToSlash(folder), "/")
+	if folder == "" {
+		return 0, fmt.Errorf("folder name cannot be empty")
+	}
+	if passphrase == "" {
+		return 0, errors.New("the passphrase is needed to re-encrypt entries")
+	}
+	entries := []string{folder}
+	if _, err := os.Stat(EntryPath(folder)); err != nil {
+		if entries, err = folderEntries(folder); err != nil {
+			return 0, err
+		}
+	}
+
+	return reencryptEntries(entries, passphrase)
+}
+
+// reencryptEntries encrypts entries again for the keys of their folder.
+func reencryptEntries(entries []string, passphrase string) (int, error) {
+	for i, entry := range entries {
+		path := EntryPath(entry)
+		plaintext, err := gpg.DecryptGPGFileWithKey(path, passphrase)
+		if err == nil {
+			err = writeEntry(path, entryRecipients(entry), []byte(plaintext))
+		}
+		audit.Log(audit.OpReencrypt, entry, err)
+		if err != nil {
+			return i, fmt.Errorf("%s: %w", entry, err)
+		}
+		Events.Publish(Event{Type: EventEntryUpdated, Message: entry, Data: EntryChanged{Entry: entry}})
+	}
+
+	return len(entries), nil
+}
+
+// pruneEmptyDirs removes the folders of an entry left empty once it is gone,
+// up to the root of its store.
+func pruneEmptyDirs(entry string) {
+	m, _ := config.MountFor(entry)
+	root := filepath.Clean(m.Path)
+	for dir := filepath.Dir(EntryPath(entry)); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		// Remove fails on a folder that is not empty
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/duykhoa/gopass/internal/config"
+)
+
+func TestBuildTree(t *testing.T) {
+	root := BuildTree([]string{"work/aws/prod", "github", "work/aws/dev", "work", "mail"})
+
+	if root.Count != 5 {
+		t.Errorf("expected 5 entries in the root, got %d", root.Count)
+	}
+	var names []string
+	for _, child := range root.Children {
+		names = append(names, child.Name)
+	}
+	// Folders first
+	if want := []string{"work", "github", "mail", "work"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected children %v, got %v", want, names)
+	}
+	if root.Children[0].Entry || !root.Children[3].Entry {
+		t.Error("expected the folder work before the entry work")
+	}
+
+	aws := root.Folder("work/aws")
+	if aws == nil || aws.Count != 2 {
+		t.Fatalf("expected folder work/aws with 2 entries, got %+v", aws)
+	}
+	if want := []string{"work/aws/dev", "work/aws/prod"}; !reflect.DeepEqual(aws.Entries(), want) {
+		t.Errorf("expected entries %v, got %v", want, aws.Entries())
+	}
+	if root.Folder("github") != nil {
+		t.Error("an entry is not a folder")
+	}
+}
+
+// setupFolderStore creates a store of fake entries, folder operations that
+// do not decrypt never read them.
+func setupFolderStore(t *testing.T, entries ...string) string {
+	t.Helper()
+	t.Cleanup(func() { config.Reload() })
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GOPASS_CONFIG", filepath.Join(home, "config.toml"))
+	dir := filepath.Join(home, ".password-store")
+	t.Setenv("PASSWORD_STORE_DIR", dir)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry+".gpg")
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("ciphertext"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestMoveAndDeleteFolder(t *testing.T) {
+	dir := setupFolderStore(t, "work/aws/prod", "work/aws/dev", "work/mail", "home/mail")
+
+	if err := MoveFolder("", "archive"); err == nil {
+		t.Error("expected an error moving the whole store")
+	}
+	if err := MoveFolder("work", "work/old"); err == nil {
+		t.Error("expected an error moving a folder into itself")
+	}
+	if err := MoveFolder("work", "home"); err == nil {
+		t.Error("expected an error when an entry exists at the destination")
+	}
+	if err := MoveFolder("work/aws", "cloud"); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := ListEntries()
+	if want := []string{"cloud/dev", "cloud/prod", "home/mail", "work/mail"}; !reflect.DeepEqual(entries, want) {
+		t.Errorf("expected entries %v, got %v", want, entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "work", "aws")); !os.IsNotExist(err) {
+		t.Error("expected the empty folder to be removed")
+	}
+
+	for _, folder := range []string{"", "/"} {
+		if _, err := DeleteFolder(folder); err == nil {
+			t.Errorf("expected an error deleting the whole store as %q", folder)
+		}
+		if _, err := ReencryptFolder(folder, "pass"); err == nil || !strings.Contains(err.Error(), "empty") {
+			t.Errorf("expected an error re-encrypting the whole store as %q, got %v", folder, err)
+		}
+	}
+	if entries, _ := ListEntries(); len(entries) != 4 {
+		t.Errorf("expected no entry deleted, got %v", entries)
+	}
+
+	n, err := DeleteFolder("/cloud/")
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 entries deleted, got %d, %v", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cloud")); !os.IsNotExist(err) {
+		t.Error("expected the deleted folder to be removed")
+	}
+	if _, err := DeleteFolder("missing"); err == nil {
+		t.Error("expected an error for a folder without entries")
+	}
+}
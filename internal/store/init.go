@@ -5,11 +5,13 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/duykhoa/gopass/internal/gpg"
 	"github.com/go-git/go-git/v5"
 	gitcfg "github.com/go-git/go-git/v5/config"
 )
 
-// InitPasswordStore creates a new password store compatible with pass
+// InitPasswordStore creates a new password store compatible with pass. The
+// remote is optional, a store without one stays local.
 func InitPasswordStore(baseDir, keyID, remoteURL string) error {
 	if baseDir == "" {
 		return fmt.Errorf("baseDir cannot be empty")
@@ -19,11 +21,6 @@ func InitPasswordStore(baseDir, keyID, remoteURL string) error {
 		return fmt.Errorf("keyID cannot be empty")
 	}
 
-	if remoteURL == "" {
-		// Right now we don't allow empty remoteURL, but we might in the future
-		return fmt.Errorf("remoteURL cannot be empty")
-	}
-
 	if _, err := os.Stat(baseDir); err == nil {
 		return fmt.Errorf("password store already exists at %s", baseDir)
 	}
@@ -34,6 +31,12 @@ func InitPasswordStore(baseDir, keyID, remoteURL string) error {
 	if err := os.WriteFile(gpgIdPath, []byte(keyID+"\n"), 0600); err != nil {
 		return fmt.Errorf("failed to write .gpg-id: %w", err)
 	}
+	// With signing keys configured nothing is encrypted for an unsigned
+	// .gpg-id
+	if err := gpg.SignGPGId(gpgIdPath, ""); err != nil {
+		os.RemoveAll(baseDir)
+		return err
+	}
 	gitRepo, err := git.PlainInit(baseDir, false)
 	if err != nil {
 		return fmt.Errorf("failed to init git repo: %w", err)
@@ -53,3 +56,42 @@ func InitPasswordStore(baseDir, keyID, remoteURL string) error {
 	}
 	return nil
 }
+
+// Clone clones an existing password store from its remote into baseDir. The
+// clone is removed again when it is not a password store.
+func Clone(baseDir, remoteURL string) error {
+	if baseDir == "" {
+		return fmt.Errorf("baseDir cannot be empty")
+	}
+	if remoteURL == "" {
+		return fmt.Errorf("remoteURL cannot be empty")
+	}
+	if _, err := os.Stat(baseDir); err == nil {
+		return fmt.Errorf("password store already exists at %s", baseDir)
+	}
+
+	if _, err := git.PlainClone(baseDir, false, &git.CloneOptions{URL: remoteURL}); err != nil {
+		os.RemoveAll(baseDir)
+		return fmt.Errorf("failed to clone %s: %w", remoteURL, err)
+	}
+	recipients, err := Recipients(baseDir)
+	if err != nil {
+		os.RemoveAll(baseDir)
+		return fmt.Errorf("%s is not a password store: %w", remoteURL, err)
+	}
+	if len(recipients) == 0 {
+		os.RemoveAll(baseDir)
+		return fmt.Errorf("%s is not a password store, its .gpg-id lists no key", remoteURL)
+	}
+	return nil
+}
+
+// Recipients returns the keys of the .gpg-id of a store directory, one per
+// line like pass, without blank lines and comments.
+func Recipients(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ".gpg-id"))
+	if err != nil {
+		return nil, err
+	}
+	return gpg.ParseRecipients(string(data)), nil
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/gpg"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// newRemote returns a repository with the given files committed.
+func newRemote(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	if _, err := w.Commit("init", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestClone(t *testing.T) {
+	remote := newRemote(t, map[string]string{".gpg-id": "# team\nalice@example.com\n\nBOB1234\n", "site.gpg": "x"})
+	dir := filepath.Join(t.TempDir(), "store")
+	if err := Clone(dir, remote); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	recipients, err := Recipients(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipients) != 2 || recipients[0] != "alice@example.com" || recipients[1] != "BOB1234" {
+		t.Errorf("unexpected recipients %q", recipients)
+	}
+	if err := Clone(dir, remote); err == nil {
+		t.Error("expected an existing store not to be cloned over")
+	}
+}
+
+func TestCloneRejectsNonStore(t *testing.T) {
+	remote := newRemote(t, map[string]string{"README": "not a store"})
+	dir := filepath.Join(t.TempDir(), "store")
+	if err := Clone(dir, remote); err == nil {
+		t.Fatal("expected a repository without .gpg-id to be rejected")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected the clone to be removed, got %v", err)
+	}
+}
+
+func TestInitPasswordStoreSignsGPGId(t *testing.T) {
+	if !gpg.CheckGPGAvailable() {
+		t.Skip("gpg is not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("GNUPGHOME", home)
+	t.Setenv("GOPASS_CONFIG", filepath.Join(home, "config.toml"))
+	t.Cleanup(func() { config.Reload() })
+
+	key, err := crypto.GenerateKey("Admin", "admin@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := key.Armor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("gpg", "--batch", "--import")
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gpg import failed: %v: %s", err, out)
+	}
+	fingerprint := strings.ToUpper(key.GetFingerprint())
+	t.Setenv("PASSWORD_STORE_SIGNING_KEY", fingerprint)
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "store")
+	if err := InitPasswordStore(dir, fingerprint, ""); err != nil {
+		t.Fatalf("InitPasswordStore failed: %v", err)
+	}
+	if err := gpg.VerifyGPGId(filepath.Join(dir, ".gpg-id")); err != nil {
+		t.Errorf("expected a signed .gpg-id, got %v", err)
+	}
+
+	// A store that cannot be signed is not left behind
+	t.Setenv("PASSWORD_STORE_SIGNING_KEY", "0123456789ABCDEF0123456789ABCDEF01234567")
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(t.TempDir(), "store")
+	if err := InitPasswordStore(other, fingerprint, ""); err == nil {
+		t.Error("expected an error without the secret key of the signing keys")
+	}
+	if _, err := os.Stat(other); !os.IsNotExist(err) {
+		t.Errorf("expected the store to be removed, got %v", err)
+	}
+}
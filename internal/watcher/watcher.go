@@ -0,0 +1,247 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/service"
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounceDelay is how long the store must be quiet before pending changes
+// are published, so a `git pull` touching many files results in one burst.
+const debounceDelay = 250 * time.Millisecond
+
+// Watcher publishes changes made to the store outside of this process, e.g.
+// by `pass insert` or `git pull`, to a PubSub.
+type Watcher struct {
+	mounts []config.Mount
+	pubsub *service.PubSub
+	fs     *fsnotify.Watcher
+	delay  time.Duration
+
+	// pending maps entry names to whether the first change seen was a create
+	pending map[string]bool
+}
+
+func New(storeDir string, pubsub *service.PubSub) (*Watcher, error) {
+	return NewMounts([]config.Mount{{Name: config.RootStoreName, Path: storeDir}}, pubsub)
+}
+
+// NewMounts watches several stores, publishing the entries of each under its
+// mount prefix. Mounted stores whose folder does not exist are not watched.
+func NewMounts(mounts []config.Mount, pubsub *service.PubSub) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fs watcher: %w", err)
+	}
+
+	w := &Watcher{
+		pubsub:  pubsub,
+		fs:      fs,
+		delay:   debounceDelay,
+		pending: map[string]bool{},
+	}
+	for _, m := range mounts {
+		if _, err := os.Stat(m.Path); m.Prefix != "" && err != nil {
+			continue
+		}
+		if err := w.addRecursive(m.Path); err != nil {
+			fs.Close()
+			return nil, err
+		}
+		w.mounts = append(w.mounts, m)
+	}
+
+	return w, nil
+}
+
+// mountOf returns the watched store holding path, the one with the deepest
+// folder when stores are nested.
+func (w *Watcher) mountOf(path string) (config.Mount, bool) {
+	var best config.Mount
+	found := false
+	for _, m := range w.mounts {
+		rel, err := filepath.Rel(m.Path, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if !found || len(m.Path) > len(best.Path) {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
+
+// entryMount returns the watched store holding an entry, the one mounted at
+// the longest prefix, and the entry name inside it.
+func (w *Watcher) entryMount(entry string) (config.Mount, string) {
+	var best config.Mount
+	found := false
+	for _, m := range w.mounts {
+		matches := m.Prefix == "" || entry == m.Prefix || strings.HasPrefix(entry, m.Prefix+"/")
+		if matches && (!found || len(m.Prefix) > len(best.Prefix)) {
+			best, found = m, true
+		}
+	}
+	return best, strings.TrimPrefix(strings.TrimPrefix(entry, best.Prefix), "/")
+}
+
+// Run delivers events until the context is cancelled or the watcher is closed.
+func (w *Watcher) Run(ctx context.Context) {
+	timer := time.NewTimer(w.delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			slog.Error("Store watcher error", slog.Any("error", err))
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if w.handle(ev) {
+				timer.Reset(w.delay)
+			}
+		case <-timer.C:
+			w.flush()
+		}
+	}
+}
+
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// handle records the change and reports whether an entry was affected.
+func (w *Watcher) handle(ev fsnotify.Event) bool {
+	m, ok := w.mountOf(ev.Name)
+	if !ok || isInternalPath(m.Path, ev.Name) {
+		return false
+	}
+
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			if err := w.addRecursive(ev.Name); err != nil {
+				slog.Error("Failed to watch new folder", slog.String("path", ev.Name), slog.Any("error", err))
+			}
+			// Files may land in the folder before it is watched
+			w.addExisting(ev.Name)
+			return true
+		}
+	}
+
+	if !strings.HasSuffix(ev.Name, ".gpg") {
+		return false
+	}
+	entry, ok := w.entryName(ev.Name)
+	if !ok {
+		return false
+	}
+	if _, seen := w.pending[entry]; !seen {
+		w.pending[entry] = ev.Has(fsnotify.Create)
+	}
+
+	return true
+}
+
+// flush publishes one event per changed entry, based on its state on disk now.
+func (w *Watcher) flush() {
+	entries := make([]string, 0, len(w.pending))
+	for entry := range w.pending {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+
+	for _, entry := range entries {
+		created := w.pending[entry]
+		m, rel := w.entryMount(entry)
+		_, err := os.Stat(filepath.Join(m.Path, filepath.FromSlash(rel)+".gpg"))
+		exists := err == nil
+
+		var eventType service.EventType
+		switch {
+		case exists && created:
+			eventType = service.EventEntryCreated
+		case exists:
+			eventType = service.EventEntryUpdated
+		case created:
+			// Created and removed again before we looked, nothing to report
+			continue
+		default:
+			eventType = service.EventEntryDeleted
+		}
+
+		w.pubsub.Publish(service.Event{Type: eventType, Message: entry, Data: service.EntryChanged{Entry: entry}})
+	}
+
+	w.pending = map[string]bool{}
+}
+
+func (w *Watcher) addExisting(dir string) {
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".gpg") {
+			return nil
+		}
+		if entry, ok := w.entryName(path); ok {
+			if _, seen := w.pending[entry]; !seen {
+				w.pending[entry] = true
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) entryName(path string) (string, bool) {
+	m, ok := w.mountOf(path)
+	if !ok {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.Path, path)
+	if err != nil {
+		return "", false
+	}
+	return m.Entry(strings.TrimSuffix(filepath.ToSlash(rel), ".gpg")), true
+}
+
+func (w *Watcher) addRecursive(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// isInternalPath reports whether path is inside a hidden folder of the store,
+// such as .git or the .gopass index.
+func isInternalPath(storeDir, path string) bool {
+	rel, err := filepath.Rel(storeDir, path)
+	if err != nil {
+		return false
+	}
+	first := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+	return strings.HasPrefix(first, ".") && first != "." && first != ".."
+}
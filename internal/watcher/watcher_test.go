@@ -0,0 +1,127 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/duykhoa/gopass/internal/config"
+	"github.com/duykhoa/gopass/internal/service"
+)
+
+func startWatcher(t *testing.T, dir string) <-chan service.Event {
+	return startMountsWatcher(t, []config.Mount{{Name: config.RootStoreName, Path: dir}})
+}
+
+func startMountsWatcher(t *testing.T, mounts []config.Mount) <-chan service.Event {
+	ps := service.NewPubSub()
+	events := make(chan service.Event, 16)
+	ps.Subscribe(func(ev service.Event) { events <- ev })
+
+	w, err := NewMounts(mounts, ps)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	w.delay = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go w.Run(ctx)
+	t.Cleanup(func() {
+		cancel()
+		w.Close()
+	})
+
+	return events
+}
+
+func collect(events <-chan service.Event, wait time.Duration) []service.Event {
+	var got []service.Event
+	timeout := time.After(wait)
+	for {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-timeout:
+			return got
+		}
+	}
+}
+
+func TestWatcherDebouncesAndIgnoresGit(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".git"), 0700)
+	events := startWatcher(t, dir)
+
+	os.WriteFile(filepath.Join(dir, ".git", "index"), []byte("x"), 0600)
+	os.WriteFile(filepath.Join(dir, "github.gpg"), []byte("one"), 0600)
+	os.WriteFile(filepath.Join(dir, "github.gpg"), []byte("two"), 0600)
+	os.MkdirAll(filepath.Join(dir, "work"), 0700)
+	os.WriteFile(filepath.Join(dir, "work", "aws.gpg"), []byte("x"), 0600)
+
+	got := collect(events, 500*time.Millisecond)
+	want := map[string]service.EventType{
+		"github":   service.EventEntryCreated,
+		"work/aws": service.EventEntryCreated,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %v", len(want), got)
+	}
+	for _, ev := range got {
+		if want[ev.Message] != ev.Type {
+			t.Errorf("unexpected event %s for %s", ev.Type, ev.Message)
+		}
+	}
+}
+
+func TestWatcherReportsUpdateAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.gpg"), []byte("x"), 0600)
+	os.WriteFile(filepath.Join(dir, "b.gpg"), []byte("x"), 0600)
+	events := startWatcher(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "a.gpg"), []byte("y"), 0600)
+	os.Remove(filepath.Join(dir, "b.gpg"))
+
+	got := collect(events, 500*time.Millisecond)
+	want := map[string]service.EventType{
+		"a": service.EventEntryUpdated,
+		"b": service.EventEntryDeleted,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %v", len(want), got)
+	}
+	for _, ev := range got {
+		if want[ev.Message] != ev.Type {
+			t.Errorf("unexpected event %s for %s", ev.Type, ev.Message)
+		}
+	}
+}
+
+func TestWatcherPrefixesMountedStores(t *testing.T) {
+	root := t.TempDir()
+	team := filepath.Join(root, "team-store")
+	os.MkdirAll(team, 0700)
+	events := startMountsWatcher(t, []config.Mount{
+		{Name: config.RootStoreName, Path: root},
+		{Name: "team", Prefix: "team", Path: team},
+	})
+
+	os.WriteFile(filepath.Join(root, "github.gpg"), []byte("x"), 0600)
+	os.WriteFile(filepath.Join(team, "aws.gpg"), []byte("x"), 0600)
+
+	got := collect(events, 500*time.Millisecond)
+	want := map[string]service.EventType{
+		"github":   service.EventEntryCreated,
+		"team/aws": service.EventEntryCreated,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %v", len(want), got)
+	}
+	for _, ev := range got {
+		if want[ev.Message] != ev.Type {
+			t.Errorf("unexpected event %s for %s", ev.Type, ev.Message)
+		}
+	}
+}